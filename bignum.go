@@ -0,0 +1,68 @@
+package env
+
+import (
+	"math/big"
+	"reflect"
+)
+
+// nolint:gochecknoglobals
+var (
+	bigIntType   = reflect.TypeOf(big.Int{})
+	bigFloatType = reflect.TypeOf(big.Float{})
+	bigRatType   = reflect.TypeOf(big.Rat{})
+)
+
+// isBigNumber returns true if t is big.Int, big.Float, big.Rat or a pointer
+// to one of them, which are parsed with their UnmarshalText method.
+func isBigNumber(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t == bigIntType || t == bigFloatType || t == bigRatType
+}
+
+// bigTypeName returns the language-neutral name of the big number type t.
+func bigTypeName(t reflect.Type) string {
+	switch t {
+	case bigIntType:
+		return "int"
+	case bigFloatType:
+		return "float"
+	default:
+		return "rational"
+	}
+}
+
+// cloneBigNumber returns a copy of the big number held by v that does not
+// share its storage, unlike a copy of the struct, whose updates would
+// write through to v.
+func cloneBigNumber(v reflect.Value) reflect.Value {
+	ptr := v.Kind() == reflect.Ptr
+	if ptr && v.IsNil() {
+		return reflect.Zero(v.Type())
+	}
+
+	if !ptr {
+		x := reflect.New(v.Type())
+		x.Elem().Set(v)
+		v = x
+	}
+
+	var c interface{}
+
+	switch x := v.Interface().(type) {
+	case *big.Int:
+		c = new(big.Int).Set(x)
+	case *big.Float:
+		c = new(big.Float).Copy(x)
+	case *big.Rat:
+		c = new(big.Rat).Set(x)
+	}
+
+	if ptr {
+		return reflect.ValueOf(c)
+	}
+
+	return reflect.ValueOf(c).Elem()
+}
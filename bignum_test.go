@@ -0,0 +1,74 @@
+package env
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBigNumbers(t *testing.T) {
+	var envs struct {
+		Int    *big.Int
+		Float  *big.Float
+		Rat    *big.Rat
+		Hex    big.Int
+		Price  big.Rat `default:"19.99"`
+		Amount []*big.Int
+	}
+
+	p, err := pparse(envsMap{
+		"int":    "123456789012345678901234567890",
+		"float":  "1.25",
+		"rat":    "1/3",
+		"hex":    "0xff",
+		"amount": "1,2",
+	}, &envs)
+	require.NoError(t, err)
+
+	assert.Equal(t, "123456789012345678901234567890", envs.Int.String())
+	assert.Equal(t, "1.25", envs.Float.Text('f', -1))
+	assert.Equal(t, "1/3", envs.Rat.String())
+	assert.Equal(t, int64(255), envs.Hex.Int64())
+	assert.Equal(t, "1999/100", envs.Price.String())
+	assert.Equal(t, []*big.Int{big.NewInt(1), big.NewInt(2)}, envs.Amount)
+	assert.Equal(t, "1999/100", p.Snapshot()["price"])
+}
+
+func TestBigNumbersHelp(t *testing.T) {
+	var envs struct {
+		Int   *big.Int
+		Float big.Float
+		Rat   *big.Rat `default:"1/2"`
+	}
+
+	p, err := NewParser(Config{}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "Environments:\n"+
+		"  int int\n"+
+		"  float float\n"+
+		"  rat rational [default: 1/2]\n", p.Help())
+}
+
+func TestBigNumberDefaultNotShared(t *testing.T) {
+	// the parsed values do not write through to the initial ones
+	initial := big.NewInt(5)
+
+	envs := struct {
+		Int *big.Int
+	}{Int: initial}
+
+	p, err := NewParser(Config{Sources: []Source{MapSource{"int": "7"}}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, int64(7), envs.Int.Int64())
+	assert.Equal(t, int64(5), initial.Int64())
+
+	// nor does a failed reload write through to the parsed values
+	p.config.Sources = []Source{MapSource{"int": "x"}}
+
+	parsed := envs.Int
+	require.Error(t, p.Reload())
+	assert.Equal(t, int64(7), parsed.Int64())
+}
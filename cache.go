@@ -0,0 +1,247 @@
+package env
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// cacheLockRetry is the delay between two attempts to take the lock of
+	// a cache file.
+	cacheLockRetry = 10 * time.Millisecond
+	// cacheLockTimeout is the age after which a lock is considered left
+	// over by a crashed process.
+	cacheLockTimeout = 30 * time.Second
+)
+
+// cachedSource is a Source caching the values of another source in a file
+// shared by the processes of a host.
+type cachedSource struct {
+	src  Source
+	path string
+	ttl  time.Duration
+
+	mu     sync.RWMutex
+	names  map[string]bool // the variables covered by the cache
+	values map[string]string
+	srcMu  sync.Mutex // serializes the lookups that miss the cache
+	loaded bool       // src was loaded since the last Prefetch
+}
+
+// cacheFile is the content of the cache file. Names lists the variables
+// that were looked up, Values those that were found.
+type cacheFile struct {
+	Names  []string          `json:"names"`
+	Values map[string]string `json:"values"`
+}
+
+// CachedSource returns a Source caching the values of src in the file at
+// path for ttl, so that short-lived processes started together on a host,
+// such as a fleet of workers, do not all hit a remote store like Vault. The
+// first process finding the cache missing or stale locks it, loads src and
+// rewrites the cache, while the others wait for the lock and read the new
+// cache. The cache holds the values in clear text, with permissions
+// restricted to the owner: keep it on a private tmpfs such as /dev/shm.
+// The variables that the parser cannot name in advance, such as the
+// elements of indexed slices or the overrides of a profile read from
+// Config.ProfileEnv, are not cached but looked up in src.
+func CachedSource(src Source, path string, ttl time.Duration) Source {
+	return &cachedSource{src: src, path: path, ttl: ttl}
+}
+
+// Prefetch reads the variables from the cache file, refreshing it from the
+// underlying source if it is stale or lacks any of the variables.
+func (s *cachedSource) Prefetch(names []string) error {
+	s.srcMu.Lock()
+	s.loaded = false
+	s.srcMu.Unlock()
+
+	if c, ok := s.read(names); ok {
+		s.store(c.Names, c.Values)
+
+		return nil
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// another process may have refreshed the cache while we waited
+	if c, ok := s.read(names); ok {
+		s.store(c.Names, c.Values)
+
+		return nil
+	}
+
+	if l, ok := s.src.(Loader); ok {
+		if err := l.Load(); err != nil {
+			return err
+		}
+	}
+
+	if f, ok := s.src.(Prefetcher); ok {
+		if err := f.Prefetch(names); err != nil {
+			return err
+		}
+	}
+
+	c := cacheFile{Names: names, Values: make(map[string]string)}
+
+	for _, name := range names {
+		if value, found := s.src.Lookup(name); found {
+			c.Values[name] = value
+		}
+	}
+
+	if err := s.write(c); err != nil {
+		return err
+	}
+
+	s.store(c.Names, c.Values)
+
+	s.srcMu.Lock()
+	s.loaded = true
+	s.srcMu.Unlock()
+
+	return nil
+}
+
+// Lookup returns the value of the variable from the cache, or from the
+// underlying source if the cache does not cover the variable.
+func (s *cachedSource) Lookup(name string) (string, bool) {
+	s.mu.RLock()
+	covered := s.names[name]
+	value, found := s.values[name]
+	s.mu.RUnlock()
+
+	if covered {
+		return value, found
+	}
+
+	return s.lookupSource(name)
+}
+
+// lookupSource looks up a variable that the cache does not cover in the
+// underlying source, loading it first if needed.
+func (s *cachedSource) lookupSource(name string) (string, bool) {
+	s.srcMu.Lock()
+	defer s.srcMu.Unlock()
+
+	if l, ok := s.src.(Loader); ok && !s.loaded {
+		if l.Load() != nil {
+			return "", false
+		}
+	}
+
+	s.loaded = true
+
+	if f, ok := s.src.(Prefetcher); ok {
+		if f.Prefetch([]string{name}) != nil {
+			return "", false
+		}
+	}
+
+	return s.src.Lookup(name)
+}
+
+func (s *cachedSource) store(names []string, values map[string]string) {
+	covered := make(map[string]bool, len(names))
+	for _, name := range names {
+		covered[name] = true
+	}
+
+	s.mu.Lock()
+	s.names = covered
+	s.values = values
+	s.mu.Unlock()
+}
+
+// read returns the content of the cache file if it is fresh and covers all
+// the names.
+func (s *cachedSource) read(names []string) (cacheFile, bool) {
+	var c cacheFile
+
+	info, err := os.Stat(s.path)
+	if err != nil || time.Since(info.ModTime()) >= s.ttl {
+		return c, false
+	}
+
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil || json.Unmarshal(data, &c) != nil {
+		return c, false
+	}
+
+	for _, name := range names {
+		if !containsString(c.Names, name) {
+			return c, false
+		}
+	}
+
+	return c, true
+}
+
+// write replaces the cache file atomically, so that readers never see a
+// partial file.
+func (s *cachedSource) write(c cacheFile) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+
+	// TempFile creates the file with permissions restricted to the owner
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// lock takes the lock of the cache file, waiting for other processes to
+// release it, and returns the function releasing it. Locks older than
+// cacheLockTimeout are broken.
+func (s *cachedSource) lock() (func(), error) {
+	path := s.path + ".lock"
+
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_ = f.Close()
+
+			return func() { _ = os.Remove(path) }, nil
+		}
+
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("error locking cache: %w", err)
+		}
+
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > cacheLockTimeout {
+			_ = os.Remove(path)
+
+			continue
+		}
+
+		time.Sleep(cacheLockRetry)
+	}
+}
@@ -0,0 +1,155 @@
+package env
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lookupCounter counts the lookups of a MapSource.
+type lookupCounter struct {
+	MapSource
+
+	mu      sync.Mutex
+	lookups int
+}
+
+func (s *lookupCounter) Lookup(name string) (string, bool) {
+	s.mu.Lock()
+	s.lookups++
+	s.mu.Unlock()
+
+	return s.MapSource.Lookup(name)
+}
+
+func TestCachedSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "env")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	remote := &lookupCounter{MapSource: MapSource{"TOKEN": "abc", "WORKERS": "4"}}
+	cache := filepath.Join(dir, "cache.json")
+
+	type envs struct {
+		Token   string `env:"TOKEN"`
+		Workers int    `env:"WORKERS"`
+		Name    string `env:"NAME"`
+	}
+
+	var wg sync.WaitGroup
+
+	results := make([]envs, 10)
+
+	// processes sharing the cache, each with its own source
+	for i := range results {
+		wg.Add(1)
+
+		go func(dest *envs) {
+			defer wg.Done()
+
+			p, err := NewParser(Config{Sources: []Source{CachedSource(remote, cache, time.Minute)}}, dest)
+			assert.NoError(t, err)
+			assert.NoError(t, p.Parse())
+		}(&results[i])
+	}
+
+	wg.Wait()
+
+	for _, r := range results {
+		assert.Equal(t, envs{Token: "abc", Workers: 4}, r)
+	}
+
+	// a single process looked up the three variables
+	assert.Equal(t, 3, remote.lookups)
+
+	info, err := os.Stat(cache)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	_, err = os.Stat(cache + ".lock")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCachedSourceExpires(t *testing.T) {
+	dir, err := ioutil.TempDir("", "env")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	remote := &lookupCounter{MapSource: MapSource{"WORKERS": "4"}}
+	cache := filepath.Join(dir, "cache.json")
+
+	var envs struct {
+		Workers int `env:"WORKERS"`
+	}
+
+	p, err := NewParser(Config{Sources: []Source{CachedSource(remote, cache, time.Minute)}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+
+	remote.MapSource["WORKERS"] = "8"
+
+	require.NoError(t, p.Parse())
+	assert.Equal(t, 4, envs.Workers)
+
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(cache, old, old))
+
+	require.NoError(t, p.Parse())
+	assert.Equal(t, 8, envs.Workers)
+	assert.Equal(t, 2, remote.lookups)
+}
+
+// parseCached parses dest from src through a fresh cache.
+func parseCached(t *testing.T, config Config, src Source, dest interface{}) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "env")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	config.Sources = []Source{CachedSource(src, filepath.Join(dir, "cache.json"), time.Minute)}
+
+	p, err := NewParser(config, dest)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+}
+
+func TestCachedSourceFallback(t *testing.T) {
+	var envs struct {
+		Token string `env:"TOKEN" defaultEnv:"LEGACY_TOKEN"`
+	}
+
+	parseCached(t, Config{}, MapSource{"LEGACY_TOKEN": "abc"}, &envs)
+	assert.Equal(t, "abc", envs.Token)
+}
+
+func TestCachedSourceIndexed(t *testing.T) {
+	var envs struct {
+		Backends []backend
+	}
+
+	parseCached(t, Config{}, MapSource{"backends_0_host": "a", "backends_1_host": "b"}, &envs)
+	assert.Equal(t, []backend{{Host: "a", Port: 80}, {Host: "b", Port: 80}}, envs.Backends)
+}
+
+func TestCachedSourceProfileEnv(t *testing.T) {
+	var envs struct {
+		Host string `env:"HOST"`
+	}
+
+	parseCached(t, Config{ProfileEnv: "APP_ENV"}, MapSource{
+		"APP_ENV":       "staging",
+		"HOST":          "prod",
+		"HOST__STAGING": "staging",
+	}, &envs)
+	assert.Equal(t, "staging", envs.Host)
+}
@@ -0,0 +1,99 @@
+package env
+
+import (
+	"reflect"
+	"strings"
+)
+
+// isCatchAllMap returns true if t is a map[string]string or a
+// map[string]interface{}.
+func isCatchAllMap(t reflect.Type) bool {
+	if t.Kind() != reflect.Map || t.Key().Kind() != reflect.String {
+		return false
+	}
+
+	elem := t.Elem()
+
+	return elem.Kind() == reflect.String || (elem.Kind() == reflect.Interface && elem.NumMethod() == 0)
+}
+
+// captureCatchAll fills the catch-all maps with the variables starting with
+// their name and an underscore that are not bound to any other field, from
+// the sources implementing Lister. The keys are the names without the
+// prefix.
+func (p *Parser) captureCatchAll(roots []reflect.Value, wasPresent map[*spec]bool) Errors {
+	if len(p.catchAll) == 0 {
+		return nil
+	}
+
+	var errs Errors
+
+	sources := p.sources()
+
+	bound := make(map[string]bool)
+	for _, name := range p.names() {
+		bound[name] = true
+	}
+
+	for _, spec := range p.catchAll {
+		prefix := spec.name + "_"
+		values := reflect.MakeMap(spec.typ)
+
+		// the sources are read in reverse so that the first source having
+		// a variable takes precedence
+		for i := len(sources) - 1; i >= 0; i-- {
+			l, ok := sources[i].(Lister)
+			if !ok {
+				continue
+			}
+
+			for _, name := range l.Names() {
+				if !strings.HasPrefix(name, prefix) || bound[name] || p.isIndexedName(name) {
+					continue
+				}
+
+				value, found := sources[i].Lookup(name)
+				if !found {
+					continue
+				}
+
+				if err := p.config.checkLength(p.messages, name, value); err != nil {
+					errs = append(errs, p.fieldError(spec, name, err))
+
+					continue
+				}
+
+				key := strings.TrimPrefix(name, prefix)
+				values.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(value).Convert(spec.typ.Elem()))
+			}
+		}
+
+		if values.Len() == 0 {
+			continue
+		}
+
+		if err := p.config.checkElements(p.messages, spec.name, values.Len()); err != nil {
+			errs = append(errs, p.fieldError(spec, spec.name, err))
+
+			continue
+		}
+
+		wasPresent[spec] = true
+
+		resolveAlloc(roots, spec.dest).Set(values)
+	}
+
+	return errs
+}
+
+// isIndexedName returns true if name is the name of a variable of an
+// element of an indexed slice.
+func (p *Parser) isIndexedName(name string) bool {
+	for _, x := range p.indexed {
+		if strings.HasPrefix(name, x.spec.name+"_") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,79 @@
+package env
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatchAll(t *testing.T) {
+	var envs struct {
+		Name     string                 `env:"PLUGIN_NAME"`
+		Backends []backend              `env:"PLUGIN_BACKENDS"`
+		Plugin   map[string]string      `env:"PLUGIN,catchall"`
+		Other    map[string]interface{} `env:"OTHER,catchall"`
+	}
+
+	err := parse(envsMap{
+		"PLUGIN_NAME":            "x",
+		"PLUGIN_BACKENDS_0_host": "a",
+		"PLUGIN_COLOR":           "blue",
+		"PLUGIN_SIZE":            "2",
+		"OTHER_DEBUG":            "true",
+		"UNRELATED":              "y",
+	}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "x", envs.Name)
+	assert.Equal(t, map[string]string{"COLOR": "blue", "SIZE": "2"}, envs.Plugin)
+	assert.Equal(t, map[string]interface{}{"DEBUG": "true"}, envs.Other)
+}
+
+func TestCatchAllSources(t *testing.T) {
+	var envs struct {
+		Plugin map[string]string `env:"PLUGIN,catchall"`
+	}
+
+	p, err := NewParser(Config{Sources: []Source{
+		MapSource{"PLUGIN_A": "first"},
+		SourceFunc(func(string) (string, bool) { return "unlisted", true }),
+		PrefixSource("PLUGIN_", MapSource{"A": "second", "B": "second"}),
+	}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, map[string]string{"A": "first", "B": "second"}, envs.Plugin)
+}
+
+func TestCatchAllUnset(t *testing.T) {
+	envs := struct {
+		Plugin map[string]string `env:"PLUGIN,catchall"`
+	}{
+		Plugin: map[string]string{"A": "default"},
+	}
+
+	err := parse(envsMap{}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"A": "default"}, envs.Plugin)
+}
+
+func TestCatchAllLimits(t *testing.T) {
+	var envs struct {
+		Plugin map[string]string `env:"PLUGIN,catchall"`
+	}
+
+	src := MapSource{"PLUGIN_A": "a", "PLUGIN_B": "b", "PLUGIN_C": "c"}
+
+	p, err := NewParser(Config{Sources: []Source{src}, MaxElements: 2}, &envs)
+	require.NoError(t, err)
+	assert.True(t, errors.Is(p.Parse(), ErrorTooManyElements))
+}
+
+func TestCatchAllNotMap(t *testing.T) {
+	var envs struct {
+		Plugin map[string]int `env:"PLUGIN,catchall"`
+	}
+
+	_, err := NewParser(Config{}, &envs)
+	assert.True(t, errors.Is(err, ErrorCatchAllNotMap))
+}
@@ -0,0 +1,70 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// charsets maps the names accepted by the encoding tag to the functions
+// transcoding values to UTF-8.
+var charsets = map[string]func(string) (string, error){
+	"utf-8":      decodeUTF8,
+	"utf8":       decodeUTF8,
+	"latin-1":    decodeLatin1,
+	"latin1":     decodeLatin1,
+	"iso-8859-1": decodeLatin1,
+	"utf-16le":   decodeUTF16LE,
+	"utf-16be":   decodeUTF16BE,
+}
+
+// charset returns the function transcoding values in the named encoding.
+func charset(name string) (func(string) (string, error), error) {
+	decode, ok := charsets[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, fmt.Errorf("%q: %w", name, ErrorUnknownEncoding)
+	}
+
+	return decode, nil
+}
+
+func decodeUTF8(s string) (string, error) {
+	if !utf8.ValidString(s) {
+		return "", fmt.Errorf("invalid UTF-8 value") // nolint:goerr113
+	}
+
+	return s, nil
+}
+
+func decodeLatin1(s string) (string, error) {
+	runes := make([]rune, len(s))
+	for i := 0; i < len(s); i++ {
+		runes[i] = rune(s[i])
+	}
+
+	return string(runes), nil
+}
+
+func decodeUTF16LE(s string) (string, error) {
+	return decodeUTF16(s, func(lo, hi byte) uint16 { return uint16(lo) | uint16(hi)<<8 })
+}
+
+func decodeUTF16BE(s string) (string, error) {
+	return decodeUTF16(s, func(hi, lo byte) uint16 { return uint16(lo) | uint16(hi)<<8 })
+}
+
+// decodeUTF16 decodes s as UTF-16 code units built from pairs of bytes by
+// unit.
+func decodeUTF16(s string, unit func(a, b byte) uint16) (string, error) {
+	if len(s)%2 != 0 {
+		return "", fmt.Errorf("invalid UTF-16 value: odd number of bytes") // nolint:goerr113
+	}
+
+	units := make([]uint16, len(s)/2)
+	for i := range units {
+		units[i] = unit(s[2*i], s[2*i+1])
+	}
+
+	return string(utf16.Decode(units)), nil
+}
@@ -0,0 +1,53 @@
+package env
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoding(t *testing.T) {
+	var envs struct {
+		Latin1  string   `encoding:"latin-1"`
+		UTF16LE string   `encoding:"UTF-16LE"`
+		UTF16BE []string `encoding:"utf-16be"`
+		Plain   string   `encoding:"utf-8"`
+	}
+
+	// values with NUL bytes cannot be set in the process environment
+	p, err := NewParser(Config{Sources: []Source{MapSource{
+		"latin1":  "caf\xe9",
+		"utf16le": "h\x00\xe9\x00",
+		"utf16be": "\x00a\x00,\x00b",
+		"plain":   "café",
+	}}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, "café", envs.Latin1)
+	assert.Equal(t, "hé", envs.UTF16LE)
+	assert.Equal(t, []string{"a", "b"}, envs.UTF16BE)
+	assert.Equal(t, "café", envs.Plain)
+}
+
+func TestEncodingInvalidValue(t *testing.T) {
+	var envs struct {
+		UTF16 string `encoding:"utf-16le"`
+		Plain string `encoding:"utf-8"`
+	}
+
+	err := parse(envsMap{"utf16": "abc", "plain": "caf\xe9"}, &envs)
+	assert.EqualError(t, err, "error decoding environment variable utf16: invalid UTF-16 value: odd number of bytes\n"+
+		"error decoding environment variable plain: invalid UTF-8 value")
+}
+
+func TestUnknownEncoding(t *testing.T) {
+	var envs struct {
+		Foo string `encoding:"ebcdic"`
+	}
+
+	err := parse(envsMap{}, &envs)
+	assert.True(t, errors.Is(err, ErrorUnknownEncoding))
+	assert.EqualError(t, err, `.Foo: "ebcdic": unknown encoding`)
+}
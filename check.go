@@ -0,0 +1,40 @@
+package env
+
+import (
+	"reflect"
+	"strings"
+)
+
+// EnvironSource returns a Source reading variables from a list of
+// "key=value" strings, in the form returned by os.Environ.
+func EnvironSource(environ []string) Source {
+	m := make(MapSource, len(environ))
+
+	for _, kv := range environ {
+		if i := strings.IndexByte(kv, '='); i >= 0 {
+			m[kv[:i]] = kv[i+1:]
+		}
+	}
+
+	return m
+}
+
+// Check validates environ, a list of "key=value" strings such as
+// os.Environ(), against the variables of the destination structs, and
+// returns the same errors as Parse would. The destination structs are only
+// used for their types and defaults, and are left untouched, so Check is
+// suited to preflight commands and init containers that validate the
+// environment and exit.
+func Check(environ []string, dest ...interface{}) error {
+	p, err := NewParser(Config{Sources: []Source{EnvironSource(environ)}}, dest...)
+	if err != nil {
+		return err
+	}
+
+	roots := make([]reflect.Value, len(p.roots))
+	for i, root := range p.roots {
+		roots[i] = reflect.New(root.Type().Elem())
+	}
+
+	return p.process(roots)
+}
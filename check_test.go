@@ -0,0 +1,60 @@
+package env
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	envs := struct {
+		Workers int    `env:"workers,required"`
+		Name    string `default:"foo"`
+		Hosts   []string
+	}{Hosts: []string{"a"}}
+
+	err := Check([]string{"workers=4", "name=bar=baz", "hosts=b,c"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, 0, envs.Workers)
+	assert.Equal(t, "", envs.Name)
+	assert.Equal(t, []string{"a"}, envs.Hosts)
+}
+
+func TestCheckErrors(t *testing.T) {
+	var envs struct {
+		Workers int `env:"workers,required"`
+		Ratio   float64
+	}
+
+	err := Check([]string{"ratio=abc", "workers"}, &envs)
+	require.Error(t, err)
+
+	var errs Errors
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs, 2)
+	assert.Equal(t, "ratio", errs[0].Name)
+	assert.Equal(t, "workers", errs[1].Name)
+	assert.True(t, errors.Is(err, ErrorFieldIsRequired))
+}
+
+func TestCheckInvalidDest(t *testing.T) {
+	var envs struct{}
+
+	err := Check(nil, envs)
+	assert.True(t, errors.Is(err, ErrorNotPointers))
+}
+
+func TestEnvironSource(t *testing.T) {
+	src := EnvironSource([]string{"a=1", "b==2", "c=", "d"})
+
+	for name, expected := range map[string]string{"a": "1", "b": "=2", "c": ""} {
+		value, found := src.Lookup(name)
+		assert.True(t, found, name)
+		assert.Equal(t, expected, value, name)
+	}
+
+	_, found := src.Lookup("d")
+	assert.False(t, found)
+}
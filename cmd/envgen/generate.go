@@ -0,0 +1,685 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// errUnsupported is returned for the fields and tags that need env.Parse.
+	errUnsupported = errors.New("not supported by envgen")
+	// errMalformedTag is returned for invalid env tags.
+	errMalformedTag = errors.New("malformed tag")
+)
+
+// envPath is the import path of the env package.
+const envPath = "github.com/Alex616/go-env"
+
+// basic is a type that the generated code parses from a single value.
+type basic struct {
+	goType string   // the type as written in Go
+	helper string   // suffix of the name of the helper parsing it
+	body   string   // body of the helper, parsing s
+	pkgs   []string // packages imported by the body
+}
+
+// basics are the supported types by name.
+// nolint:gochecknoglobals
+var basics = map[string]*basic{
+	"string":        {goType: "string", helper: "String", body: "return s, nil"},
+	"bool":          {goType: "bool", helper: "Bool", body: "return strconv.ParseBool(s)", pkgs: []string{"strconv"}},
+	"int":           intBasic("int", "Int", "0"),
+	"int8":          intBasic("int8", "Int8", "8"),
+	"int16":         intBasic("int16", "Int16", "16"),
+	"int32":         intBasic("int32", "Int32", "32"),
+	"int64":         {goType: "int64", helper: "Int64", body: "return strconv.ParseInt(s, 10, 64)", pkgs: []string{"strconv"}},
+	"uint":          uintBasic("uint", "Uint", "0"),
+	"uint8":         uintBasic("uint8", "Uint8", "8"),
+	"uint16":        uintBasic("uint16", "Uint16", "16"),
+	"uint32":        uintBasic("uint32", "Uint32", "32"),
+	"uint64":        {goType: "uint64", helper: "Uint64", body: "return strconv.ParseUint(s, 10, 64)", pkgs: []string{"strconv"}},
+	"float32":       floatBasic("float32", "Float32", "32"),
+	"float64":       {goType: "float64", helper: "Float64", body: "return strconv.ParseFloat(s, 64)", pkgs: []string{"strconv"}},
+	"time.Duration": {goType: "time.Duration", helper: "Duration", body: "return time.ParseDuration(s)", pkgs: []string{"time"}},
+}
+
+// mapBasic is map[string]string, read from a list of key=value pairs.
+// nolint:gochecknoglobals
+var mapBasic = &basic{
+	goType: "map[string]string",
+	helper: "Map",
+	body: `m := make(map[string]string)
+if s == "" {
+return m, nil
+}
+
+entries, err := csv.NewReader(strings.NewReader(s)).Read()
+if err != nil {
+return nil, err
+}
+
+for _, entry := range entries {
+i := strings.Index(entry, "=")
+if i < 0 {
+return nil, fmt.Errorf("%q: %w", entry, env.ErrorMalformedPair)
+}
+
+m[entry[:i]] = entry[i+1:]
+}
+
+return m, nil`,
+	pkgs: []string{"encoding/csv", "strings"},
+}
+
+func intBasic(goType, helper, bits string) *basic {
+	body := fmt.Sprintf("x, err := strconv.ParseInt(s, 10, %s)\nreturn %s(x), err", bits, goType)
+
+	return &basic{goType: goType, helper: helper, body: body, pkgs: []string{"strconv"}}
+}
+
+func uintBasic(goType, helper, bits string) *basic {
+	body := fmt.Sprintf("x, err := strconv.ParseUint(s, 10, %s)\nreturn %s(x), err", bits, goType)
+
+	return &basic{goType: goType, helper: helper, body: body, pkgs: []string{"strconv"}}
+}
+
+func floatBasic(goType, helper, bits string) *basic {
+	body := fmt.Sprintf("x, err := strconv.ParseFloat(s, %s)\nreturn %s(x), err", bits, goType)
+
+	return &basic{goType: goType, helper: helper, body: body, pkgs: []string{"strconv"}}
+}
+
+// field is a variable of the struct.
+type field struct {
+	selector   string // selector of the field from dest, such as "Base.Host"
+	qualified  string // path of the field from the struct, as in env.FieldError
+	name       string
+	aliases    []string
+	required   bool
+	notEmpty   bool
+	defaultVal string
+	defaults   []string // elements of the default value of a slice
+	secret     bool
+	typ        *basic
+	pointer    bool
+	slice      bool
+}
+
+// structDecl is a struct type of the package with the imports of its file.
+type structDecl struct {
+	typ     *ast.StructType
+	imports map[string]string // import paths by name
+}
+
+// generator collects the variables of a struct type.
+type generator struct {
+	structs map[string]*structDecl
+	fields  []*field
+}
+
+// generate returns the source of the parser of the struct named typeName
+// declared in the package in dir, ignoring the file named output.
+func generate(dir, typeName, output string) ([]byte, error) {
+	fset := token.NewFileSet()
+
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go") && info.Name() != output
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pkg := range pkgs {
+		g := &generator{structs: make(map[string]*structDecl)}
+		g.collect(pkg)
+
+		decl, ok := g.structs[typeName]
+		if !ok {
+			continue
+		}
+
+		if err := g.walk(decl, typeName, nil); err != nil {
+			return nil, err
+		}
+
+		return g.source(pkg.Name, typeName)
+	}
+
+	return nil, fmt.Errorf("struct type %s not found in %s", typeName, dir)
+}
+
+// collect records the struct types declared in the package.
+func (g *generator) collect(pkg *ast.Package) {
+	for _, file := range pkg.Files {
+		imports := make(map[string]string)
+
+		for _, imp := range file.Imports {
+			path, _ := strconv.Unquote(imp.Path.Value)
+
+			name := path[strings.LastIndex(path, "/")+1:]
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+
+			imports[name] = path
+		}
+
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+
+			for _, s := range gen.Specs {
+				ts := s.(*ast.TypeSpec)
+				if st, ok := ts.Type.(*ast.StructType); ok {
+					g.structs[ts.Name.Name] = &structDecl{typ: st, imports: imports}
+				}
+			}
+		}
+	}
+}
+
+// walk collects the variables of the fields of decl, recursing into the
+// embedded structs.
+func (g *generator) walk(decl *structDecl, qualified string, selector []string) error {
+	for _, f := range decl.typ.Fields.List {
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			s, _ := strconv.Unquote(f.Tag.Value)
+			tag = reflect.StructTag(s)
+		}
+
+		if tag.Get("env") == "-" {
+			continue
+		}
+
+		if len(f.Names) == 0 {
+			if err := g.embedded(decl, f, tag, qualified, selector); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		for _, name := range f.Names {
+			path := qualified + "." + name.Name
+
+			if !name.IsExported() {
+				if _, exists := tag.Lookup("env"); exists {
+					return fmt.Errorf("%s: unexported field has an env tag", path)
+				}
+
+				continue
+			}
+
+			v, err := newField(decl, f.Type, tag, name.Name)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+
+			v.selector = strings.Join(append(selector, name.Name), ".")
+			v.qualified = path
+			g.fields = append(g.fields, v)
+		}
+	}
+
+	return nil
+}
+
+// embedded collects the variables of the embedded struct f.
+func (g *generator) embedded(decl *structDecl, f *ast.Field, tag reflect.StructTag, qualified string,
+	selector []string) error {
+	ident, ok := f.Type.(*ast.Ident)
+	if !ok || g.structs[ident.Name] == nil {
+		return fmt.Errorf("%s: embedded %s: %w", qualified, exprString(f.Type), errUnsupported)
+	}
+
+	path := qualified + "." + ident.Name
+
+	if _, exists := tag.Lookup("onMissing"); exists {
+		return fmt.Errorf("%s: onMissing tag: %w", path, errUnsupported)
+	}
+
+	if strings.Contains(tag.Get("env"), "prefix:") {
+		return fmt.Errorf("%s: prefix: %w", path, errUnsupported)
+	}
+
+	return g.walk(g.structs[ident.Name], path, append(selector[:len(selector):len(selector)], ident.Name))
+}
+
+// newField returns the variable of the field named name.
+func newField(decl *structDecl, expr ast.Expr, tag reflect.StructTag, name string) (*field, error) {
+	v := &field{name: strings.ToLower(name)}
+
+	for _, key := range []string{"levels", "defaultEnv", "sources", "encoding", "onMissing", "path", "csv", "kvSep", "itemSep", "layout"} {
+		if _, exists := tag.Lookup(key); exists {
+			return nil, fmt.Errorf("%s tag: %w", key, errUnsupported)
+		}
+	}
+
+	if def, exists := tag.Lookup("default"); exists {
+		if strings.Contains(def, "{{") {
+			return nil, fmt.Errorf("default template: %w", errUnsupported)
+		}
+
+		v.defaultVal = def
+	}
+
+	if err := v.lookAtTag(tag.Get("env"), name); err != nil {
+		return nil, err
+	}
+
+	if v.required && v.defaultVal != "" {
+		return nil, fmt.Errorf("required field with a default: %w", errMalformedTag)
+	}
+
+	typ := expr
+
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		typ, v.pointer = t.X, true
+	case *ast.ArrayType:
+		if t.Len == nil {
+			typ, v.slice = t.Elt, true
+		}
+	}
+
+	v.typ = decl.basic(typ)
+	if v.typ == nil || v.typ == mapBasic && (v.slice || v.pointer) {
+		return nil, fmt.Errorf("type %s: %w", exprString(expr), errUnsupported)
+	}
+
+	if v.notEmpty && v.pointer {
+		return nil, fmt.Errorf("notEmpty on a pointer: %w", errMalformedTag)
+	}
+
+	if v.slice && v.defaultVal != "" {
+		defaults, err := csv.NewReader(strings.NewReader(v.defaultVal)).Read()
+		if err != nil {
+			return nil, fmt.Errorf("default value %q: %w", v.defaultVal, errMalformedTag)
+		}
+
+		v.defaults = defaults
+	}
+
+	return v, nil
+}
+
+// basic returns the supported type of expr, or nil.
+func (d *structDecl) basic(expr ast.Expr) *basic {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return basics[t.Name]
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok && d.imports[pkg.Name] == "time" && t.Sel.Name == "Duration" {
+			return basics["time.Duration"]
+		}
+	case *ast.MapType:
+		key, keyOK := t.Key.(*ast.Ident)
+		value, valueOK := t.Value.(*ast.Ident)
+
+		if keyOK && valueOK && key.Name == "string" && value.Name == "string" {
+			return mapBasic
+		}
+	}
+
+	return nil
+}
+
+// lookAtTag fills the variable from the env tag of the field named name.
+func (v *field) lookAtTag(tag, name string) error {
+	named := false
+
+	for _, item := range strings.Split(tag, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		key, value, hasValue := item, "", false
+		if pos := strings.Index(item, ":"); pos != -1 {
+			key, value, hasValue = item[:pos], item[pos+1:], true
+		}
+
+		switch key {
+		case "required", "secret", "mask", "verbatim", "notEmpty":
+			if hasValue {
+				return fmt.Errorf("%q: option takes no value: %w", item, errMalformedTag)
+			}
+		case "invert", "count", "catchall", "unset", "expand", "file", "minitems", "maxitems":
+			return fmt.Errorf("%q: %w", item, errUnsupported)
+		case "name", "alias":
+			if hasValue && value == "" {
+				return fmt.Errorf("%q: %s requires a value: %w", item, key, errMalformedTag)
+			}
+		default:
+			if hasValue {
+				return fmt.Errorf("%q: unrecognized key: %w", item, errMalformedTag)
+			}
+		}
+
+		switch {
+		case key == "required":
+			v.required = true
+		case key == "secret":
+			v.secret = true
+		case key == "notEmpty":
+			v.notEmpty = true
+		case key == "mask":
+		case key == "alias" && hasValue:
+			v.aliases = append(v.aliases, value)
+		default:
+			if named {
+				return fmt.Errorf("%q: name already set to %q: %w", item, v.name, errMalformedTag)
+			}
+
+			switch {
+			case hasValue:
+				v.name = value
+			case key == "verbatim":
+				v.name = name
+			default:
+				v.name = key
+			}
+
+			named = true
+		}
+	}
+
+	return nil
+}
+
+// source returns the formatted source of the parser.
+func (g *generator) source(pkg, typeName string) ([]byte, error) {
+	prefix := strings.ToLower(typeName[:1]) + typeName[1:]
+	imports := map[string]bool{"fmt": true}
+	helpers := make(map[string]*basic)
+	slices := make(map[string]*basic)
+	secret := false
+
+	for _, v := range g.fields {
+		if v.slice {
+			imports["encoding/csv"], imports["strings"] = true, true
+		}
+
+		// strings are assigned as they are
+		if v.typ == basics["string"] {
+			continue
+		}
+
+		helpers[v.typ.helper] = v.typ
+
+		for _, pkg := range v.typ.pkgs {
+			imports[pkg] = true
+		}
+
+		if v.slice {
+			slices[v.typ.helper] = v.typ
+		}
+
+		if v.secret {
+			secret = true
+			imports["strings"] = true
+		}
+	}
+
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "// Code generated by envgen -type %s; DO NOT EDIT.\n\npackage %s\n\nimport (\n", typeName, pkg)
+
+	for _, path := range sortedKeys(imports) {
+		if path == envPath {
+			continue
+		}
+
+		fmt.Fprintf(&b, "%q\n", path)
+	}
+
+	fmt.Fprintf(&b, "\nenv %q\n)\n\n", envPath)
+	fmt.Fprintf(&b, "// Parse%[1]s sets the fields of dest from the variables returned by\n", typeName)
+	fmt.Fprintf(&b, "// lookup, such as os.LookupEnv, like env.Parse would.\n")
+	fmt.Fprintf(&b, "func Parse%s(dest *%s, lookup func(string) (string, bool)) error {\n", typeName, typeName)
+	fmt.Fprintf(&b, "var errs env.Errors\n\n")
+
+	found := 0
+
+	for _, v := range g.fields {
+		if v.required || v.defaultVal != "" {
+			found++
+		}
+	}
+
+	if found > 0 {
+		fmt.Fprintf(&b, "var found [%d]bool\n\n", found)
+	}
+
+	found = 0
+
+	for _, v := range g.fields {
+		g.capture(&b, prefix, v, found)
+
+		if v.required || v.defaultVal != "" {
+			found++
+		}
+	}
+
+	found = 0
+
+	for _, v := range g.fields {
+		if !v.required && v.defaultVal == "" {
+			continue
+		}
+
+		g.fallback(&b, prefix, v, found)
+		found++
+	}
+
+	fmt.Fprintf(&b, "if len(errs) > 0 {\nreturn errs\n}\n\nreturn nil\n}\n\n")
+
+	g.helpers(&b, prefix, helpers, slices, secret)
+
+	return format.Source(b.Bytes())
+}
+
+// capture writes the code setting the field from its variable.
+func (g *generator) capture(b *bytes.Buffer, prefix string, v *field, index int) {
+	names := []string{strconv.Quote(v.name)}
+	for _, alias := range v.aliases {
+		names = append(names, strconv.Quote(alias))
+	}
+
+	text := v.typ == basics["string"]
+
+	// only the errors need the name that was set
+	name := "name"
+	if text && !v.slice && !v.notEmpty {
+		name = "_"
+	}
+
+	fmt.Fprintf(b, "if %s, value, ok := %sLookup(lookup, %s); ok {\n", name, prefix, strings.Join(names, ", "))
+
+	if v.required || v.defaultVal != "" {
+		fmt.Fprintf(b, "found[%d] = true\n\n", index)
+	}
+
+	// the closing brace of the check of empty values, if any, comes first
+	end := "}\n\n"
+
+	if v.notEmpty {
+		fmt.Fprintf(b, "if value == \"\" {\n")
+		fmt.Fprintf(b, "errs = append(errs, %sError(%q, name,\n\"environment variable %%s: %%w\", env.ErrorEmptyValue))\n",
+			prefix, v.qualified)
+		fmt.Fprintf(b, "} else {\n")
+
+		end = "}\n" + end
+	}
+
+	switch {
+	case v.slice:
+		fmt.Fprintf(b, "if values, err := csv.NewReader(strings.NewReader(value)).Read(); err != nil {\n")
+		fmt.Fprintf(b, "errs = append(errs, %sError(%q, name,\n"+
+			"\"error reading a CSV string from environment variable %%s with multiple values: %%w\", %s))\n",
+			prefix, v.qualified, redact(prefix, v, "err", "value"))
+
+		if text {
+			fmt.Fprintf(b, "} else {\ndest.%s = values\n}\n%s", v.selector, end)
+
+			return
+		}
+
+		fmt.Fprintf(b, "} else if v, err := %s%sSlice(values); err != nil {\n", prefix, v.typ.helper)
+		fmt.Fprintf(b, "errs = append(errs, %sError(%q, name,\n"+
+			"\"error processing environment variable %%s with multiple values: %%w\", %s))\n",
+			prefix, v.qualified, redact(prefix, v, "err", "value"))
+	case text:
+		assignString(b, v, "value")
+		fmt.Fprint(b, end)
+
+		return
+	default:
+		fmt.Fprintf(b, "if v, err := %s%s(value); err != nil {\n", prefix, v.typ.helper)
+		fmt.Fprintf(b, "errs = append(errs, %sError(%q, name,\n\"error processing environment variable %%s: %%w\", %s))\n",
+			prefix, v.qualified, redact(prefix, v, "err", "value"))
+	}
+
+	fmt.Fprintf(b, "} else {\ndest.%s = %s\n}\n%s", v.selector, address(v, "v"), end)
+}
+
+// assignString writes the code setting the string field to value.
+func assignString(b *bytes.Buffer, v *field, value string) {
+	if v.pointer {
+		fmt.Fprintf(b, "v := %s\ndest.%s = &v\n", value, v.selector)
+	} else {
+		fmt.Fprintf(b, "dest.%s = %s\n", v.selector, value)
+	}
+}
+
+// address returns the expression assigned to the field for the value in
+// the variable named value.
+func address(v *field, value string) string {
+	if v.pointer {
+		return "&" + value
+	}
+
+	return value
+}
+
+// fallback writes the code reporting the field as missing or setting it
+// to its default when its variable is not set.
+func (g *generator) fallback(b *bytes.Buffer, prefix string, v *field, index int) {
+	fmt.Fprintf(b, "if !found[%d] {\n", index)
+
+	if v.required {
+		fmt.Fprintf(b, "errs = append(errs, &env.FieldError{\nField: %q,\nName: %q,\n"+
+			"Err: fmt.Errorf(\"%%s: %%w\", %q, env.ErrorFieldIsRequired),\n})\n}\n\n", v.qualified, v.name, v.name)
+
+		return
+	}
+
+	if v.slice {
+		values := make([]string, len(v.defaults))
+		for i, value := range v.defaults {
+			values[i] = strconv.Quote(value)
+		}
+
+		literal := fmt.Sprintf("[]string{%s}", strings.Join(values, ", "))
+
+		if v.typ == basics["string"] {
+			fmt.Fprintf(b, "dest.%s = %s\n}\n\n", v.selector, literal)
+
+			return
+		}
+
+		fmt.Fprintf(b, "if v, err := %s%sSlice(%s); err != nil {\n", prefix, v.typ.helper, literal)
+	} else if v.typ == basics["string"] {
+		assignString(b, v, strconv.Quote(v.defaultVal))
+		fmt.Fprintf(b, "}\n\n")
+
+		return
+	} else {
+		fmt.Fprintf(b, "if v, err := %s%s(%q); err != nil {\n", prefix, v.typ.helper, v.defaultVal)
+	}
+
+	err := redact(prefix, v, "err", strconv.Quote(v.defaultVal))
+
+	fmt.Fprintf(b, "errs = append(errs, %sError(%q, %q,\n\"error processing default value for %%s: %%w\", %s))\n",
+		prefix, v.qualified, v.name, err)
+	fmt.Fprintf(b, "} else {\ndest.%s = %s\n}\n}\n\n", v.selector, address(v, "v"))
+}
+
+// redact returns the expression of the error err of the field, hiding value
+// if the field is secret.
+func redact(prefix string, v *field, err, value string) string {
+	if !v.secret {
+		return err
+	}
+
+	return fmt.Sprintf("%sRedact(%s, %s)", prefix, err, value)
+}
+
+// helpers writes the functions used by the parser.
+func (g *generator) helpers(b *bytes.Buffer, prefix string, helpers, slices map[string]*basic, secret bool) {
+	fmt.Fprintf(b, "func %sLookup(lookup func(string) (string, bool), names ...string) (string, string, bool) {\n", prefix)
+	fmt.Fprintf(b, "for _, name := range names {\nif value, ok := lookup(name); ok {\nreturn name, value, true\n}\n}\n\n")
+	fmt.Fprintf(b, "return \"\", \"\", false\n}\n\n")
+
+	fmt.Fprintf(b, "func %sError(field, name, format string, err error) *env.FieldError {\n", prefix)
+	fmt.Fprintf(b, "return &env.FieldError{Field: field, Name: name, Err: fmt.Errorf(format, name, err)}\n}\n\n")
+
+	for _, name := range sortedKeys(helpers) {
+		h := helpers[name]
+		fmt.Fprintf(b, "func %s%s(s string) (%s, error) {\n%s\n}\n\n", prefix, h.helper, h.goType, h.body)
+	}
+
+	for _, name := range sortedKeys(slices) {
+		h := slices[name]
+		fmt.Fprintf(b, "func %s%sSlice(values []string) ([]%s, error) {\n", prefix, h.helper, h.goType)
+		fmt.Fprintf(b, "s := make([]%s, len(values))\n\nfor i, value := range values {\n", h.goType)
+		fmt.Fprintf(b, "v, err := %s%s(value)\nif err != nil {\nreturn nil, err\n}\n\ns[i] = v\n}\n\nreturn s, nil\n}\n\n",
+			prefix, h.helper)
+	}
+
+	if secret {
+		fmt.Fprintf(b, "// %sRedactedError hides a secret value from the message of err.\n", prefix)
+		fmt.Fprintf(b, "type %sRedactedError struct {\nerr error\nvalue string\n}\n\n", prefix)
+		fmt.Fprintf(b, "func (e *%sRedactedError) Error() string {\n", prefix)
+		fmt.Fprintf(b, "return strings.ReplaceAll(e.err.Error(), e.value, \"***\")\n}\n\n")
+		fmt.Fprintf(b, "func (e *%sRedactedError) Unwrap() error {\nreturn e.err\n}\n\n", prefix)
+		fmt.Fprintf(b, "func %sRedact(err error, value string) error {\nif value == \"\" {\nreturn err\n}\n\n", prefix)
+		fmt.Fprintf(b, "return &%sRedactedError{err: err, value: value}\n}\n", prefix)
+	}
+}
+
+// sortedKeys returns the keys of m, which is a map with string keys, in
+// order.
+func sortedKeys(m interface{}) []string {
+	keys := make([]string, 0)
+
+	for _, k := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, k.String())
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func exprString(expr ast.Expr) string {
+	var b bytes.Buffer
+
+	_ = format.Node(&b, token.NewFileSet(), expr)
+
+	return b.String()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateExample(t *testing.T) {
+	got, err := generate("internal/example", "Config", "config_env.go")
+	require.NoError(t, err)
+
+	want, err := ioutil.ReadFile("internal/example/config_env.go")
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got), "run go generate ./... to update the example")
+}
+
+// generateSource runs the generator on a package made of src.
+func generateSource(t *testing.T, src string) ([]byte, error) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "envgen")
+	require.NoError(t, err)
+
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "config.go"), []byte(src), 0o600))
+
+	return generate(dir, "Config", "config_env.go")
+}
+
+func TestGenerateUnsupported(t *testing.T) {
+	for _, src := range []string{
+		"package p\n\nimport \"net/url\"\n\ntype Config struct {\n\tURL url.URL\n}\n",
+		"package p\n\ntype Config struct {\n\tDebug bool `env:\"invert\"`\n}\n",
+		"package p\n\ntype Config struct {\n\tLevel int `levels:\"low,high\"`\n}\n",
+		"package p\n\ntype Config struct {\n\t*Base\n}\n\ntype Base struct {\n\tHost string\n}\n",
+		"package p\n\ntype Config struct {\n\tBase `env:\"prefix:DB_\"`\n}\n\ntype Base struct {\n\tHost string\n}\n",
+		"package p\n\ntype Config struct {\n\tLabels map[string]int\n}\n",
+		"package p\n\ntype Config struct {\n\tLabels []map[string]string\n}\n",
+		"package p\n\ntype Config struct {\n\tLabels map[string]string `kvSep:\":\"`\n}\n",
+		"package p\n\ntype Config struct {\n\tHost string\n\tURL string `default:\"http://{{ .Host }}\"`\n}\n",
+	} {
+		_, err := generateSource(t, src)
+		assert.True(t, errors.Is(err, errUnsupported), src)
+	}
+}
+
+func TestGenerateMalformed(t *testing.T) {
+	for _, src := range []string{
+		"package p\n\ntype Config struct {\n\tHost string `env:\"required\" default:\"localhost\"`\n}\n",
+		"package p\n\ntype Config struct {\n\tHost string `env:\"a,b\"`\n}\n",
+		"package p\n\ntype Config struct {\n\tPorts []int `default:\"\\\"80\"`\n}\n",
+		"package p\n\ntype Config struct {\n\tHost *string `env:\"notEmpty\"`\n}\n",
+	} {
+		_, err := generateSource(t, src)
+		assert.True(t, errors.Is(err, errMalformedTag), src)
+	}
+}
+
+func TestGenerateNotFound(t *testing.T) {
+	_, err := generateSource(t, "package p\n\ntype Other struct{}\n")
+	assert.Error(t, err)
+}
@@ -0,0 +1,30 @@
+// Package example declares a configuration whose parser is generated by
+// envgen, to check that it behaves like env.Parse.
+package example
+
+import "time"
+
+//go:generate go run github.com/Alex616/go-env/cmd/envgen -type Config
+
+// Server is embedded in Config.
+type Server struct {
+	Host string `env:"required"`
+	Port uint16 `default:"80"`
+}
+
+// Config is parsed by the generated ParseConfig.
+type Config struct {
+	Server
+	Timeout  time.Duration `default:"30s"`
+	Debug    bool          `env:"DEBUG,alias:VERBOSE"`
+	Workers  *int
+	Ratio    float64
+	Tags     []string          `default:"a,b"`
+	Ports    []int             `default:"80,443"`
+	Labels   map[string]string `default:"team=core"`
+	Pin      int               `env:"secret"`
+	Name     string            `env:"verbatim"`
+	Region   string            `env:"notEmpty"`
+	internal string
+	Ignored  string `env:"-"`
+}
@@ -0,0 +1,284 @@
+// Code generated by envgen -type Config; DO NOT EDIT.
+
+package example
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	env "github.com/Alex616/go-env"
+)
+
+// ParseConfig sets the fields of dest from the variables returned by
+// lookup, such as os.LookupEnv, like env.Parse would.
+func ParseConfig(dest *Config, lookup func(string) (string, bool)) error {
+	var errs env.Errors
+
+	var found [6]bool
+
+	if _, value, ok := configLookup(lookup, "host"); ok {
+		found[0] = true
+
+		dest.Server.Host = value
+	}
+
+	if name, value, ok := configLookup(lookup, "port"); ok {
+		found[1] = true
+
+		if v, err := configUint16(value); err != nil {
+			errs = append(errs, configError("Config.Server.Port", name,
+				"error processing environment variable %s: %w", err))
+		} else {
+			dest.Server.Port = v
+		}
+	}
+
+	if name, value, ok := configLookup(lookup, "timeout"); ok {
+		found[2] = true
+
+		if v, err := configDuration(value); err != nil {
+			errs = append(errs, configError("Config.Timeout", name,
+				"error processing environment variable %s: %w", err))
+		} else {
+			dest.Timeout = v
+		}
+	}
+
+	if name, value, ok := configLookup(lookup, "DEBUG", "VERBOSE"); ok {
+		if v, err := configBool(value); err != nil {
+			errs = append(errs, configError("Config.Debug", name,
+				"error processing environment variable %s: %w", err))
+		} else {
+			dest.Debug = v
+		}
+	}
+
+	if name, value, ok := configLookup(lookup, "workers"); ok {
+		if v, err := configInt(value); err != nil {
+			errs = append(errs, configError("Config.Workers", name,
+				"error processing environment variable %s: %w", err))
+		} else {
+			dest.Workers = &v
+		}
+	}
+
+	if name, value, ok := configLookup(lookup, "ratio"); ok {
+		if v, err := configFloat64(value); err != nil {
+			errs = append(errs, configError("Config.Ratio", name,
+				"error processing environment variable %s: %w", err))
+		} else {
+			dest.Ratio = v
+		}
+	}
+
+	if name, value, ok := configLookup(lookup, "tags"); ok {
+		found[3] = true
+
+		if values, err := csv.NewReader(strings.NewReader(value)).Read(); err != nil {
+			errs = append(errs, configError("Config.Tags", name,
+				"error reading a CSV string from environment variable %s with multiple values: %w", err))
+		} else {
+			dest.Tags = values
+		}
+	}
+
+	if name, value, ok := configLookup(lookup, "ports"); ok {
+		found[4] = true
+
+		if values, err := csv.NewReader(strings.NewReader(value)).Read(); err != nil {
+			errs = append(errs, configError("Config.Ports", name,
+				"error reading a CSV string from environment variable %s with multiple values: %w", err))
+		} else if v, err := configIntSlice(values); err != nil {
+			errs = append(errs, configError("Config.Ports", name,
+				"error processing environment variable %s with multiple values: %w", err))
+		} else {
+			dest.Ports = v
+		}
+	}
+
+	if name, value, ok := configLookup(lookup, "labels"); ok {
+		found[5] = true
+
+		if v, err := configMap(value); err != nil {
+			errs = append(errs, configError("Config.Labels", name,
+				"error processing environment variable %s: %w", err))
+		} else {
+			dest.Labels = v
+		}
+	}
+
+	if name, value, ok := configLookup(lookup, "pin"); ok {
+		if v, err := configInt(value); err != nil {
+			errs = append(errs, configError("Config.Pin", name,
+				"error processing environment variable %s: %w", configRedact(err, value)))
+		} else {
+			dest.Pin = v
+		}
+	}
+
+	if _, value, ok := configLookup(lookup, "Name"); ok {
+		dest.Name = value
+	}
+
+	if name, value, ok := configLookup(lookup, "region"); ok {
+		if value == "" {
+			errs = append(errs, configError("Config.Region", name,
+				"environment variable %s: %w", env.ErrorEmptyValue))
+		} else {
+			dest.Region = value
+		}
+	}
+
+	if !found[0] {
+		errs = append(errs, &env.FieldError{
+			Field: "Config.Server.Host",
+			Name:  "host",
+			Err:   fmt.Errorf("%s: %w", "host", env.ErrorFieldIsRequired),
+		})
+	}
+
+	if !found[1] {
+		if v, err := configUint16("80"); err != nil {
+			errs = append(errs, configError("Config.Server.Port", "port",
+				"error processing default value for %s: %w", err))
+		} else {
+			dest.Server.Port = v
+		}
+	}
+
+	if !found[2] {
+		if v, err := configDuration("30s"); err != nil {
+			errs = append(errs, configError("Config.Timeout", "timeout",
+				"error processing default value for %s: %w", err))
+		} else {
+			dest.Timeout = v
+		}
+	}
+
+	if !found[3] {
+		dest.Tags = []string{"a", "b"}
+	}
+
+	if !found[4] {
+		if v, err := configIntSlice([]string{"80", "443"}); err != nil {
+			errs = append(errs, configError("Config.Ports", "ports",
+				"error processing default value for %s: %w", err))
+		} else {
+			dest.Ports = v
+		}
+	}
+
+	if !found[5] {
+		if v, err := configMap("team=core"); err != nil {
+			errs = append(errs, configError("Config.Labels", "labels",
+				"error processing default value for %s: %w", err))
+		} else {
+			dest.Labels = v
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func configLookup(lookup func(string) (string, bool), names ...string) (string, string, bool) {
+	for _, name := range names {
+		if value, ok := lookup(name); ok {
+			return name, value, true
+		}
+	}
+
+	return "", "", false
+}
+
+func configError(field, name, format string, err error) *env.FieldError {
+	return &env.FieldError{Field: field, Name: name, Err: fmt.Errorf(format, name, err)}
+}
+
+func configBool(s string) (bool, error) {
+	return strconv.ParseBool(s)
+}
+
+func configDuration(s string) (time.Duration, error) {
+	return time.ParseDuration(s)
+}
+
+func configFloat64(s string) (float64, error) {
+	return strconv.ParseFloat(s, 64)
+}
+
+func configInt(s string) (int, error) {
+	x, err := strconv.ParseInt(s, 10, 0)
+	return int(x), err
+}
+
+func configMap(s string) (map[string]string, error) {
+	m := make(map[string]string)
+	if s == "" {
+		return m, nil
+	}
+
+	entries, err := csv.NewReader(strings.NewReader(s)).Read()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		i := strings.Index(entry, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("%q: %w", entry, env.ErrorMalformedPair)
+		}
+
+		m[entry[:i]] = entry[i+1:]
+	}
+
+	return m, nil
+}
+
+func configUint16(s string) (uint16, error) {
+	x, err := strconv.ParseUint(s, 10, 16)
+	return uint16(x), err
+}
+
+func configIntSlice(values []string) ([]int, error) {
+	s := make([]int, len(values))
+
+	for i, value := range values {
+		v, err := configInt(value)
+		if err != nil {
+			return nil, err
+		}
+
+		s[i] = v
+	}
+
+	return s, nil
+}
+
+// configRedactedError hides a secret value from the message of err.
+type configRedactedError struct {
+	err   error
+	value string
+}
+
+func (e *configRedactedError) Error() string {
+	return strings.ReplaceAll(e.err.Error(), e.value, "***")
+}
+
+func (e *configRedactedError) Unwrap() error {
+	return e.err
+}
+
+func configRedact(err error, value string) error {
+	if value == "" {
+		return err
+	}
+
+	return &configRedactedError{err: err, value: value}
+}
@@ -0,0 +1,96 @@
+package example
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	env "github.com/Alex616/go-env"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parse parses vars with both the generated parser and env.
+func parse(t *testing.T, vars env.MapSource) (generated, reflected Config, generatedErr, reflectedErr error) {
+	t.Helper()
+
+	generatedErr = ParseConfig(&generated, vars.Lookup)
+
+	p, err := env.NewParser(env.Config{Sources: []env.Source{vars}}, &reflected)
+	require.NoError(t, err)
+
+	reflectedErr = p.Parse()
+
+	return generated, reflected, generatedErr, reflectedErr
+}
+
+func TestParseConfig(t *testing.T) {
+	generated, reflected, generatedErr, reflectedErr := parse(t, env.MapSource{
+		"host":    "example.com",
+		"VERBOSE": "true",
+		"workers": "4",
+		"ratio":   "0.5",
+		"tags":    "a,b",
+		"ports":   "80,443",
+		"labels":  "team=api,tier=",
+		"pin":     "1234",
+		"Name":    "api",
+		"region":  "eu",
+	})
+	require.NoError(t, generatedErr)
+	require.NoError(t, reflectedErr)
+	assert.Equal(t, reflected, generated)
+	assert.Equal(t, "example.com", generated.Host)
+	assert.Equal(t, uint16(80), generated.Port)
+	assert.Equal(t, 30*time.Second, generated.Timeout)
+	assert.True(t, generated.Debug)
+	require.NotNil(t, generated.Workers)
+	assert.Equal(t, 4, *generated.Workers)
+	assert.Equal(t, []int{80, 443}, generated.Ports)
+	assert.Equal(t, map[string]string{"team": "api", "tier": ""}, generated.Labels)
+}
+
+func TestParseConfigDefaults(t *testing.T) {
+	generated, reflected, generatedErr, reflectedErr := parse(t, env.MapSource{"host": "example.com"})
+	require.NoError(t, generatedErr)
+	require.NoError(t, reflectedErr)
+	assert.Equal(t, reflected, generated)
+	assert.Equal(t, []string{"a", "b"}, generated.Tags)
+	assert.Equal(t, []int{80, 443}, generated.Ports)
+	assert.Equal(t, map[string]string{"team": "core"}, generated.Labels)
+}
+
+func TestParseConfigEmptyMap(t *testing.T) {
+	generated, reflected, generatedErr, reflectedErr := parse(t, env.MapSource{"host": "example.com", "labels": ""})
+	require.NoError(t, generatedErr)
+	require.NoError(t, reflectedErr)
+	assert.Equal(t, reflected, generated)
+	assert.Equal(t, map[string]string{}, generated.Labels)
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	_, _, generatedErr, reflectedErr := parse(t, env.MapSource{
+		"port":    "http",
+		"timeout": "soon",
+		"ports":   "80,x",
+		"pin":     "secret",
+		"tags":    `"a`,
+		"labels":  "team",
+		"region":  "",
+	})
+	require.Error(t, generatedErr)
+	assert.True(t, errors.Is(generatedErr, env.ErrorFieldIsRequired))
+	assert.True(t, errors.Is(generatedErr, env.ErrorEmptyValue))
+	assert.NotContains(t, generatedErr.Error(), "secret")
+
+	var generated, reflected env.Errors
+	require.True(t, errors.As(generatedErr, &generated))
+	require.True(t, errors.As(reflectedErr, &reflected))
+	require.Len(t, generated, len(reflected))
+
+	for i := range reflected {
+		assert.Equal(t, reflected[i].Field, generated[i].Field)
+		assert.Equal(t, reflected[i].Name, generated[i].Name)
+		assert.Equal(t, reflected[i].Error(), generated[i].Error())
+	}
+}
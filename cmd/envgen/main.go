@@ -0,0 +1,75 @@
+// Command envgen generates a parser for the variables of a struct that does
+// not use reflection, so that programs with a tight startup budget can avoid
+// the cost of env.Parse and let the linker drop what they do not use.
+//
+// It is meant to be run by go generate from the package declaring the
+// struct:
+//
+//	//go:generate go run github.com/Alex616/go-env/cmd/envgen -type Config
+//
+// which writes config_env.go with
+//
+//	func ParseConfig(dest *Config, lookup func(string) (string, bool)) error
+//
+// to be called with os.LookupEnv or any other lookup function. The tags are
+// read like env.Parse does, with the default configuration: names are the
+// lower-cased field names unless set by the tag, and the name, alias,
+// verbatim, required, notEmpty, secret and mask items of the env tag as
+// well as the default tag are supported. Fields may be strings, booleans,
+// integers, floats or time.Duration, pointers to them, slices of them read
+// as comma-separated lists, or embedded structs of the same package. The
+// generator fails on anything else, such as text unmarshalers or the
+// onMissing and levels tags, which still need env.Parse.
+//
+// Failures are returned as env.Errors with the same fields, names and
+// messages as env.Parse, without the hints.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	typeName := flag.String("type", "", "name of the struct type to generate a parser for")
+	output := flag.String("output", "", "output file name, <type>_env.go by default")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: envgen -type T [-output file] [directory]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *typeName == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+
+	if *output == "" {
+		*output = strings.ToLower(*typeName) + "_env.go"
+	}
+
+	if !filepath.IsAbs(*output) {
+		*output = filepath.Join(dir, *output)
+	}
+
+	src, err := generate(dir, *typeName, filepath.Base(*output))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "envgen: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := ioutil.WriteFile(*output, src, 0o644); err != nil { // nolint:gosec
+		fmt.Fprintf(os.Stderr, "envgen: %v\n", err)
+		os.Exit(1)
+	}
+}
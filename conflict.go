@@ -0,0 +1,103 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Conflict describes a variable set to different values by several
+// sources, only the first of which is used.
+type Conflict struct {
+	// Name is the name of the variable.
+	Name string
+	// Values are the values of the sources setting the variable, in order
+	// of precedence. Secret values are redacted.
+	Values []SourceValue
+}
+
+// SourceValue is the value of a variable in a source.
+type SourceValue struct {
+	// Source is the name of the source, see Named, or its position among
+	// the sources in order of precedence, such as "#2", if it has no name.
+	Source string
+	Value  string
+}
+
+// String lists the sources and their values, such as
+// `PORT: env="80" (used), file="8080"`.
+func (c Conflict) String() string {
+	values := make([]string, len(c.Values))
+	for i, v := range c.Values {
+		values[i] = fmt.Sprintf("%s=%q", v.Source, v.Value)
+	}
+
+	values[0] += " (used)"
+
+	return fmt.Sprintf("%s: %s", c.Name, strings.Join(values, ", "))
+}
+
+// detectsConflicts returns true if conflicting sources must be looked for.
+func (c Config) detectsConflicts() bool {
+	return c.OnConflict != nil || c.StrictConflicts
+}
+
+// conflict returns the conflict of the sources setting the option to
+// different values, if any.
+func (p *Parser) conflict(spec *spec, sources []Source) (Conflict, bool) {
+	c := Conflict{Name: spec.name}
+
+	distinct := false
+
+	for _, i := range spec.order(sources) {
+		src := sources[i]
+
+		_, value, found := spec.lookup([]Source{src})
+		if !found {
+			continue
+		}
+
+		if len(c.Values) > 0 && value != c.Values[0].Value {
+			distinct = true
+		}
+
+		c.Values = append(c.Values, SourceValue{Source: sourceLabel(src, i), Value: value})
+	}
+
+	if !distinct {
+		return Conflict{}, false
+	}
+
+	for i := range c.Values {
+		c.Values[i].Value = spec.redact(c.Values[i].Value)
+	}
+
+	return c, true
+}
+
+// sourceLabel returns the name of src, or its position i in the sources.
+func sourceLabel(src Source, i int) string {
+	if name := sourceName(src); name != "" {
+		return name
+	}
+
+	return fmt.Sprintf("#%d", i+1)
+}
+
+// checkConflict reports the conflict of the sources of the option, if any,
+// to Config.OnConflict, and returns an error in strict mode.
+func (p *Parser) checkConflict(spec *spec, sources []Source) error {
+	c, ok := p.conflict(spec, sources)
+	if !ok {
+		return nil
+	}
+
+	if p.config.OnConflict != nil {
+		p.config.OnConflict(c)
+	}
+
+	if p.config.StrictConflicts {
+		return fmt.Errorf("%s: %w", c, ErrorConflictingSources)
+	}
+
+	return nil
+}
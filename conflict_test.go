@@ -0,0 +1,61 @@
+package env
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnConflict(t *testing.T) {
+	var envs struct {
+		Port  int
+		Host  string
+		Token string `env:"secret"`
+	}
+
+	var conflicts []Conflict
+
+	p, err := NewParser(Config{
+		Sources: []Source{
+			EnvironSource([]string{"port=80", "host=a", "token=x"}),
+			NamedSource("file", MapSource{"port": "8080", "host": "a"}),
+			MapSource{"port": "9090", "token": "y"},
+		},
+		OnConflict: func(c Conflict) { conflicts = append(conflicts, c) },
+	}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, 80, envs.Port)
+	assert.Equal(t, "x", envs.Token)
+
+	require.Len(t, conflicts, 2)
+	assert.Equal(t, Conflict{Name: "port", Values: []SourceValue{
+		{Source: "#1", Value: "80"},
+		{Source: "file", Value: "8080"},
+		{Source: "#3", Value: "9090"},
+	}}, conflicts[0])
+	assert.Equal(t, `port: #1="80" (used), file="8080", #3="9090"`, conflicts[0].String())
+	assert.Equal(t, `token: #1="***" (used), #3="***"`, conflicts[1].String())
+}
+
+func TestStrictConflicts(t *testing.T) {
+	var envs struct {
+		Port int
+	}
+
+	p, err := NewParser(Config{
+		Sources: []Source{
+			NamedSource("env", MapSource{"port": "80"}),
+			NamedSource("file", MapSource{"port": "8080"}),
+		},
+		StrictConflicts: true,
+	}, &envs)
+	require.NoError(t, err)
+
+	err = p.Parse()
+	assert.True(t, errors.Is(err, ErrorConflictingSources))
+	assert.Contains(t, err.Error(), `port: env="80" (used), file="8080"`)
+	assert.Equal(t, 0, envs.Port)
+}
@@ -0,0 +1,239 @@
+package env
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultConsulWait bounds the blocking queries of ConsulSource.Watch.
+	defaultConsulWait = 5 * time.Minute
+	// consulRetryInterval is the delay before retrying a failed blocking query.
+	consulRetryInterval = time.Second
+	// defaultLoadTimeout bounds the requests of the Load methods of the
+	// key-value store sources.
+	defaultLoadTimeout = 10 * time.Second
+)
+
+// ConsulSource is a Source reading variables from the keys under a prefix
+// of the Consul KV store, through the Consul HTTP API. The part of a key
+// after the prefix is matched like the keys of FileSource, slashes being
+// treated as underscores, so that the key myapp/db/host provides DB_HOST
+// when the prefix is myapp/.
+type ConsulSource struct {
+	// Address is the base URL of the Consul agent, such as
+	// "http://127.0.0.1:8500".
+	Address string
+	// Prefix is the KV prefix under which the variables are read.
+	Prefix string
+	// Token is the ACL token sent with the requests, if any.
+	Token string
+	// Client is the HTTP client, http.DefaultClient if nil.
+	Client *http.Client
+	// WaitTime bounds the blocking queries of Watch, 5 minutes if zero.
+	WaitTime time.Duration
+	// Timeout bounds the request of Load, 10 seconds if zero.
+	Timeout time.Duration
+
+	mu     sync.RWMutex
+	values map[string]string
+	index  uint64
+}
+
+// Load reads the keys under the prefix, failing after Timeout.
+func (s *ConsulSource) Load() error {
+	ctx, cancel := context.WithTimeout(context.Background(), loadTimeout(s.Timeout))
+	defer cancel()
+
+	values, index, err := s.fetch(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	s.store(values, index)
+
+	return nil
+}
+
+// Lookup returns the value of the variable from the last read keys.
+func (s *ConsulSource) Lookup(name string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	value, found := s.values[normalizeKey(name)]
+
+	return value, found
+}
+
+// Watch runs blocking queries on the prefix until ctx is done, and reloads
+// p whenever a key changes, so that the OnChange callbacks of p are
+// invoked. Agents not returning an index, whose queries do not block, are
+// polled every second instead. Failed queries and reloads are reported to
+// the OnError callbacks of p. It returns the context error.
+func (s *ConsulSource) Watch(ctx context.Context, p *Parser) error {
+	for {
+		s.mu.RLock()
+		index := s.index
+		s.mu.RUnlock()
+
+		values, next, err := s.fetch(ctx, index)
+
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case err != nil:
+			p.reportError(err)
+
+			if err := sleepContext(ctx, consulRetryInterval); err != nil {
+				return err
+			}
+		case next == 0:
+			// without an index the query did not block, poll instead
+			if s.changed(values) {
+				s.store(values, 0)
+
+				if err := p.Reload(); err != nil {
+					p.reportError(err)
+				}
+			}
+
+			if err := sleepContext(ctx, consulRetryInterval); err != nil {
+				return err
+			}
+		case next < index:
+			// the index went backwards, start over as advised by Consul
+			s.store(values, 0)
+		case next != index:
+			s.store(values, next)
+
+			if err := p.Reload(); err != nil {
+				p.reportError(err)
+			}
+		}
+	}
+}
+
+// changed reports whether values differ from the last values read.
+func (s *ConsulSource) changed(values map[string]string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return !reflect.DeepEqual(s.values, values)
+}
+
+// sleepContext waits for d, and returns the context error if ctx is done
+// first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// store replaces the values read from Consul and the index they were read
+// at.
+func (s *ConsulSource) store(values map[string]string, index uint64) {
+	s.mu.Lock()
+	s.values = values
+	s.index = index
+	s.mu.Unlock()
+}
+
+// fetch reads the keys under the prefix, blocking until they change if
+// index is not zero, and returns them along with the current index.
+func (s *ConsulSource) fetch(ctx context.Context, index uint64) (map[string]string, uint64, error) {
+	prefix := strings.TrimLeft(s.Prefix, "/")
+
+	query := url.Values{"recurse": {"true"}}
+	if index > 0 {
+		wait := s.WaitTime
+		if wait <= 0 {
+			wait = defaultConsulWait
+		}
+
+		query.Set("index", strconv.FormatUint(index, 10))
+		query.Set("wait", fmt.Sprintf("%ds", int(wait.Seconds())))
+	}
+
+	u := strings.TrimRight(s.Address, "/") + "/v1/kv/" + prefix + "?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if s.Token != "" {
+		req.Header.Set("X-Consul-Token", s.Token)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	next, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	values := make(map[string]string)
+
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		// no key under the prefix
+		return values, next, nil
+	case http.StatusOK:
+	default:
+		return nil, 0, fmt.Errorf("error reading consul prefix %q: %s", s.Prefix, resp.Status) // nolint:goerr113
+	}
+
+	var pairs []struct {
+		Key   string
+		Value []byte
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&pairs); err != nil {
+		return nil, 0, fmt.Errorf("error reading consul prefix %q: %w", s.Prefix, err)
+	}
+
+	for _, pair := range pairs {
+		if key, ok := prefixedKey(pair.Key, prefix); ok {
+			values[key] = string(pair.Value)
+		}
+	}
+
+	return values, next, nil
+}
+
+// loadTimeout returns timeout, or defaultLoadTimeout if it is not positive.
+func loadTimeout(timeout time.Duration) time.Duration {
+	if timeout <= 0 {
+		return defaultLoadTimeout
+	}
+
+	return timeout
+}
+
+// prefixedKey returns the normalized form of the part of a key-value store
+// key after prefix, slashes being treated as underscores, and false for
+// folder keys ending with a slash.
+func prefixedKey(key, prefix string) (string, bool) {
+	name := strings.Trim(strings.TrimPrefix(key, prefix), "/")
+	if name == "" || strings.HasSuffix(key, "/") {
+		return "", false
+	}
+
+	return normalizeKey(strings.Replace(name, "/", "_", -1)), true
+}
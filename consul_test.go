@@ -0,0 +1,202 @@
+package env
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConsul serves the keys of a KV store the way the Consul HTTP API
+// does, including blocking queries.
+type fakeConsul struct {
+	mu      sync.Mutex
+	index   uint64
+	kv      map[string]string
+	changed chan struct{}
+}
+
+func newFakeConsul(kv map[string]string) *fakeConsul {
+	return &fakeConsul{index: 1, kv: kv, changed: make(chan struct{})}
+}
+
+func (c *fakeConsul) set(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.kv[key] = value
+	c.index++
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+func (c *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Consul-Token") != "token" {
+		w.WriteHeader(http.StatusForbidden)
+
+		return
+	}
+
+	c.mu.Lock()
+	index, changed := c.index, c.changed
+	c.mu.Unlock()
+
+	if r.URL.Query().Get("index") == strconv.FormatUint(index, 10) {
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	type pair struct {
+		Key   string
+		Value []byte
+	}
+
+	pairs := []pair{{Key: "myapp/"}}
+	for k, v := range c.kv {
+		pairs = append(pairs, pair{k, []byte(v)})
+	}
+
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(c.index, 10))
+	_ = json.NewEncoder(w).Encode(pairs)
+}
+
+func TestConsulSource(t *testing.T) {
+	var envs struct {
+		Workers int
+		DBHost  string `env:"DB_HOST"`
+		Name    string `default:"foo"`
+	}
+
+	server := httptest.NewServer(newFakeConsul(map[string]string{
+		"myapp/workers": "4",
+		"myapp/db/host": "localhost",
+		"other/name":    "bar",
+	}))
+	defer server.Close()
+
+	src := &ConsulSource{Address: server.URL, Prefix: "myapp/", Token: "token"}
+
+	p, err := NewParser(Config{Sources: []Source{src}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, 4, envs.Workers)
+	assert.Equal(t, "localhost", envs.DBHost)
+	assert.Equal(t, "foo", envs.Name)
+}
+
+func TestConsulSourceErrors(t *testing.T) {
+	var envs struct {
+		Workers int
+	}
+
+	server := httptest.NewServer(newFakeConsul(map[string]string{}))
+	defer server.Close()
+
+	p, err := NewParser(Config{Sources: []Source{&ConsulSource{Address: server.URL, Prefix: "myapp"}}}, &envs)
+	require.NoError(t, err)
+	assert.EqualError(t, p.Parse(), `error loading source: error reading consul prefix "myapp": 403 Forbidden`)
+}
+
+func TestConsulSourceWatch(t *testing.T) {
+	var envs struct {
+		Workers int
+	}
+
+	consul := newFakeConsul(map[string]string{"myapp/workers": "4"})
+
+	server := httptest.NewServer(consul)
+	defer server.Close()
+
+	src := &ConsulSource{Address: server.URL, Prefix: "myapp", Token: "token", WaitTime: time.Minute}
+
+	p, err := NewParser(Config{Sources: []Source{src}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changed := make(chan interface{}, 1)
+
+	p.OnChange(func(field string, old, new interface{}) {
+		changed <- new
+		cancel()
+	})
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- src.Watch(ctx, p)
+	}()
+
+	consul.set("myapp/workers", "8")
+
+	assert.Equal(t, 8, <-changed)
+	assert.Equal(t, context.Canceled, <-done)
+	assert.Equal(t, 8, envs.Workers)
+}
+
+func TestConsulSourceTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	src := &ConsulSource{Address: server.URL, Prefix: "myapp", Timeout: 10 * time.Millisecond}
+
+	err := src.Load()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, defaultLoadTimeout, loadTimeout(0))
+}
+
+func TestConsulSourceWatchWithoutIndex(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+
+		_, _ = w.Write([]byte(`[{"Key": "myapp/workers", "Value": "NA=="}]`))
+	}))
+	defer server.Close()
+
+	var envs struct {
+		Workers int
+	}
+
+	src := &ConsulSource{Address: server.URL, Prefix: "myapp"}
+
+	p, err := NewParser(Config{Sources: []Source{src}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, 4, envs.Workers)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, src.Watch(ctx, p))
+
+	// the queries that do not block are not repeated in a loop
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 2, requests)
+}
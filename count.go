@@ -0,0 +1,100 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// level is a named value of an int field tagged with levels.
+type level struct {
+	name  string
+	value int64
+}
+
+// parseLevels parses a levels tag such as "error=0,warn=1,info=2". Names
+// without an explicit value get their position in the list.
+func parseLevels(tag string) ([]level, error) {
+	var levels []level
+
+	for i, entry := range strings.Split(tag, ",") {
+		entry = strings.TrimSpace(entry)
+		name, value := entry, int64(i)
+
+		if pos := strings.Index(entry, "="); pos != -1 {
+			n, err := strconv.ParseInt(strings.TrimSpace(entry[pos+1:]), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", entry, ErrorInvalidLevels)
+			}
+
+			name, value = strings.TrimSpace(entry[:pos]), n
+		}
+
+		if name == "" {
+			return nil, fmt.Errorf("%q: %w", tag, ErrorInvalidLevels)
+		}
+
+		levels = append(levels, level{name: name, value: value})
+	}
+
+	return levels, nil
+}
+
+// isInteger returns true if t is an integer type or a pointer to one.
+func isInteger(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+// decodeLevel translates level names and, for counting options, truthy
+// values and repeated letters such as "vvv" into integers. Other values are
+// returned unchanged.
+func (s *spec) decodeLevel(value string) string {
+	for _, l := range s.levels {
+		if strings.EqualFold(l.name, value) {
+			return strconv.FormatInt(l.value, 10)
+		}
+	}
+
+	if !s.count {
+		return value
+	}
+
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return value
+	}
+
+	if b, err := strconv.ParseBool(value); err == nil {
+		if b {
+			return "1"
+		}
+
+		return "0"
+	}
+
+	if value != "" && strings.Count(value, value[:1]) == len(value) {
+		return strconv.Itoa(len(value))
+	}
+
+	return value
+}
+
+// levelNames returns the level names for the help message.
+func (s *spec) levelNames() []string {
+	names := make([]string, len(s.levels))
+	for i, l := range s.levels {
+		names[i] = l.name
+	}
+
+	return names
+}
@@ -0,0 +1,94 @@
+package env
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCount(t *testing.T) {
+	var envs struct {
+		Verbose int `env:"verbose,count"`
+	}
+
+	for value, expected := range map[string]int{
+		"":      0,
+		"2":     2,
+		"true":  1,
+		"false": 0,
+		"v":     1,
+		"vvv":   3,
+	} {
+		envs.Verbose = 0
+		err := parse(envsMap{"verbose": value}, &envs)
+
+		if value == "" {
+			assert.Error(t, err)
+
+			continue
+		}
+
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, envs.Verbose, value)
+	}
+
+	err := parse(envsMap{"verbose": "vvx"}, &envs)
+	assert.Error(t, err)
+}
+
+func TestLevels(t *testing.T) {
+	var envs struct {
+		Verbosity uint8 `levels:"error,warn,info,debug" default:"info"`
+		Trace     *int  `levels:"off=0, on=10"`
+	}
+
+	err := parse(envsMap{}, &envs)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, envs.Verbosity)
+	assert.Nil(t, envs.Trace)
+
+	err = parse(envsMap{"verbosity": "DEBUG", "trace": "on"}, &envs)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, envs.Verbosity)
+	require.NotNil(t, envs.Trace)
+	assert.Equal(t, 10, *envs.Trace)
+
+	envs.Trace = nil
+	err = parse(envsMap{"verbosity": "1"}, &envs)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, envs.Verbosity)
+
+	envs.Trace = nil
+	err = parse(envsMap{"verbosity": "loud"}, &envs)
+	assert.Error(t, err)
+}
+
+func TestLevelsHelp(t *testing.T) {
+	var envs struct {
+		Verbosity int `levels:"error,warn,info,debug" default:"info"`
+	}
+
+	p, err := NewParser(Config{}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, `Environments:
+  verbosity int [levels: error, warn, info, debug, default: info]
+`, p.Help())
+}
+
+func TestCountErrors(t *testing.T) {
+	var notInt struct {
+		Verbose string `env:"verbose,count"`
+	}
+
+	err := parse(envsMap{}, &notInt)
+	assert.True(t, errors.Is(err, ErrorCountNotInteger))
+
+	var badLevels struct {
+		Verbosity int `levels:"error=x"`
+	}
+
+	err = parse(envsMap{}, &badLevels)
+	assert.True(t, errors.Is(err, ErrorInvalidLevels))
+}
@@ -0,0 +1,89 @@
+package env
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// CSVOptions controls how the values of lists are split into elements, see
+// Config.CSV and the csv tag.
+type CSVOptions struct {
+	// Comma separates the elements. Defaults to ','.
+	Comma rune
+	// LazyQuotes accepts bare quotes in unquoted elements and quotes
+	// within quoted elements, see csv.Reader.
+	LazyQuotes bool
+	// TrimLeadingSpace ignores the spaces at the start of the elements.
+	TrimLeadingSpace bool
+	// Raw splits the values on Comma without interpreting quotes at all.
+	Raw bool
+}
+
+// split splits value into the elements of a list.
+func (o CSVOptions) split(value string) ([]string, error) {
+	comma := o.Comma
+	if comma == 0 {
+		comma = ','
+	}
+
+	if o.Raw {
+		values := strings.Split(value, string(comma))
+
+		if o.TrimLeadingSpace {
+			for i, v := range values {
+				values[i] = strings.TrimLeft(v, " \t")
+			}
+		}
+
+		return values, nil
+	}
+
+	r := csv.NewReader(strings.NewReader(value))
+	r.Comma = comma
+	r.LazyQuotes = o.LazyQuotes
+	r.TrimLeadingSpace = o.TrimLeadingSpace
+
+	return r.Read()
+}
+
+// parseCSVOptions parses a csv tag, such as "lazyQuotes,trimLeadingSpace"
+// or "raw,comma:;".
+func parseCSVOptions(tag string) (*CSVOptions, error) {
+	var o CSVOptions
+
+	for _, item := range strings.Split(tag, ",") {
+		item = strings.TrimSpace(item)
+
+		switch {
+		case item == "lazyQuotes":
+			o.LazyQuotes = true
+		case item == "trimLeadingSpace":
+			o.TrimLeadingSpace = true
+		case item == "raw":
+			o.Raw = true
+		case strings.HasPrefix(item, "comma:"):
+			comma := strings.TrimPrefix(item, "comma:")
+			if utf8.RuneCountInString(comma) != 1 {
+				return nil, fmt.Errorf("csv %q: %w", item, ErrorMalformedTag)
+			}
+
+			o.Comma, _ = utf8.DecodeRuneInString(comma)
+		default:
+			return nil, fmt.Errorf("csv %q: %w", item, ErrorUnrecognizedTag)
+		}
+	}
+
+	return &o, nil
+}
+
+// csvOptions returns the options splitting the values of spec: those of
+// its csv tag, or else those of the configuration.
+func (p *Parser) csvOptions(spec *spec) CSVOptions {
+	if spec.csv != nil {
+		return *spec.csv
+	}
+
+	return p.config.CSV
+}
@@ -0,0 +1,82 @@
+package env
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVOptions(t *testing.T) {
+	for _, test := range []struct {
+		options CSVOptions
+		value   string
+		want    []string
+	}{
+		{CSVOptions{}, `a,"b,c"`, []string{"a", "b,c"}},
+		{CSVOptions{LazyQuotes: true}, `5" screen,b`, []string{`5" screen`, "b"}},
+		{CSVOptions{TrimLeadingSpace: true}, "a, b,  c", []string{"a", "b", "c"}},
+		{CSVOptions{Raw: true}, `a,"b,c"`, []string{"a", `"b`, `c"`}},
+		{CSVOptions{Raw: true, TrimLeadingSpace: true, Comma: ';'}, "a; b", []string{"a", "b"}},
+		{CSVOptions{Comma: ';'}, `a;"b;c"`, []string{"a", "b;c"}},
+	} {
+		values, err := test.options.split(test.value)
+		require.NoError(t, err, test.value)
+		assert.Equal(t, test.want, values, test.value)
+	}
+
+	_, err := CSVOptions{}.split(`5" screen,b`)
+	assert.Error(t, err)
+}
+
+func TestCSVTag(t *testing.T) {
+	var envs struct {
+		Sizes   []string `csv:"lazyQuotes,trimLeadingSpace"`
+		Paths   []string `csv:"raw,comma::"`
+		Default []string `csv:"raw" default:"a,\"b"`
+	}
+
+	err := parse(envsMap{"sizes": `5" screen, 7" screen`, "paths": "/bin:/usr/bin"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`5" screen`, `7" screen`}, envs.Sizes)
+	assert.Equal(t, []string{"/bin", "/usr/bin"}, envs.Paths)
+	assert.Equal(t, []string{"a", `"b`}, envs.Default)
+}
+
+func TestConfigCSV(t *testing.T) {
+	var envs struct {
+		Sizes []string
+	}
+
+	p, err := NewParser(Config{
+		Sources: []Source{MapSource{"sizes": `5" screen`}},
+		CSV:     CSVOptions{LazyQuotes: true},
+	}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, []string{`5" screen`}, envs.Sizes)
+}
+
+func TestCSVTagErrors(t *testing.T) {
+	var notSlice struct {
+		Name string `csv:"raw"`
+	}
+
+	_, err := NewParser(Config{}, &notSlice)
+	assert.True(t, errors.Is(err, ErrorCSVNotSlice))
+
+	var unknown struct {
+		Names []string `csv:"quote:'"`
+	}
+
+	_, err = NewParser(Config{}, &unknown)
+	assert.True(t, errors.Is(err, ErrorUnrecognizedTag))
+
+	var comma struct {
+		Names []string `csv:"comma:ab"`
+	}
+
+	_, err = NewParser(Config{}, &comma)
+	assert.True(t, errors.Is(err, ErrorMalformedTag))
+}
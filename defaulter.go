@@ -0,0 +1,57 @@
+package env
+
+import "reflect"
+
+// Defaulter is the interface that the destination structs can implement
+// to compute default values at parse time, such as the hostname or the
+// number of CPUs, which default tags cannot express.
+type Defaulter interface {
+	// DefaultEnv returns the default value of the field with the given
+	// dotted path, such as "DB.Host", in the form of an environment value,
+	// and false to fall back on the default tag or field value.
+	DefaultEnv(field string) (string, bool)
+}
+
+// dynamicDefault returns the default value of the option computed by the
+// root struct, if it implements Defaulter.
+func (p *Parser) dynamicDefault(spec *spec) (string, bool) {
+	d, ok := p.roots[spec.dest.root].Interface().(Defaulter)
+	if !ok {
+		return "", false
+	}
+
+	return d.DefaultEnv(spec.dest.fieldPath())
+}
+
+// parseDefault parses a default value into dest, as a CSV string for
+// multiple options, once expanded and read from its file if any.
+func (p *Parser) parseDefault(dest reflect.Value, spec *spec, value string) error {
+	value, err := spec.readFileValue(p.expand(spec, value))
+	if err != nil {
+		return err
+	}
+
+	if spec.mapped {
+		return p.parseMap(dest, spec, value)
+	}
+
+	if !spec.multiple {
+		relaid, err := spec.relayout(spec.decodeLevel(value))
+		if err != nil {
+			return err
+		}
+
+		return p.parseValue(dest, relaid)
+	}
+
+	values, err := p.csvOptions(spec).split(value)
+	if err != nil {
+		return err
+	}
+
+	if values, err = spec.relayoutAll(values); err != nil {
+		return err
+	}
+
+	return p.setSlice(dest, values)
+}
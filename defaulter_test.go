@@ -0,0 +1,60 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type defaulterEnvs struct {
+	Host    string `default:"static"`
+	Workers int
+	Hosts   []string
+	Port    int
+	DB      struct {
+		Name string
+	} `onMissing:"disable"`
+}
+
+func (defaulterEnvs) DefaultEnv(field string) (string, bool) {
+	switch field {
+	case "Host":
+		return "dynamic", true
+	case "Workers":
+		return "4", true
+	case "Hosts":
+		return "a,b", true
+	case "Port":
+		return "x", false
+	case "DB.Name":
+		return "db", true
+	}
+
+	return "", false
+}
+
+func TestDefaulter(t *testing.T) {
+	var envs defaulterEnvs
+
+	err := parse(envsMap{"workers": "8", "name": "set"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "dynamic", envs.Host)
+	assert.Equal(t, 8, envs.Workers)
+	assert.Equal(t, []string{"a", "b"}, envs.Hosts)
+	assert.Equal(t, 0, envs.Port)
+	assert.Equal(t, "set", envs.DB.Name)
+}
+
+type badDefaulterEnvs struct {
+	Workers int
+}
+
+func (badDefaulterEnvs) DefaultEnv(string) (string, bool) {
+	return "many", true
+}
+
+func TestDefaulterInvalidValue(t *testing.T) {
+	err := parse(envsMap{}, &badDefaulterEnvs{})
+	assert.EqualError(t, err, `error processing default value for workers: strconv.ParseInt: parsing "many": invalid syntax`)
+}
@@ -0,0 +1,139 @@
+package env
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// dirSource is a Source reading one variable per file of a directory.
+type dirSource struct {
+	path string
+
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// DirSource returns a Source reading one variable per file of a directory,
+// the file name being the name of the variable and its contents the value,
+// which is how Kubernetes mounts Secrets and ConfigMaps as volumes. A single
+// trailing newline is removed from the contents. Hidden files, such as the
+// ..data links maintained by Kubernetes, and subdirectories are ignored. The
+// directory is read again before every Parse, so that rotated secrets are
+// picked up by Reload.
+func DirSource(path string) Source {
+	return &dirSource{path: path}
+}
+
+// Load reads the files of the directory.
+func (s *dirSource) Load() error {
+	entries, err := ioutil.ReadDir(s.path)
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string]string, len(entries))
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		file := filepath.Join(s.path, name)
+
+		// follow the symbolic links to the actual files
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		value := strings.TrimSuffix(string(data), "\n")
+		values[name] = strings.TrimSuffix(value, "\r")
+	}
+
+	s.mu.Lock()
+	s.values = values
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Lookup returns the contents of the file named after the variable.
+func (s *dirSource) Lookup(name string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	value, found := s.values[name]
+
+	return value, found
+}
+
+// Names returns the names of the files read.
+func (s *dirSource) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.values))
+	for name := range s.values {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// prefixSource is a Source providing the variables of another source under
+// prefixed names.
+type prefixSource struct {
+	prefix string
+	src    Source
+}
+
+// PrefixSource returns a Source providing the variables of src under names
+// starting with prefix, so that PrefixSource("DB_", DirSource("/etc/db"))
+// provides DB_PASSWORD from the file /etc/db/PASSWORD. Sources implementing
+// Loader are still loaded.
+func PrefixSource(prefix string, src Source) Source {
+	return prefixSource{prefix: prefix, src: src}
+}
+
+// Lookup returns the value of the variable without the prefix from the
+// underlying source.
+func (s prefixSource) Lookup(name string) (string, bool) {
+	if !strings.HasPrefix(name, s.prefix) {
+		return "", false
+	}
+
+	return s.src.Lookup(strings.TrimPrefix(name, s.prefix))
+}
+
+// Load loads the underlying source if it implements Loader.
+func (s prefixSource) Load() error {
+	if l, ok := s.src.(Loader); ok {
+		return l.Load()
+	}
+
+	return nil
+}
+
+// Names returns the prefixed names of the variables of the underlying
+// source if it implements Lister.
+func (s prefixSource) Names() []string {
+	l, ok := s.src.(Lister)
+	if !ok {
+		return nil
+	}
+
+	return prefixAll(s.prefix, l.Names())
+}
@@ -0,0 +1,58 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirSource(t *testing.T) {
+	var envs struct {
+		User     string `env:"user"`
+		Password string `env:"password"`
+		Hidden   string `env:".hidden"`
+		Sub      string `env:"sub"`
+		Name     string `env:"name"`
+	}
+
+	os.Clearenv()
+	_ = os.Setenv("user", "from-env")
+	_ = os.Setenv("name", "foo")
+
+	p, err := NewParser(Config{Sources: []Source{DirSource("testdata/secrets"), EnvSource()}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, "admin", envs.User)
+	assert.Equal(t, "hunter2", envs.Password)
+	assert.Equal(t, "", envs.Hidden)
+	assert.Equal(t, "", envs.Sub)
+	assert.Equal(t, "foo", envs.Name)
+}
+
+func TestDirSourceMissing(t *testing.T) {
+	var envs struct {
+		User string
+	}
+
+	p, err := NewParser(Config{Sources: []Source{DirSource("testdata/missing")}}, &envs)
+	require.NoError(t, err)
+	assert.True(t, errors.Is(p.Parse(), os.ErrNotExist))
+}
+
+func TestPrefixSource(t *testing.T) {
+	var envs struct {
+		User     string `env:"DB_user"`
+		Password string `env:"DB_password"`
+		Other    string `env:"password"`
+	}
+
+	p, err := NewParser(Config{Sources: []Source{PrefixSource("DB_", DirSource("testdata/secrets"))}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, "admin", envs.User)
+	assert.Equal(t, "hunter2", envs.Password)
+	assert.Equal(t, "", envs.Other)
+}
@@ -11,4 +11,30 @@
 // defines two environments, which can be set using any of
 //
 //	iter=1 debug=true ./example  // debug is a boolean flag so its value is set to true
+//
+// The env tag is a comma-separated list of items, surrounding spaces being
+// ignored:
+//
+//	tag    = item { "," item }
+//	item   = option | key ":" value | name
+//	option = "required" | "secret" | "mask" | "invert" | "count" | "verbatim" |
+//	         "catchall" | "unset" | "notEmpty" | "expand" | "file"
+//	key    = "name" | "alias" | "minitems" | "maxitems"
+//
+// A bare name, or the name key, sets the name of the variable, which can
+// only be done once. So does the verbatim option, which names the variable
+// exactly like the Go field instead of lowercasing it. The alias key may be repeated. Options take no value.
+// The minitems and maxitems keys bound the number of elements of slices.
+// The notEmpty option rejects variables set to an empty value.
+// The unset option removes the variable that provided the value from the
+// process environment once Parse has applied it, so that secrets are not
+// inherited by child processes.
+// The expand option replaces the ${VAR} references of the values, and of
+// the defaults, with the values of the variables, ${VAR:-default} giving
+// default when VAR is unset or empty.
+// The file option reads the values, and the defaults, from the files whose
+// paths the variables hold, without the trailing newline.
+// Invalid tags are reported with ErrorUnrecognizedTag for unknown keys,
+// ErrorMalformedTag for missing or unexpected values and ErrorConflictingTag
+// for items that cannot be used together. The tag "-" ignores the field.
 package env
@@ -0,0 +1,174 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DocOptions select and frame the variables written by the documentation
+// generators WriteSpecs, WriteMarkdown, WriteDotEnv and WriteK8sEnv.
+type DocOptions struct {
+	// Sort orders the variables by name instead of by field.
+	Sort bool
+	// RequiredOnly keeps only the required variables.
+	RequiredOnly bool
+	// Group keeps only the variables of the embedded or nested struct with
+	// this dotted field path, such as "DB".
+	Group string
+	// Header and Footer are written verbatim before and after the
+	// document, so that it can be embedded in a larger one.
+	Header string
+	Footer string
+	// SecretName is the name of the Kubernetes Secret that WriteK8sEnv
+	// refers to for the secret variables, "<secret-name>" if empty.
+	SecretName string
+}
+
+// docSpecs returns the specs selected by the options, in the order they
+// should be documented, and the options themselves.
+func (p *Parser) docSpecs(opts []DocOptions) ([]*spec, DocOptions) {
+	var o DocOptions
+	if len(opts) > 0 {
+		o = opts[len(opts)-1]
+	}
+
+	specs := make([]*spec, 0, len(p.specs))
+
+	for _, spec := range p.specs {
+		if o.RequiredOnly && !spec.required {
+			continue
+		}
+
+		if o.Group != "" && !strings.HasPrefix(spec.dest.fieldPath(), o.Group+".") {
+			continue
+		}
+
+		specs = append(specs, spec)
+	}
+
+	if o.Sort {
+		sort.SliceStable(specs, func(i, j int) bool {
+			return specs[i].name < specs[j].name
+		})
+	}
+
+	return specs, o
+}
+
+// WriteMarkdown writes a Markdown table describing the variables.
+func (p *Parser) WriteMarkdown(w io.Writer, opts ...DocOptions) error {
+	specs, o := p.docSpecs(opts)
+
+	var b strings.Builder
+
+	b.WriteString(o.Header)
+	b.WriteString("| Variable | Type | Default | Required | Description |\n")
+	b.WriteString("|----------|------|---------|----------|-------------|\n")
+
+	for _, spec := range specs {
+		required := ""
+		if spec.required {
+			required = "yes"
+		}
+
+		def := spec.displayDefault()
+		if def != "" {
+			def = "`" + def + "`"
+		}
+
+		help := escapeMarkdown(spec.help)
+		if spec.docs != "" {
+			help = strings.TrimSpace(help + " [docs](" + escapeMarkdown(spec.docs) + ")")
+		}
+
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s |\n",
+			spec.name, escapeMarkdown(spec.docType()), escapeMarkdown(def), required, help)
+	}
+
+	b.WriteString(o.Footer)
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// docType returns the type of the variable as written by WriteMarkdown: the
+// type of WriteSpecs, or the syntax of maps.
+func (s *spec) docType() string {
+	if s.mapped {
+		return s.valueType()
+	}
+
+	return s.typeName()
+}
+
+// dotEnvSafe matches the values written as is to .env files.
+var dotEnvSafe = regexp.MustCompile(`^[A-Za-z0-9_.,:/@%+=-]*$`) // nolint:gochecknoglobals
+
+// quoteDotEnv returns value as written to a .env file: as is when it only
+// has safe characters, and otherwise double-quoted, escaping the characters
+// that parseEnvironmentFile unescapes.
+func quoteDotEnv(value string) string {
+	if dotEnvSafe.MatchString(value) {
+		return value
+	}
+
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`", "$", `\$`).Replace(value) + `"`
+}
+
+// escapeMarkdown escapes the characters that would break a table cell.
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+// WriteDotEnv writes a .env.example file listing the variables with their
+// help as comments and their defaults as values, quoted as needed for the
+// .env files read by the parser. Secret values are left empty, and the
+// assignments of templated defaults, which depend on other variables, are
+// commented out.
+func (p *Parser) WriteDotEnv(w io.Writer, opts ...DocOptions) error {
+	specs, o := p.docSpecs(opts)
+
+	var b strings.Builder
+
+	b.WriteString(o.Header)
+
+	for i, spec := range specs {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		comment := spec.help
+		if spec.required {
+			comment = strings.TrimSpace(comment + " (required)")
+		}
+
+		if comment != "" {
+			fmt.Fprintf(&b, "# %s\n", strings.Replace(comment, "\n", "\n# ", -1))
+		}
+
+		if spec.docs != "" {
+			fmt.Fprintf(&b, "# see %s\n", spec.docs)
+		}
+
+		value := ""
+		if !spec.secret && !spec.mask {
+			value = quoteDotEnv(spec.defaultVal)
+		}
+
+		if spec.defaultTemplate != nil && value != "" {
+			b.WriteString("# ")
+		}
+
+		fmt.Fprintf(&b, "%s=%s\n", spec.name, value)
+	}
+
+	b.WriteString(o.Footer)
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
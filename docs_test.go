@@ -0,0 +1,166 @@
+package env_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Alex616/go-env"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type DocsDB struct {
+	Host     string `env:"DB_HOST,required" help:"database host"`
+	Password string `env:"DB_PASSWORD,secret" default:"hunter2"`
+}
+
+type DocsEnvs struct {
+	Workers int    `env:"WORKERS,required" help:"number of workers | threads"`
+	Name    string `env:"NAME" default:"foo"`
+	DocsDB
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	expected := `| Variable | Type | Default | Required | Description |
+|----------|------|---------|----------|-------------|
+| ` + "`WORKERS`" + ` | int |  | yes | number of workers \| threads |
+| ` + "`NAME`" + ` | string | ` + "`foo`" + ` |  |  |
+| ` + "`DB_HOST`" + ` | string |  | yes | database host |
+| ` + "`DB_PASSWORD`" + ` | string | ` + "`***`" + ` |  |  |
+`
+
+	p, err := env.NewParser(env.Config{}, &DocsEnvs{})
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+
+	require.NoError(t, p.WriteMarkdown(&b))
+	assert.Equal(t, expected, b.String())
+}
+
+func TestWriteDotEnv(t *testing.T) {
+	expected := `# number of workers | threads (required)
+WORKERS=
+
+NAME=foo
+
+# database host (required)
+DB_HOST=
+
+DB_PASSWORD=
+`
+
+	p, err := env.NewParser(env.Config{}, &DocsEnvs{})
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+
+	require.NoError(t, p.WriteDotEnv(&b))
+	assert.Equal(t, expected, b.String())
+}
+
+func TestDocOptions(t *testing.T) {
+	p, err := env.NewParser(env.Config{}, &DocsEnvs{})
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+
+	require.NoError(t, p.WriteDotEnv(&b, env.DocOptions{Sort: true}))
+	assert.Equal(t, []string{"DB_HOST=", "DB_PASSWORD=", "NAME=foo", "WORKERS="}, assignments(b.String()))
+
+	b.Reset()
+	require.NoError(t, p.WriteDotEnv(&b, env.DocOptions{RequiredOnly: true}))
+	assert.Equal(t, []string{"WORKERS=", "DB_HOST="}, assignments(b.String()))
+
+	b.Reset()
+	require.NoError(t, p.WriteDotEnv(&b, env.DocOptions{Group: "DocsDB", Sort: true}))
+	assert.Equal(t, []string{"DB_HOST=", "DB_PASSWORD="}, assignments(b.String()))
+
+	b.Reset()
+	require.NoError(t, p.WriteSpecs(&b, env.DocOptions{Header: "<pre>\n", Footer: "</pre>\n", Group: "Missing"}))
+	assert.Equal(t, "<pre>\n{\n  \"schema\": 3,\n  \"variables\": []\n}\n</pre>\n", b.String())
+}
+
+// assignments returns the variable assignment lines of a .env file.
+func assignments(dotenv string) []string {
+	var lines []string
+
+	for _, line := range strings.Split(dotenv, "\n") {
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+func TestDocsTag(t *testing.T) {
+	var envs struct {
+		Workers int `env:"WORKERS" help:"number of workers" docs:"https://runbooks.example.com/workers"`
+	}
+
+	p, err := env.NewParser(env.Config{}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "Environments:\n"+
+		"  WORKERS int            number of workers [docs: https://runbooks.example.com/workers]\n", p.Help())
+
+	var b bytes.Buffer
+
+	require.NoError(t, p.WriteMarkdown(&b))
+	assert.Contains(t, b.String(),
+		"| `WORKERS` | int |  |  | number of workers [docs](https://runbooks.example.com/workers) |\n")
+
+	b.Reset()
+	require.NoError(t, p.WriteDotEnv(&b))
+	assert.Equal(t, "# number of workers\n# see https://runbooks.example.com/workers\nWORKERS=\n", b.String())
+
+	b.Reset()
+	require.NoError(t, p.WriteSpecs(&b))
+	assert.Contains(t, b.String(), `"docs": "https://runbooks.example.com/workers"`)
+}
+
+func TestWriteDotEnvRoundTrip(t *testing.T) {
+	var defaults struct {
+		Greeting string `default:"hello world"`
+		Channel  string `default:"#general"`
+		Quoted   string `default:"say \"hi\" \\o/ 'there'"`
+		Price    string `default:"$5 or ${PRICE}"`
+		Padded   string `default:"  padded  "`
+		Hosts    string `default:"a.example.com:80,b.example.com"`
+		Label    string `default:"{{ .Greeting }}!"`
+	}
+
+	p, err := env.NewParser(env.Config{}, &defaults)
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+	require.NoError(t, p.WriteDotEnv(&b))
+	assert.Contains(t, b.String(), "\n# label=\"{{ .Greeting }}!\"\n")
+
+	dir, err := ioutil.TempDir("", "dotenv")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ".env")
+	require.NoError(t, ioutil.WriteFile(path, b.Bytes(), 0o600))
+
+	var envs struct {
+		Greeting, Channel, Quoted, Price, Padded, Hosts, Label string
+	}
+
+	p, err = env.NewParser(env.Config{Sources: []env.Source{}, Files: []string{path}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, "hello world", envs.Greeting)
+	assert.Equal(t, "#general", envs.Channel)
+	assert.Equal(t, `say "hi" \o/ 'there'`, envs.Quoted)
+	assert.Equal(t, "$5 or ${PRICE}", envs.Price)
+	assert.Equal(t, "  padded  ", envs.Padded)
+	assert.Equal(t, "a.example.com:80,b.example.com", envs.Hosts)
+	assert.Empty(t, envs.Label)
+}
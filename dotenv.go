@@ -0,0 +1,99 @@
+package env
+
+import (
+	"io/ioutil"
+	"os"
+	"regexp"
+)
+
+// exportPrefix matches the export keyword starting the assignments of .env
+// files written to be sourced by shells.
+var exportPrefix = regexp.MustCompile(`(?m)^[ \t]*export[ \t]+`) // nolint:gochecknoglobals
+
+// dotenvSource is a Source reading a .env file, named "dotenv" for the
+// sources tag.
+type dotenvSource struct {
+	*systemdSource
+}
+
+// newDotenvSource returns a source reading the .env file at path, which may
+// not exist if optional is true.
+func newDotenvSource(path string, optional bool) dotenvSource {
+	return dotenvSource{&systemdSource{path: path, optional: optional}}
+}
+
+// SourceName returns "dotenv".
+func (s dotenvSource) SourceName() string {
+	return "dotenv"
+}
+
+// Load reads the file, in the syntax of SystemdEnvFileSource where the
+// assignments may also start with export.
+func (s dotenvSource) Load() error {
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil && !(s.optional && os.IsNotExist(err)) {
+		return err
+	}
+
+	values := parseEnvironmentFile(exportPrefix.ReplaceAllString(string(data), ""))
+
+	s.mu.Lock()
+	s.values = values
+	s.mu.Unlock()
+
+	return nil
+}
+
+// LoadDotenv reads the .env file at path and uses it as a fallback for the
+// variables that none of the sources of the parser, Config.Files included,
+// provides. Like those, the file is read again before every Parse. The
+// files loaded first take precedence.
+func (p *Parser) LoadDotenv(path string) error {
+	src := newDotenvSource(path, false)
+	if err := src.Load(); err != nil {
+		return err
+	}
+
+	p.processMu.Lock()
+	p.files = append(p.files, src)
+	p.processMu.Unlock()
+
+	return nil
+}
+
+// DotenvFiles returns the .env files of a profile, such as production, in
+// the order of precedence of the usual layering, for Config.Files:
+// .env.production.local, .env.local, .env.production and .env. The local
+// files hold the overrides of a machine, and are not committed. Without a
+// profile, it returns .env.local and .env.
+func DotenvFiles(profile string) []string {
+	if profile == "" {
+		return []string{".env.local", ".env"}
+	}
+
+	return []string{".env." + profile + ".local", ".env.local", ".env." + profile, ".env"}
+}
+
+// DotenvOrigins returns the path of the .env file providing each of the
+// variables set by the files of Config.Files and LoadDotenv, as of the last
+// time they were read, the first file setting a variable taking precedence.
+// Variables that other sources set as well are included, even though the
+// files do not provide their values.
+func (p *Parser) DotenvOrigins() map[string]string {
+	p.processMu.Lock()
+	defer p.processMu.Unlock()
+
+	origins := make(map[string]string)
+
+	for _, src := range p.files {
+		file := src.(dotenvSource)
+
+		for _, name := range file.Names() {
+			if _, exists := origins[name]; !exists {
+				origins[name] = file.path
+			}
+		}
+	}
+
+	return origins
+}
@@ -0,0 +1,110 @@
+package env
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dotenv")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	local := filepath.Join(dir, ".env.local")
+	require.NoError(t, ioutil.WriteFile(local, []byte("NAME=local\n"), 0o600))
+
+	shared := filepath.Join(dir, ".env")
+	require.NoError(t, ioutil.WriteFile(shared, []byte("export WORKERS=4\nNAME=shared\nHOST=file\n"), 0o600))
+
+	var envs struct {
+		Workers int    `env:"WORKERS"`
+		Name    string `env:"NAME"`
+		Host    string `env:"HOST"`
+	}
+
+	p, err := NewParser(Config{
+		Sources: []Source{MapSource{"HOST": "env"}},
+		Files:   []string{local, filepath.Join(dir, "missing.env"), shared},
+	}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, 4, envs.Workers)
+	assert.Equal(t, "local", envs.Name)
+	assert.Equal(t, "env", envs.Host)
+
+	_, source, found := p.Lookup("WORKERS")
+	assert.True(t, found)
+	assert.Equal(t, "dotenv", source)
+}
+
+func TestLoadDotenv(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dotenv")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ".env")
+	require.NoError(t, ioutil.WriteFile(path, []byte("WORKERS=4\nNAME=file\n"), 0o600))
+
+	var envs struct {
+		Workers int    `env:"WORKERS"`
+		Name    string `env:"NAME"`
+	}
+
+	p, err := NewParser(Config{Sources: []Source{MapSource{"NAME": "env"}}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.LoadDotenv(path))
+	require.NoError(t, p.Parse())
+	assert.Equal(t, 4, envs.Workers)
+	assert.Equal(t, "env", envs.Name)
+
+	assert.Error(t, p.LoadDotenv(filepath.Join(dir, "missing.env")))
+}
+
+func TestDotenvOrigins(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dotenv")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	files := DotenvFiles("production")
+	assert.Equal(t, []string{".env.production.local", ".env.local", ".env.production", ".env"}, files)
+
+	contents := map[string]string{
+		".env.local":      "NAME=local\n",
+		".env.production": "NAME=production\nHOST=prod\n",
+		".env":            "NAME=shared\nHOST=shared\nWORKERS=2\n",
+	}
+
+	for i, file := range files {
+		files[i] = filepath.Join(dir, file)
+
+		if data, exists := contents[file]; exists {
+			require.NoError(t, ioutil.WriteFile(files[i], []byte(data), 0o600))
+		}
+	}
+
+	var envs struct {
+		Name    string `env:"NAME"`
+		Host    string `env:"HOST"`
+		Workers int    `env:"WORKERS"`
+	}
+
+	p, err := NewParser(Config{Sources: []Source{MapSource{}}, Files: files}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, "local", envs.Name)
+	assert.Equal(t, "prod", envs.Host)
+	assert.Equal(t, 2, envs.Workers)
+	assert.Equal(t, map[string]string{
+		"NAME":    files[1],
+		"HOST":    files[2],
+		"WORKERS": files[3],
+	}, p.DotenvOrigins())
+}
@@ -0,0 +1,59 @@
+package env
+
+import (
+	"strings"
+	"time"
+)
+
+// Duration is a time.Duration remembering the text it was parsed from, so
+// that it is displayed the way it was written, such as "1h30m" rather than
+// "1h30m0s". Fields of type time.Duration are parsed the same way, but are
+// displayed in their shortest form.
+type Duration struct {
+	time.Duration
+	text string
+}
+
+// UnmarshalText parses a duration as accepted by time.ParseDuration.
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	d.Duration, d.text = v, string(text)
+
+	return nil
+}
+
+// MarshalText returns the text the duration was parsed from, or its
+// shortest form if it was not parsed.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// String returns the text the duration was parsed from, or its shortest
+// form if it was not parsed.
+func (d Duration) String() string {
+	if d.text != "" {
+		return d.text
+	}
+
+	return formatDuration(d.Duration)
+}
+
+// formatDuration formats d without the zero minutes and seconds that
+// time.Duration.String appends after larger units.
+func formatDuration(d time.Duration) string {
+	s := d.String()
+
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+
+	return s
+}
@@ -0,0 +1,67 @@
+package env
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvDuration(t *testing.T) {
+	var envs struct {
+		Timeout Duration
+		Retries []Duration
+	}
+
+	p, err := pparse(envsMap{"timeout": "90m", "retries": "1s,1m30s"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, envs.Timeout.Duration)
+	assert.Equal(t, "90m", envs.Timeout.String())
+	assert.Equal(t, 90*time.Second, envs.Retries[1].Duration)
+	assert.Equal(t, map[string]string{"timeout": "90m", "retries": "1s,1m30s"}, p.Snapshot())
+
+	err = parse(envsMap{"timeout": "soon"}, &envs)
+	require.Error(t, err)
+	assert.Equal(t, "expected a value of type duration", err.(Errors)[0].Hint)
+}
+
+func TestEnvDurationDefault(t *testing.T) {
+	envs := struct {
+		Timeout Duration
+	}{
+		Timeout: Duration{Duration: time.Hour},
+	}
+
+	p, err := NewParser(Config{}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "Environments:\n  timeout duration [default: 1h]\n", p.Help())
+}
+
+func TestDurationHelp(t *testing.T) {
+	envs := struct {
+		Timeout  time.Duration
+		Interval time.Duration
+		Delay    time.Duration
+	}{
+		Timeout:  90 * time.Minute,
+		Interval: 2 * time.Minute,
+		Delay:    1500 * time.Millisecond,
+	}
+
+	p, err := NewParser(Config{}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "Environments:\n"+
+		"  timeout duration [default: 1h30m]\n"+
+		"  interval duration [default: 2m]\n"+
+		"  delay duration [default: 1.5s]\n", p.Help())
+	assert.Equal(t, "1h30m", p.Snapshot()["timeout"])
+}
+
+func TestFormatDuration(t *testing.T) {
+	assert.Equal(t, "0s", formatDuration(0))
+	assert.Equal(t, "1h", formatDuration(time.Hour))
+	assert.Equal(t, "1h0m5s", formatDuration(time.Hour+5*time.Second))
+	assert.Equal(t, "-2m", formatDuration(-2*time.Minute))
+	assert.Equal(t, "10ms", formatDuration(10*time.Millisecond))
+}
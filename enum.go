@@ -0,0 +1,46 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Enumerated is implemented by types whose values are restricted to a list,
+// such as a string type naming environments. The value of a variable of
+// such a type must be one of Values, which are listed in the help and in
+// the errors.
+type Enumerated interface {
+	// Values returns the allowed values, in their environment form.
+	Values() []string
+}
+
+var enumeratedType = reflect.TypeOf([]Enumerated{}).Elem() // nolint:gochecknoglobals
+
+// enumValues returns the allowed values of t, or of its elements for
+// slices, if it implements Enumerated.
+func enumValues(t reflect.Type) []string {
+	if t.Kind() == reflect.Slice && !t.Implements(enumeratedType) && !reflect.PtrTo(t).Implements(enumeratedType) {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if !reflect.PtrTo(t).Implements(enumeratedType) {
+		return nil
+	}
+
+	return reflect.New(t).Interface().(Enumerated).Values()
+}
+
+// checkValue returns an error if the option only allows some values and
+// value is not one of them.
+func (s *spec) checkValue(value string) error {
+	if s.values == nil || containsString(s.values, value) {
+		return nil
+	}
+
+	return fmt.Errorf("%q %w: %s", value, ErrorValueNotAllowed, strings.Join(s.values, ", "))
+}
@@ -0,0 +1,56 @@
+package env
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stage string
+
+func (stage) Values() []string {
+	return []string{"dev", "staging", "prod"}
+}
+
+func TestEnumerated(t *testing.T) {
+	var envs struct {
+		Stage  stage
+		Stages []stage
+		Ptr    *stage
+	}
+
+	err := parse(envsMap{"stage": "prod", "stages": "dev,staging", "ptr": "dev"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, stage("prod"), envs.Stage)
+	assert.Equal(t, []stage{"dev", "staging"}, envs.Stages)
+	assert.Equal(t, stage("dev"), *envs.Ptr)
+}
+
+func TestEnumeratedNotAllowed(t *testing.T) {
+	var envs struct {
+		Stage  stage
+		Stages []stage
+	}
+
+	err := parse(envsMap{"stage": "qa", "stages": "dev,test"}, &envs)
+
+	var errs Errors
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs, 2)
+	assert.True(t, errors.Is(err, ErrorValueNotAllowed))
+	assert.Equal(t, `error processing environment variable stage: "qa" is not one of: dev, staging, prod`, errs[0].Err.Error())
+	assert.Equal(t, "expected one of: dev, staging, prod", errs[0].Hint)
+	assert.Contains(t, errs[1].Err.Error(), `"test" is not one of`)
+}
+
+func TestEnumeratedHelp(t *testing.T) {
+	var envs struct {
+		Stage stage `default:"dev"`
+	}
+
+	p, err := NewParser(Config{}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "Environments:\n  stage string [one of: dev, staging, prod, default: dev]\n", p.Help())
+}
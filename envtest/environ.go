@@ -0,0 +1,136 @@
+package envtest
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// environMu serializes the tests changing the process environment, which
+// is shared by the tests running in parallel.
+var environMu sync.Mutex // nolint:gochecknoglobals
+
+// With sets the variables of the process environment for the duration of
+// fn, and then restores the environment as it was, even if fn fails the
+// test or panics. Tests calling With in parallel wait for each other, so
+// that each sees only its own variables, but With must not be nested.
+func With(t testing.TB, vars map[string]string, fn func()) {
+	t.Helper()
+
+	environMu.Lock()
+	defer environMu.Unlock()
+
+	saved := os.Environ()
+	defer restore(t, saved)
+
+	for name, value := range vars {
+		if err := os.Setenv(name, value); err != nil {
+			t.Fatalf("envtest: %v", err)
+		}
+	}
+
+	fn()
+}
+
+// restore sets the process environment to the given variables only.
+func restore(t testing.TB, environ []string) {
+	t.Helper()
+
+	os.Clearenv()
+
+	for _, kv := range environ {
+		// on Windows, the names of some hidden variables start with =
+		i := strings.IndexByte(kv[1:], '=') + 1
+		if i == 0 {
+			continue
+		}
+
+		if err := os.Setenv(kv[:i], kv[i+1:]); err != nil {
+			t.Errorf("envtest: restoring the environment: %v", err)
+		}
+	}
+}
+
+// Source is a fake env.Source whose variables can be changed between
+// parses, such as to test Reload, and which records the names looked up.
+// It is safe for concurrent use.
+type Source struct {
+	mu       sync.Mutex
+	vars     map[string]string
+	lookedUp map[string]bool
+}
+
+// NewSource returns a Source with a copy of the given variables.
+func NewSource(vars map[string]string) *Source {
+	s := &Source{vars: make(map[string]string, len(vars)), lookedUp: make(map[string]bool)}
+	for name, value := range vars {
+		s.vars[name] = value
+	}
+
+	return s
+}
+
+// SourceName returns "envtest".
+func (s *Source) SourceName() string {
+	return "envtest"
+}
+
+// Lookup returns the value of the variable.
+func (s *Source) Lookup(name string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lookedUp[name] = true
+	value, found := s.vars[name]
+
+	return value, found
+}
+
+// Names returns the names of the variables, sorted.
+func (s *Source) Names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.vars))
+	for name := range s.vars {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Set sets the variable.
+func (s *Source) Set(name, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.vars[name] = value
+}
+
+// Unset removes the variable.
+func (s *Source) Unset(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.vars, name)
+}
+
+// LookedUp returns the names looked up so far, whether they were set or
+// not, sorted.
+func (s *Source) LookedUp() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.lookedUp))
+	for name := range s.lookedUp {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,92 @@
+package envtest
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	env "github.com/Alex616/go-env"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWith(t *testing.T) {
+	require.NoError(t, os.Setenv("ENVTEST_KEPT", "kept"))
+	require.NoError(t, os.Setenv("ENVTEST_CHANGED", "before"))
+
+	defer func() {
+		_ = os.Unsetenv("ENVTEST_KEPT")
+		_ = os.Unsetenv("ENVTEST_CHANGED")
+	}()
+
+	With(t, map[string]string{"ENVTEST_CHANGED": "during", "ENVTEST_NEW": "new"}, func() {
+		assert.Equal(t, "kept", os.Getenv("ENVTEST_KEPT"))
+		assert.Equal(t, "during", os.Getenv("ENVTEST_CHANGED"))
+		assert.Equal(t, "new", os.Getenv("ENVTEST_NEW"))
+		require.NoError(t, os.Setenv("ENVTEST_ADDED", "added"))
+	})
+
+	assert.Equal(t, "kept", os.Getenv("ENVTEST_KEPT"))
+	assert.Equal(t, "before", os.Getenv("ENVTEST_CHANGED"))
+
+	_, found := os.LookupEnv("ENVTEST_NEW")
+	assert.False(t, found)
+
+	_, found = os.LookupEnv("ENVTEST_ADDED")
+	assert.False(t, found)
+}
+
+func TestWithPanic(t *testing.T) {
+	assert.Panics(t, func() {
+		With(t, map[string]string{"ENVTEST_PANIC": "1"}, func() {
+			panic("boom")
+		})
+	})
+
+	_, found := os.LookupEnv("ENVTEST_PANIC")
+	assert.False(t, found)
+}
+
+func TestWithParallel(t *testing.T) {
+	var wg sync.WaitGroup
+
+	for _, value := range []string{"a", "b", "c", "d"} {
+		wg.Add(1)
+
+		go func(value string) {
+			defer wg.Done()
+
+			With(t, map[string]string{"ENVTEST_PARALLEL": value}, func() {
+				var cfg struct {
+					Value string `env:"ENVTEST_PARALLEL"`
+				}
+
+				_, err := env.MustParse(&cfg)
+				assert.NoError(t, err)
+				assert.Equal(t, value, cfg.Value)
+			})
+		}(value)
+	}
+
+	wg.Wait()
+}
+
+func TestSource(t *testing.T) {
+	src := NewSource(map[string]string{"WORKERS": "8"})
+
+	var cfg config
+
+	p, err := env.NewParser(env.Config{Sources: []env.Source{src}}, &cfg)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, 8, cfg.Workers)
+	assert.Equal(t, []string{"TOKEN", "WORKERS"}, src.LookedUp())
+
+	src.Set("TOKEN", "t")
+	src.Unset("WORKERS")
+	assert.Equal(t, []string{"TOKEN"}, src.Names())
+
+	require.NoError(t, p.Reload())
+	assert.Equal(t, 4, cfg.Workers)
+	assert.Equal(t, "t", cfg.Token)
+}
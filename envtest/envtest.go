@@ -0,0 +1,127 @@
+// Package envtest provides helpers to test the configuration of programs
+// using env.
+package envtest
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	env "github.com/Alex616/go-env"
+)
+
+// update makes Golden write the golden files instead of comparing them.
+var update = flag.Bool("envtest.update", false, "update the golden files of envtest.Golden") // nolint:gochecknoglobals
+
+// Golden compares the variables of the parser, as written by WriteSpecs,
+// and their current values, as returned by Snapshot, with the golden file
+// testdata/<test name>.golden, and fails the test if they differ, so that
+// renamed variables and changed defaults show up in code review. Run the
+// tests with -envtest.update to write the golden file after an intended
+// change.
+func Golden(t testing.TB, p *env.Parser) {
+	t.Helper()
+
+	got, err := golden(p)
+	if err != nil {
+		t.Fatalf("envtest: %v", err)
+	}
+
+	file := filepath.Join("testdata", strings.ReplaceAll(t.Name(), "/", "_")+".golden")
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatalf("envtest: %v", err)
+		}
+
+		if err := ioutil.WriteFile(file, got, 0o644); err != nil { // nolint:gosec
+			t.Fatalf("envtest: %v", err)
+		}
+
+		return
+	}
+
+	want, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("envtest: %v, run the tests with -envtest.update to create it", err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("envtest: the configuration differs from %s, "+
+			"run the tests with -envtest.update to accept the changes:\n%s", file, diff(string(want), string(got)))
+	}
+}
+
+// golden returns the contents of the golden file of the parser.
+func golden(p *env.Parser) ([]byte, error) {
+	var b bytes.Buffer
+
+	b.WriteString("# variables\n")
+
+	if err := p.WriteSpecs(&b); err != nil {
+		return nil, err
+	}
+
+	b.WriteString("# values\n")
+
+	snapshot := p.Snapshot()
+
+	names := make([]string, 0, len(snapshot))
+	for name := range snapshot {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s=%s\n", name, snapshot[name])
+	}
+
+	return b.Bytes(), nil
+}
+
+// diff returns the lines of want missing from got, prefixed with "-", and
+// the lines of got missing from want, prefixed with "+".
+func diff(want, got string) string {
+	wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+
+	var b strings.Builder
+
+	for _, line := range missing(wantLines, gotLines) {
+		b.WriteString("-" + line + "\n")
+	}
+
+	for _, line := range missing(gotLines, wantLines) {
+		b.WriteString("+" + line + "\n")
+	}
+
+	return b.String()
+}
+
+// missing returns the lines of a that are not in b, in order.
+func missing(a, b []string) []string {
+	count := make(map[string]int, len(b))
+	for _, line := range b {
+		count[line]++
+	}
+
+	var lines []string
+
+	for _, line := range a {
+		if count[line] > 0 {
+			count[line]--
+
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
@@ -0,0 +1,95 @@
+package envtest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	env "github.com/Alex616/go-env"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type config struct {
+	Workers int    `env:"WORKERS" default:"4" help:"number of workers"`
+	Token   string `env:"TOKEN,secret"`
+}
+
+// recorder records the failures of a test.
+type recorder struct {
+	testing.TB
+	name   string
+	failed string
+}
+
+func (r *recorder) Name() string { return r.name }
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failed = fmt.Sprintf(format, args...)
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.failed = fmt.Sprintf(format, args...)
+}
+
+func newParser(t *testing.T, src env.MapSource) *env.Parser {
+	var cfg config
+
+	p, err := env.NewParser(env.Config{Sources: []env.Source{src}}, &cfg)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+
+	return p
+}
+
+func TestGolden(t *testing.T) {
+	Golden(t, newParser(t, env.MapSource{"TOKEN": "secret"}))
+}
+
+func TestGoldenChanged(t *testing.T) {
+	r := &recorder{TB: t, name: "TestGolden"}
+
+	Golden(r, newParser(t, env.MapSource{"WORKERS": "8", "TOKEN": "secret"}))
+	assert.Contains(t, r.failed, "the configuration differs from testdata/TestGolden.golden")
+	assert.Contains(t, r.failed, "-WORKERS=4\n+WORKERS=8\n")
+}
+
+func TestGoldenMissing(t *testing.T) {
+	r := &recorder{TB: t, name: "TestMissing/sub"}
+
+	Golden(r, newParser(t, env.MapSource{}))
+	assert.Contains(t, r.failed, "testdata/TestMissing_sub.golden")
+	assert.Contains(t, r.failed, "-envtest.update")
+}
+
+func TestGoldenUpdate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "envtest")
+	require.NoError(t, err)
+
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+
+	defer func() { _ = os.Chdir(wd) }()
+
+	*update = true
+
+	defer func() { *update = false }()
+
+	r := &recorder{TB: t, name: "TestUpdate"}
+
+	Golden(r, newParser(t, env.MapSource{}))
+	assert.Empty(t, r.failed)
+
+	data, err := ioutil.ReadFile("testdata/TestUpdate.golden")
+	require.NoError(t, err)
+
+	want, err := golden(newParser(t, env.MapSource{}))
+	require.NoError(t, err)
+	assert.Equal(t, want, data)
+}
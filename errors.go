@@ -9,14 +9,83 @@ var (
 	ErrorFieldIsRequired = errors.New("field is required")
 	// ErrorNotPointers source is not pointers.
 	ErrorNotPointers = errors.New("must be pointers")
+	// ErrorNilPointer source is a nil pointer.
+	ErrorNilPointer = errors.New("must be non-nil pointers")
 	// ErrorNotStruct source is not structs.
 	ErrorNotStruct = errors.New("must be structs")
 	// ErrorRequiredWithDefault error when required used with default value.
 	ErrorRequiredWithDefault = errors.New("'required' cannot be used when a default value is specified")
-	// ErrorUnrecognizedTag unrecognized tag.
+	// ErrorUnrecognizedTag unrecognized tag key.
 	ErrorUnrecognizedTag = errors.New("unrecognized tag")
+	// ErrorMalformedTag tag item with a missing or unexpected value.
+	ErrorMalformedTag = errors.New("malformed tag")
+	// ErrorConflictingTag tag items that cannot be used together.
+	ErrorConflictingTag = errors.New("conflicting tag options")
 	// ErrorFieldsAreNotSupported fields are not supported.
 	ErrorFieldsAreNotSupported = errors.New("fields are not supported")
-	// ErrorDefaultValueForSlice default value for slice are not supported.
-	ErrorDefaultValueForSlice = errors.New("default values are not supported for slice fields")
+	// ErrorDefaultValueForSlice default value for slices of structs are not supported.
+	ErrorDefaultValueForSlice = errors.New("default values are not supported for slices of structs")
+	// ErrorInvertNotBool invert used on a field that is not a bool.
+	ErrorInvertNotBool = errors.New("'invert' can only be used on bool fields")
+	// ErrorCountNotInteger count or levels used on a field that is not an integer.
+	ErrorCountNotInteger = errors.New("'count' and 'levels' can only be used on integer fields")
+	// ErrorInvalidLevels malformed levels tag.
+	ErrorInvalidLevels = errors.New(`levels must be a list of names or name=value pairs`)
+	// ErrorUnknownEncoding encoding tag naming an unsupported encoding.
+	ErrorUnknownEncoding = errors.New("unknown encoding")
+	// ErrorExampleUnnamedType example requested for an anonymous struct.
+	ErrorExampleUnnamedType = errors.New("examples can only be generated for named struct types")
+	// ErrorValueTooLong value longer than Config.MaxValueLength.
+	ErrorValueTooLong = errors.New("value too long")
+	// ErrorTooManyElements list with more elements than Config.MaxElements
+	// or its maxitems tag.
+	ErrorTooManyElements = errors.New("too many elements")
+	// ErrorCatchAllNotMap catchall used on a field that is not a map of strings.
+	ErrorCatchAllNotMap = errors.New("'catchall' can only be used on map[string]string or map[string]interface{} fields")
+	// ErrorMalformedPair item of a KVList without an equal sign.
+	ErrorMalformedPair = errors.New("expected a key=value pair")
+	// ErrorValueNotAllowed value not among the Values of an Enumerated type.
+	ErrorValueNotAllowed = errors.New("is not one of")
+	// ErrorUnknownSource sources tag naming a source the parser does not have.
+	ErrorUnknownSource = errors.New("unknown source")
+	// ErrorConflictingSources variable set to different values by several sources.
+	ErrorConflictingSources = errors.New("set to different values by several sources")
+	// ErrorTooFewElements list with fewer elements than its minitems tag.
+	ErrorTooFewElements = errors.New("too few elements")
+	// ErrorItemsNotSlice minitems or maxitems used on a field that is not a slice.
+	ErrorItemsNotSlice = errors.New("'minitems' and 'maxitems' can only be used on slice fields")
+	// ErrorEmptyValue variable tagged notEmpty set to an empty value.
+	ErrorEmptyValue = errors.New("empty value")
+	// ErrorNotEmptyPointer notEmpty used on a pointer field.
+	ErrorNotEmptyPointer = errors.New("'notEmpty' cannot be used on pointer fields")
+	// ErrorPathTagNotPath path tag used on a field that is not a Path.
+	ErrorPathTagNotPath = errors.New("the path tag can only be used on Path fields")
+	// ErrorNotDirectory path tagged mustDir that is not a directory.
+	ErrorNotDirectory = errors.New("not a directory")
+	// ErrorNotPermissions os.FileMode value with bits other than the permissions.
+	ErrorNotPermissions = errors.New("expected permissions between 0000 and 0777")
+	// ErrorOnMissingNotStruct onMissing:"disable" used on a field that is not a struct.
+	ErrorOnMissingNotStruct = errors.New(`onMissing:"disable" can only be used on struct or pointer to struct fields`)
+	// ErrorEnabledNotBool onMissing:"enabled" used on a field that is not a bool.
+	ErrorEnabledNotBool = errors.New(`onMissing:"enabled" can only be used on bool fields`)
+	// ErrorEnabledOutsideGroup onMissing:"enabled" used outside of an optional struct.
+	ErrorEnabledOutsideGroup = errors.New(`onMissing:"enabled" must be used once inside a struct tagged onMissing:"disable"`)
+	// ErrorCSVNotSlice csv tag used on a field that is not a slice.
+	ErrorCSVNotSlice = errors.New("the csv tag can only be used on slice fields")
+	// ErrorSeparatorNotMap kvSep or itemSep tag used on a field that is not a map.
+	ErrorSeparatorNotMap = errors.New("the kvSep and itemSep tags can only be used on map fields")
+	// ErrorSharedName variable bound to fields of several destination structs.
+	ErrorSharedName = errors.New("variable bound to fields of several destination structs")
+	// ErrorUnknownField default template referring to a field the struct does not have.
+	ErrorUnknownField = errors.New("unknown field")
+	// ErrorDefaultCycle default templates referring to each other.
+	ErrorDefaultCycle = errors.New("default values refer to each other")
+	// ErrorLayoutNotTime layout tag used on a field that is not a time.Time.
+	ErrorLayoutNotTime = errors.New("the layout tag can only be used on time.Time fields")
+	// ErrorUnknownVariable name that is not bound to any field.
+	ErrorUnknownVariable = errors.New("unknown variable")
+	// ErrorInvalidInterval interval of Watch that is not positive.
+	ErrorInvalidInterval = errors.New("the interval must be positive")
+	// ErrorTypeMismatch destination of another type than the field of the variable.
+	ErrorTypeMismatch = errors.New("destination type does not match the field")
 )
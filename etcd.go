@@ -0,0 +1,218 @@
+package env
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// etcdRetryInterval is the delay before reopening a failed watch stream.
+const etcdRetryInterval = time.Second
+
+// EtcdSource is a Source reading variables from the keys under a prefix of
+// an etcd v3 cluster, through its JSON gateway. Keys are matched like those
+// of ConsulSource, so that the key /myapp/db/host provides DB_HOST when the
+// prefix is /myapp/.
+type EtcdSource struct {
+	// Endpoint is the base URL of an etcd member, such as
+	// "http://127.0.0.1:2379".
+	Endpoint string
+	// Prefix is the key prefix under which the variables are read.
+	Prefix string
+	// Token is the authentication token sent with the requests, if any.
+	Token string
+	// Client is the HTTP client, http.DefaultClient if nil.
+	Client *http.Client
+	// Timeout bounds the request of Load, 10 seconds if zero.
+	Timeout time.Duration
+
+	mu       sync.RWMutex
+	values   map[string]string
+	revision int64
+}
+
+// etcdKeyValue is a key-value pair of the etcd JSON gateway, whose bytes
+// fields are base64 encoded.
+type etcdKeyValue struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// etcdHeader is the response header of the etcd JSON gateway, whose
+// integers are encoded as strings.
+type etcdHeader struct {
+	Revision int64 `json:"revision,string"`
+}
+
+// Load reads the keys under the prefix, failing after Timeout.
+func (s *EtcdSource) Load() error {
+	var resp struct {
+		Header etcdHeader     `json:"header"`
+		Kvs    []etcdKeyValue `json:"kvs"`
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), loadTimeout(s.Timeout))
+	defer cancel()
+
+	err := s.post(ctx, "/v3/kv/range", map[string]interface{}{
+		"key":       []byte(s.Prefix),
+		"range_end": prefixRangeEnd(s.Prefix),
+	}, func(body *json.Decoder) error {
+		return body.Decode(&resp)
+	})
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string]string, len(resp.Kvs))
+
+	for _, kv := range resp.Kvs {
+		if key, ok := prefixedKey(string(kv.Key), s.Prefix); ok {
+			values[key] = string(kv.Value)
+		}
+	}
+
+	s.mu.Lock()
+	s.values = values
+	s.revision = resp.Header.Revision
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Lookup returns the value of the variable from the last read keys.
+func (s *EtcdSource) Lookup(name string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	value, found := s.values[normalizeKey(name)]
+
+	return value, found
+}
+
+// Watch watches the prefix until ctx is done, and reloads p whenever a key
+// changes, so that the OnChange callbacks of p are invoked. Failed watches
+// and reloads are reported to the OnError callbacks of p, and the watch is
+// resumed from the last read revision. It returns the context error.
+func (s *EtcdSource) Watch(ctx context.Context, p *Parser) error {
+	for {
+		err := s.watch(ctx, p)
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		p.reportError(err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(etcdRetryInterval):
+		}
+	}
+}
+
+// watch opens a watch stream on the prefix, starting after the last read
+// revision, and reloads p for every batch of events until the stream ends.
+func (s *EtcdSource) watch(ctx context.Context, p *Parser) error {
+	s.mu.RLock()
+	revision := s.revision
+	s.mu.RUnlock()
+
+	create := map[string]interface{}{
+		"key":       []byte(s.Prefix),
+		"range_end": prefixRangeEnd(s.Prefix),
+	}
+
+	if revision > 0 {
+		create["start_revision"] = fmt.Sprint(revision + 1)
+	}
+
+	return s.post(ctx, "/v3/watch", map[string]interface{}{"create_request": create}, func(body *json.Decoder) error {
+		for {
+			var msg struct {
+				Result struct {
+					Events []json.RawMessage `json:"events"`
+				} `json:"result"`
+				Error *struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+
+			if err := body.Decode(&msg); err != nil {
+				return fmt.Errorf("error watching etcd prefix %q: %w", s.Prefix, err)
+			}
+
+			if msg.Error != nil {
+				return fmt.Errorf("error watching etcd prefix %q: %s", s.Prefix, msg.Error.Message) // nolint:goerr113
+			}
+
+			if len(msg.Result.Events) == 0 {
+				continue
+			}
+
+			if err := p.Reload(); err != nil {
+				p.reportError(err)
+			}
+		}
+	})
+}
+
+// post sends a JSON request to the etcd gateway and passes the response
+// body to read.
+func (s *EtcdSource) post(ctx context.Context, path string, request interface{}, read func(*json.Decoder) error) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(s.Endpoint, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if s.Token != "" {
+		req.Header.Set("Authorization", s.Token)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error reading etcd prefix %q: %s", s.Prefix, resp.Status) // nolint:goerr113
+	}
+
+	return read(json.NewDecoder(resp.Body))
+}
+
+// prefixRangeEnd returns the end of the etcd key range covering every key
+// starting with prefix.
+func prefixRangeEnd(prefix string) []byte {
+	end := []byte(prefix)
+
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+
+			return end[:i+1]
+		}
+	}
+
+	// every key
+	return []byte{0}
+}
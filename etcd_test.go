@@ -0,0 +1,210 @@
+package env
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEtcd serves the keys of a KV store the way the etcd v3 JSON gateway
+// does, including watch streams.
+type fakeEtcd struct {
+	mu       sync.Mutex
+	revision int64
+	kv       map[string]string
+	changed  chan struct{}
+}
+
+func newFakeEtcd(kv map[string]string) *fakeEtcd {
+	return &fakeEtcd{revision: 1, kv: kv, changed: make(chan struct{})}
+}
+
+func (e *fakeEtcd) put(key, value string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.kv[key] = value
+	e.revision++
+	close(e.changed)
+	e.changed = make(chan struct{})
+}
+
+func (e *fakeEtcd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "token" {
+		w.WriteHeader(http.StatusUnauthorized)
+
+		return
+	}
+
+	switch r.URL.Path {
+	case "/v3/kv/range":
+		var req struct {
+			Key      []byte `json:"key"`
+			RangeEnd []byte `json:"range_end"`
+		}
+
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		var kvs []etcdKeyValue
+
+		for k, v := range e.kv {
+			if k >= string(req.Key) && k < string(req.RangeEnd) {
+				kvs = append(kvs, etcdKeyValue{Key: []byte(k), Value: []byte(v)})
+			}
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"header": map[string]string{"revision": strconv.FormatInt(e.revision, 10)},
+			"kvs":    kvs,
+		})
+	case "/v3/watch":
+		var req struct {
+			CreateRequest struct {
+				StartRevision int64 `json:"start_revision,string"`
+			} `json:"create_request"`
+		}
+
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		_, _ = w.Write([]byte(`{"result":{"created":true}}` + "\n"))
+		w.(http.Flusher).Flush()
+
+		next := req.CreateRequest.StartRevision
+
+		for {
+			e.mu.Lock()
+			revision, changed := e.revision, e.changed
+			e.mu.Unlock()
+
+			if next <= revision {
+				// report the changes since the start revision
+				_, _ = w.Write([]byte(`{"result":{"events":[{"kv":{}}]}}` + "\n"))
+				w.(http.Flusher).Flush()
+
+				next = revision + 1
+			}
+
+			select {
+			case <-changed:
+			case <-r.Context().Done():
+				return
+			}
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestEtcdSource(t *testing.T) {
+	var envs struct {
+		Workers int
+		DBHost  string `env:"DB_HOST"`
+		Name    string `default:"foo"`
+	}
+
+	server := httptest.NewServer(newFakeEtcd(map[string]string{
+		"/myapp/workers": "4",
+		"/myapp/db/host": "localhost",
+		"/other/name":    "bar",
+	}))
+	defer server.Close()
+
+	src := &EtcdSource{Endpoint: server.URL, Prefix: "/myapp/", Token: "token"}
+
+	p, err := NewParser(Config{Sources: []Source{src}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, 4, envs.Workers)
+	assert.Equal(t, "localhost", envs.DBHost)
+	assert.Equal(t, "foo", envs.Name)
+}
+
+func TestEtcdSourceErrors(t *testing.T) {
+	var envs struct {
+		Workers int
+	}
+
+	server := httptest.NewServer(newFakeEtcd(map[string]string{}))
+	defer server.Close()
+
+	p, err := NewParser(Config{Sources: []Source{&EtcdSource{Endpoint: server.URL, Prefix: "/myapp/"}}}, &envs)
+	require.NoError(t, err)
+
+	err = p.Parse()
+	require.Error(t, err)
+	assert.True(t, strings.HasSuffix(err.Error(), "401 Unauthorized"))
+}
+
+func TestEtcdSourceWatch(t *testing.T) {
+	var envs struct {
+		Workers int
+	}
+
+	etcd := newFakeEtcd(map[string]string{"/myapp/workers": "4"})
+
+	server := httptest.NewServer(etcd)
+	defer server.Close()
+
+	src := &EtcdSource{Endpoint: server.URL, Prefix: "/myapp/", Token: "token"}
+
+	p, err := NewParser(Config{Sources: []Source{src}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changed := make(chan interface{}, 1)
+
+	p.OnChange(func(field string, old, new interface{}) {
+		changed <- new
+		cancel()
+	})
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- src.Watch(ctx, p)
+	}()
+
+	etcd.put("/myapp/workers", "8")
+
+	assert.Equal(t, 8, <-changed)
+	assert.Equal(t, context.Canceled, <-done)
+	assert.Equal(t, 8, envs.Workers)
+}
+
+func TestEtcdSourceTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the cancellation is only noticed once the body is read
+		_, _ = ioutil.ReadAll(r.Body)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	src := &EtcdSource{Endpoint: server.URL, Prefix: "/myapp/", Timeout: 10 * time.Millisecond}
+
+	err := src.Load()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestPrefixRangeEnd(t *testing.T) {
+	assert.Equal(t, []byte("/myapp0"), prefixRangeEnd("/myapp/"))
+	assert.Equal(t, []byte("b"), prefixRangeEnd("a\xff"))
+	assert.Equal(t, []byte{0}, prefixRangeEnd(""))
+}
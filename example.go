@@ -0,0 +1,139 @@
+package env
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// WriteExample writes a Go example function named name demonstrating how to
+// set every variable, with the expected values as the example output. The
+// output is computed by actually parsing the example values, so that the
+// example stays in sync with the destination structs. The destination types
+// are qualified with their package name, so the example is meant for an
+// external test package importing fmt, os and this package.
+func (p *Parser) WriteExample(w io.Writer, name string) error {
+	samples := make(map[string]string)
+
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "func %s() {\n", name)
+
+	for _, spec := range p.specs {
+		sample, ok := sampleValue(spec)
+		if !ok {
+			continue
+		}
+
+		samples[spec.name] = sample
+		fmt.Fprintf(&b, "os.Setenv(%q, %q)\n", spec.name, sample)
+	}
+
+	vars := make([]string, len(p.roots))
+	dests := make([]interface{}, len(p.roots))
+
+	for i, root := range p.roots {
+		t := root.Type().Elem()
+		if t.Name() == "" {
+			return ErrorExampleUnnamedType
+		}
+
+		vars[i] = "cfg"
+		if len(p.roots) > 1 {
+			vars[i] += strconv.Itoa(i + 1)
+		}
+
+		dests[i] = reflect.New(t).Interface()
+
+		fmt.Fprintf(&b, "\nvar %s %s", vars[i], t)
+	}
+
+	fmt.Fprintf(&b, "\nif err := env.Parse(&%s); err != nil {\npanic(err)\n}\n\n", strings.Join(vars, ", &"))
+
+	q, err := NewParser(Config{Sources: []Source{MapSource(samples)}}, dests...)
+	if err != nil {
+		return err
+	}
+
+	if err := q.Parse(); err != nil {
+		return fmt.Errorf("error parsing the example values: %w", err)
+	}
+
+	var output []string
+
+	for _, spec := range q.specs {
+		v := q.val(spec.dest)
+		if _, ok := samples[spec.name]; !ok || !v.IsValid() {
+			continue
+		}
+
+		if v.Kind() == reflect.Ptr && !v.IsNil() {
+			fmt.Fprintf(&b, "fmt.Println(*%s.%s)\n", vars[spec.dest.root], spec.dest.fieldPath())
+			v = v.Elem()
+		} else {
+			fmt.Fprintf(&b, "fmt.Println(%s.%s)\n", vars[spec.dest.root], spec.dest.fieldPath())
+		}
+
+		output = append(output, strings.TrimRight(fmt.Sprint(v.Interface()), " "))
+	}
+
+	b.WriteString("// Output:\n")
+
+	for _, line := range output {
+		fmt.Fprintf(&b, "// %s\n", line)
+	}
+
+	b.WriteString("}\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(src)
+
+	return err
+}
+
+// sampleValue returns an example value for the variable: its default value
+// if it has one, or a value depending on its type.
+func sampleValue(spec *spec) (string, bool) {
+	if spec.defaultVal != "" && !spec.multiple {
+		return spec.defaultVal, true
+	}
+
+	if spec.levels != nil {
+		return spec.levels[len(spec.levels)-1].name, true
+	}
+
+	var sample string
+
+	switch typeName(spec.typ) {
+	case "bool":
+		sample = "true"
+	case "int", "uint":
+		sample = "42"
+	case "float":
+		sample = "1.5"
+	case "string":
+		sample = "example"
+	case "duration":
+		sample = "1m30s"
+	default:
+		return "", false
+	}
+
+	if spec.invert {
+		sample = "false"
+	}
+
+	if spec.multiple {
+		sample += "," + sample
+	}
+
+	return sample, true
+}
@@ -0,0 +1,71 @@
+package env_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/Alex616/go-env"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ExampleEnvs struct {
+	Workers int           `env:"WORKERS,required"`
+	Timeout time.Duration `default:"5s"`
+	Hosts   []string
+	Ratio   *float64
+	Cache   bool `env:"name:NO_CACHE,invert"`
+	File    *NameDotName
+}
+
+func TestWriteExample(t *testing.T) {
+	expected := `func ExampleEnvs() {
+	os.Setenv("WORKERS", "42")
+	os.Setenv("timeout", "5s")
+	os.Setenv("hosts", "example,example")
+	os.Setenv("ratio", "1.5")
+	os.Setenv("NO_CACHE", "false")
+
+	var cfg env_test.ExampleEnvs
+	if err := env.Parse(&cfg); err != nil {
+		panic(err)
+	}
+
+	fmt.Println(cfg.Workers)
+	fmt.Println(cfg.Timeout)
+	fmt.Println(cfg.Hosts)
+	fmt.Println(*cfg.Ratio)
+	fmt.Println(cfg.Cache)
+	// Output:
+	// 42
+	// 5s
+	// [example example]
+	// 1.5
+	// true
+}
+`
+
+	var envs ExampleEnvs
+
+	p, err := env.NewParser(env.Config{}, &envs)
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+
+	err = p.WriteExample(&b, "ExampleEnvs")
+	require.NoError(t, err)
+	assert.Equal(t, expected, b.String())
+}
+
+func TestWriteExampleUnnamedType(t *testing.T) {
+	var envs struct {
+		Workers int
+	}
+
+	p, err := env.NewParser(env.Config{}, &envs)
+	require.NoError(t, err)
+
+	err = p.WriteExample(&bytes.Buffer{}, "ExampleEnvs")
+	assert.Equal(t, env.ErrorExampleUnnamedType, err)
+}
@@ -0,0 +1,36 @@
+package env
+
+import (
+	"os"
+	"strings"
+)
+
+// expand replaces the ${VAR} and $VAR references of value with the values
+// of the variables from the sources of the parser, when the option has the
+// expand option. ${VAR:-default} gives default when VAR is unset or empty,
+// and ${VAR-default} only when VAR is unset.
+func (p *Parser) expand(spec *spec, value string) string {
+	if !spec.expand {
+		return value
+	}
+
+	sources := p.sources()
+
+	return os.Expand(value, func(ref string) string {
+		name, def, unsetOnly := ref, "", false
+
+		if i := strings.Index(ref, ":-"); i > 0 {
+			name, def = ref[:i], ref[i+2:]
+		} else if i := strings.IndexByte(ref, '-'); i > 0 {
+			name, def, unsetOnly = ref[:i], ref[i+1:], true
+		}
+
+		for _, src := range sources {
+			if v, found := src.Lookup(name); found && (v != "" || unsetOnly) {
+				return v
+			}
+		}
+
+		return def
+	})
+}
@@ -0,0 +1,49 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpand(t *testing.T) {
+	var envs struct {
+		Data    string   `env:"expand"`
+		Cache   string   `env:"expand" default:"${HOME}/cache"`
+		Region  string   `env:"expand" default:"${REGION:-eu}"`
+		Zone    string   `env:"expand" default:"${ZONE-a}"`
+		Hosts   []string `env:"expand"`
+		Port    int      `env:"expand"`
+		Literal string
+	}
+
+	err := parse(envsMap{
+		"HOME":    "/home/app",
+		"ZONE":    "",
+		"PORT":    "8080",
+		"data":    "${HOME}/data",
+		"hosts":   "$HOME,${MISSING:-none}",
+		"port":    "${PORT}",
+		"literal": "${HOME}",
+	}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "/home/app/data", envs.Data)
+	assert.Equal(t, "/home/app/cache", envs.Cache)
+	assert.Equal(t, "eu", envs.Region)
+	assert.Equal(t, "", envs.Zone)
+	assert.Equal(t, []string{"/home/app", "none"}, envs.Hosts)
+	assert.Equal(t, 8080, envs.Port)
+	assert.Equal(t, "${HOME}", envs.Literal)
+}
+
+func TestExpandFromSources(t *testing.T) {
+	var envs struct {
+		URL string `env:"expand"`
+	}
+
+	p, err := NewParser(Config{Sources: []Source{MapSource{"url": "http://${HOST}:80"}, MapSource{"HOST": "db"}}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, "http://db:80", envs.URL)
+}
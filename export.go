@@ -0,0 +1,188 @@
+package env
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// SpecSchemaVersion is the version of the document written by WriteSpecs.
+// It is incremented whenever a change could break existing consumers, such
+// as a new type. Version 2 added the "path", "octal" and "rational" types,
+// and version 3 the "map" type along with the "mapValues" field.
+const SpecSchemaVersion = 3
+
+// specsDocument is the machine-readable description of the variables.
+type specsDocument struct {
+	Schema    int            `json:"schema"`
+	Variables []specDocument `json:"variables"`
+}
+
+// specDocument describes a single variable.
+type specDocument struct {
+	Name     string          `json:"name"`
+	Aliases  []string        `json:"aliases,omitempty"`
+	Field    string          `json:"field"`
+	Type     string          `json:"type"`
+	GoType   string          `json:"goType"`
+	Multiple bool            `json:"multiple"`
+	MapValue string          `json:"mapValues,omitempty"`
+	Required bool            `json:"required"`
+	Secret   bool            `json:"secret"`
+	Default  *string         `json:"default,omitempty"`
+	Help     string          `json:"help,omitempty"`
+	Docs     string          `json:"docs,omitempty"`
+	Levels   []levelDocument `json:"levels,omitempty"`
+	Values   []string        `json:"values,omitempty"`
+}
+
+// levelDocument describes a level name of an integer variable.
+type levelDocument struct {
+	Name  string `json:"name"`
+	Value int64  `json:"value"`
+}
+
+// WriteSpecs writes a JSON description of every variable, versioned with
+// SpecSchemaVersion, for code generators and other tools. Types are
+// described with one of "bool", "int" (big.Int included), "uint", "float"
+// (big.Float included), "rational" (big.Rat), "string", "duration",
+// "path" (env.Path), "octal" (file modes), "map" (key=value pairs, the type
+// of whose values, or of the elements of their lists, is given by
+// "mapValues") or "text" (any other type parsed from its text form). The
+// options select and frame the variables, see DocOptions.
+func (p *Parser) WriteSpecs(w io.Writer, opts ...DocOptions) error {
+	specs, o := p.docSpecs(opts)
+
+	doc := specsDocument{
+		Schema:    SpecSchemaVersion,
+		Variables: make([]specDocument, 0, len(specs)),
+	}
+
+	for _, spec := range specs {
+		v := specDocument{
+			Name:     spec.name,
+			Aliases:  spec.aliases,
+			Field:    spec.dest.fieldPath(),
+			Type:     spec.typeName(),
+			MapValue: spec.mapValueType(),
+			GoType:   spec.typ.String(),
+			Multiple: spec.multiple,
+			Required: spec.required,
+			Secret:   spec.secret || spec.mask,
+			Help:     spec.help,
+			Docs:     spec.docs,
+			Values:   spec.values,
+		}
+
+		if def := spec.displayDefault(); def != "" {
+			v.Default = &def
+		}
+
+		for _, l := range spec.levels {
+			v.Levels = append(v.Levels, levelDocument{Name: l.name, Value: l.value})
+		}
+
+		doc.Variables = append(doc.Variables, v)
+	}
+
+	if _, err := io.WriteString(w, o.Header); err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, o.Footer)
+
+	return err
+}
+
+// fieldPath returns the dotted names of the struct fields of the path.
+func (p path) fieldPath() string {
+	names := make([]string, len(p.fields))
+	for i, f := range p.fields {
+		names[i] = f.Name
+	}
+
+	return strings.Join(names, ".")
+}
+
+var (
+	durationType    = reflect.TypeOf(time.Duration(0)) // nolint:gochecknoglobals
+	envDurationType = reflect.TypeOf(Duration{})       // nolint:gochecknoglobals
+)
+
+// typeName returns the language-neutral name of the type of a variable,
+
+// typeName returns the language-neutral type of the values of the option,
+// see WriteSpecs.
+func (s *spec) typeName() string {
+	if s.mapped {
+		return "map"
+	}
+
+	return typeName(s.typ)
+}
+
+// mapValueType returns the language-neutral type of the values of the maps
+// read from key=value pairs, or of the elements of their lists, and "" for
+// the other options.
+func (s *spec) mapValueType() string {
+	if !s.mapped {
+		return ""
+	}
+
+	return typeName(s.typ.Elem())
+}
+
+// or of its elements for slices.
+func typeName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Slice && !reflect.PtrTo(t).Implements(textUnmarshalerType) && !isUnmarshaler(t) {
+		return typeName(t.Elem())
+	}
+
+	if t == durationType || t == envDurationType {
+		return "duration"
+	}
+
+	if t == envPathType {
+		return "path"
+	}
+
+	if t == fileModeType {
+		return "octal"
+	}
+
+	if isBigNumber(t) {
+		return bigTypeName(t)
+	}
+
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) || t.Implements(textUnmarshalerType) || isUnmarshaler(t) {
+		return "text"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "int"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "uint"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.String:
+		return "string"
+	default:
+		return "text"
+	}
+}
@@ -0,0 +1,134 @@
+package env_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/Alex616/go-env"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSpecs(t *testing.T) {
+	expected := `{
+  "schema": 3,
+  "variables": [
+    {
+      "name": "WORKERS",
+      "field": "Workers",
+      "type": "int",
+      "goType": "int",
+      "multiple": false,
+      "required": true,
+      "secret": false,
+      "help": "number of workers"
+    },
+    {
+      "name": "timeout",
+      "field": "Timeout",
+      "type": "duration",
+      "goType": "time.Duration",
+      "multiple": false,
+      "required": false,
+      "secret": false,
+      "default": "5s"
+    },
+    {
+      "name": "hosts",
+      "aliases": [
+        "peers"
+      ],
+      "field": "Hosts",
+      "type": "string",
+      "goType": "[]string",
+      "multiple": true,
+      "required": false,
+      "secret": false
+    },
+    {
+      "name": "file",
+      "field": "File",
+      "type": "text",
+      "goType": "*env_test.NameDotName",
+      "multiple": false,
+      "required": false,
+      "secret": false
+    },
+    {
+      "name": "token",
+      "field": "Token",
+      "type": "string",
+      "goType": "env.Secret",
+      "multiple": false,
+      "required": false,
+      "secret": true,
+      "default": "***"
+    },
+    {
+      "name": "verbosity",
+      "field": "Verbosity",
+      "type": "int",
+      "goType": "int",
+      "multiple": false,
+      "required": false,
+      "secret": false,
+      "levels": [
+        {
+          "name": "quiet",
+          "value": 0
+        },
+        {
+          "name": "loud",
+          "value": 1
+        }
+      ]
+    }
+  ]
+}
+`
+
+	var args struct {
+		Workers   int           `env:"WORKERS,required" help:"number of workers"`
+		Timeout   time.Duration `default:"5s"`
+		Hosts     []string      `env:"alias:peers"`
+		File      *NameDotName
+		Token     env.Secret `default:"abc"`
+		Verbosity int        `levels:"quiet,loud"`
+	}
+
+	p, err := env.NewParser(env.Config{}, &args)
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+	require.NoError(t, p.WriteSpecs(&b))
+	assert.Equal(t, expected, b.String())
+}
+
+func TestWriteSpecsMap(t *testing.T) {
+	var args struct {
+		Limits  map[string]int
+		Accepts map[string][]string `kvSep:":"`
+	}
+
+	p, err := env.NewParser(env.Config{}, &args)
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+	require.NoError(t, p.WriteSpecs(&b))
+	assert.Contains(t, b.String(), `"type": "map",
+      "goType": "map[string]int",
+      "multiple": false,
+      "mapValues": "int",`)
+	assert.Contains(t, b.String(), `"mapValues": "string",`)
+
+	assert.Equal(t, "map", p.Specs()[0].Type)
+	assert.Equal(t, "int", p.Specs()[0].MapValues)
+
+	assert.Contains(t, p.Help(), "limits key=int,...")
+	assert.Contains(t, p.Help(), "accepts key:string|...,...")
+
+	b.Reset()
+	require.NoError(t, p.WriteMarkdown(&b))
+	assert.Contains(t, b.String(), "| `accepts` | key:string\\|...,... |")
+}
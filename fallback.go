@@ -0,0 +1,72 @@
+package env
+
+import "strings"
+
+// parseDefaultEnv parses a defaultEnv tag into the names of the variables
+// to fall back on, in order.
+func parseDefaultEnv(tag string) ([]string, error) {
+	var names []string
+
+	for _, name := range strings.Split(tag, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, ErrorMalformedTag
+		}
+
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// lookupFallback looks up the variables named by the defaultEnv tag of the
+// option, in order, and returns the first that is set. The variables that
+// are themselves options are looked up under their aliases and defaultEnv
+// tags too, so that fallbacks can be chained. seen records the options
+// already visited to stop on cycles, and may be nil.
+func (p *Parser) lookupFallback(sp *spec, sources []Source, seen map[*spec]bool) (string, string, bool) {
+	if seen == nil {
+		seen = make(map[*spec]bool)
+	}
+
+	seen[sp] = true
+
+	for _, name := range sp.defaultEnv {
+		next := p.specNamed(name)
+
+		if next == nil {
+			for _, src := range sources {
+				if value, found := src.Lookup(name); found {
+					return name, value, true
+				}
+			}
+
+			continue
+		}
+
+		if seen[next] {
+			continue
+		}
+
+		if name, value, found := next.lookup(sources); found {
+			return name, value, true
+		}
+
+		if name, value, found := p.lookupFallback(next, sources, seen); found {
+			return name, value, true
+		}
+	}
+
+	return "", "", false
+}
+
+// specNamed returns the option with the given name, if any.
+func (p *Parser) specNamed(name string) *spec {
+	for _, spec := range p.specs {
+		if spec.name == name {
+			return spec
+		}
+	}
+
+	return nil
+}
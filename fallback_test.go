@@ -0,0 +1,60 @@
+package env
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultEnv(t *testing.T) {
+	var envs struct {
+		CacheDir string `env:"CACHE_DIR" defaultEnv:"XDG_CACHE_HOME,HOME"`
+		DataDir  string `env:"DATA_DIR" defaultEnv:"XDG_DATA_HOME" default:"/data"`
+		LogDir   string `env:"LOG_DIR,required" defaultEnv:"CACHE_DIR"`
+		Set      string `env:"SET" defaultEnv:"HOME"`
+	}
+
+	err := parse(envsMap{"HOME": "/home/me", "SET": "set"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "/home/me", envs.CacheDir)
+	assert.Equal(t, "/data", envs.DataDir)
+	assert.Equal(t, "/home/me", envs.LogDir)
+	assert.Equal(t, "set", envs.Set)
+
+	err = parse(envsMap{"XDG_CACHE_HOME": "/cache", "HOME": "/home/me", "XDG_DATA_HOME": "/xdg"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "/cache", envs.CacheDir)
+	assert.Equal(t, "/xdg", envs.DataDir)
+	assert.Equal(t, "/cache", envs.LogDir)
+}
+
+func TestDefaultEnvRequired(t *testing.T) {
+	var envs struct {
+		A string `env:"A,required" defaultEnv:"B"`
+		B string `env:"B" defaultEnv:"A"`
+	}
+
+	err := parse(envsMap{}, &envs)
+	assert.True(t, errors.Is(err, ErrorFieldIsRequired))
+}
+
+func TestDefaultEnvMalformed(t *testing.T) {
+	var envs struct {
+		A string `defaultEnv:"B,"`
+	}
+
+	err := parse(envsMap{}, &envs)
+	assert.True(t, errors.Is(err, ErrorMalformedTag))
+}
+
+func TestDefaultEnvHelp(t *testing.T) {
+	var envs struct {
+		CacheDir string `env:"CACHE_DIR" defaultEnv:"XDG_CACHE_HOME,HOME" default:"/tmp"`
+	}
+
+	p, err := NewParser(Config{}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "Environments:\n  CACHE_DIR string [fallback: $XDG_CACHE_HOME, $HOME, default: /tmp]\n", p.Help())
+}
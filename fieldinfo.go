@@ -0,0 +1,47 @@
+package env
+
+import "reflect"
+
+// FieldInfo identifies the struct field a variable is stored in, so that
+// tools can map a variable or an error back to its definition.
+type FieldInfo struct {
+	// Name is the name of the variable.
+	Name string
+	// Aliases are the other names of the variable.
+	Aliases []string
+	// Path is the dotted path of the field from its root struct type, such
+	// as "Config.DB.Host". It is stable as long as the fields are not
+	// renamed, and is the same as FieldError.Field.
+	Path string
+	// GoType is the Go type of the field, such as "time.Duration".
+	GoType string
+}
+
+// FieldInfo returns the field of the variable with the given name or
+// alias.
+func (p *Parser) FieldInfo(name string) (FieldInfo, bool) {
+	for _, spec := range p.specs {
+		if spec.name != name && !containsString(spec.aliases, name) {
+			continue
+		}
+
+		return FieldInfo{
+			Name:    spec.name,
+			Aliases: spec.aliases,
+			Path:    qualifiedPath(p.roots[spec.dest.root].Type().Elem(), spec.dest),
+			GoType:  spec.typ.String(),
+		}, true
+	}
+
+	return FieldInfo{}, false
+}
+
+// qualifiedPath returns the dotted path of a field prefixed with the name
+// of its root struct type, if it has one.
+func qualifiedPath(root reflect.Type, dest path) string {
+	if root.Name() == "" {
+		return dest.fieldPath()
+	}
+
+	return root.Name() + "." + dest.fieldPath()
+}
@@ -0,0 +1,54 @@
+package env
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pathDB struct {
+	Host string `env:"DB_HOST,required,alias:DATABASE_HOST"`
+	Port int    `env:"DB_PORT"`
+}
+
+type pathConfig struct {
+	DB pathDB `onMissing:"disable"`
+}
+
+func TestFieldInfo(t *testing.T) {
+	p, err := NewParser(Config{}, &pathConfig{})
+	require.NoError(t, err)
+
+	info, found := p.FieldInfo("DATABASE_HOST")
+	require.True(t, found)
+	assert.Equal(t, FieldInfo{
+		Name:    "DB_HOST",
+		Aliases: []string{"DATABASE_HOST"},
+		Path:    "pathConfig.DB.Host",
+		GoType:  "string",
+	}, info)
+
+	_, found = p.FieldInfo("MISSING")
+	assert.False(t, found)
+}
+
+func TestFieldErrorPath(t *testing.T) {
+	err := parse(envsMap{"DB_PORT": "x"}, &pathConfig{})
+
+	var fe *FieldError
+	require.True(t, errors.As(err, &fe))
+	assert.Equal(t, "pathConfig.DB.Port", fe.Field)
+	assert.Equal(t, "DB_PORT", fe.Name)
+
+	var invalid struct {
+		Foo string `env:"required:yes"`
+	}
+
+	_, err = NewParser(Config{}, &invalid)
+	require.True(t, errors.As(err, &fe))
+	assert.Equal(t, "Foo", fe.Field)
+	assert.Equal(t, "", fe.Name)
+	assert.True(t, errors.Is(err, ErrorMalformedTag))
+}
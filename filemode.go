@@ -0,0 +1,51 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var fileModeType = reflect.TypeOf(os.FileMode(0)) // nolint:gochecknoglobals
+
+// isFileMode returns true if t is os.FileMode or a pointer to it, which are
+// parsed from octal permissions such as 0640.
+func isFileMode(t reflect.Type) bool {
+	return t == fileModeType || t == reflect.PtrTo(fileModeType)
+}
+
+// parseFileMode parses octal permissions, such as "0640", "640" or "0o640",
+// into dest. Only the permission bits can be set.
+func parseFileMode(dest reflect.Value, value string) error {
+	n, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimPrefix(value, "0o"), "0O"), 8, 32)
+	if err != nil {
+		return err
+	}
+
+	if os.FileMode(n)&^os.ModePerm != 0 {
+		return fmt.Errorf("%q: %w", value, ErrorNotPermissions)
+	}
+
+	mode := reflect.ValueOf(os.FileMode(n))
+
+	if dest.Kind() == reflect.Ptr {
+		ptr := reflect.New(fileModeType)
+		ptr.Elem().Set(mode)
+		dest.Set(ptr)
+	} else {
+		dest.Set(mode)
+	}
+
+	return nil
+}
+
+// formatFileMode returns the permissions held by v in octal, such as 0640.
+func formatFileMode(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	return fmt.Sprintf("%04o", v.Interface().(os.FileMode)&os.ModePerm)
+}
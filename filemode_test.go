@@ -0,0 +1,35 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileMode(t *testing.T) {
+	envs := struct {
+		Umask   os.FileMode
+		DirMode *os.FileMode
+		Modes   []os.FileMode
+		Default os.FileMode
+	}{Default: 0o755}
+
+	p, err := pparse(envsMap{"umask": "0027", "dirmode": "0o750", "modes": "640,600"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o027), envs.Umask)
+	require.NotNil(t, envs.DirMode)
+	assert.Equal(t, os.FileMode(0o750), *envs.DirMode)
+	assert.Equal(t, []os.FileMode{0o640, 0o600}, envs.Modes)
+	assert.Equal(t, "0027", p.Snapshot()["umask"])
+	assert.Equal(t, "Environments:\n  umask octal\n  dirmode octal\n  modes octal,...\n  default octal [default: 0755]\n", p.Help())
+
+	err = parse(envsMap{"umask": "0800"}, &envs)
+	require.Error(t, err)
+	assert.Equal(t, "expected a value of type octal", err.(Errors)[0].Hint)
+
+	err = parse(envsMap{"umask": "01777"}, &envs)
+	assert.True(t, errors.Is(err, ErrorNotPermissions))
+}
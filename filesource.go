@@ -0,0 +1,149 @@
+package env
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// fileSource is a Source reading variables from a structured config file.
+type fileSource struct {
+	path      string
+	unmarshal func([]byte, interface{}) error
+
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// FileSource returns a Source reading variables from a structured config
+// file decoded with unmarshal, such as json.Unmarshal. The file is decoded
+// into a map[string]interface{} and reloaded before every Parse. Nested keys
+// are joined with underscores and matched ignoring case and treating dots,
+// dashes and underscores alike, so that {"db": {"host": "x"}} provides
+// DB_HOST and db.host. Arrays are provided as CSV strings, so that they can
+// fill slice fields.
+func FileSource(path string, unmarshal func([]byte, interface{}) error) Source {
+	return &fileSource{path: path, unmarshal: unmarshal}
+}
+
+// JSONFileSource returns a Source reading variables from a JSON file, see
+// FileSource.
+func JSONFileSource(path string) Source {
+	return FileSource(path, unmarshalJSON)
+}
+
+// unmarshalJSON decodes JSON numbers as json.Number to format them back
+// exactly as written.
+func unmarshalJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	return dec.Decode(v)
+}
+
+// Load reads and flattens the file.
+func (s *fileSource) Load() error {
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+
+	var doc map[string]interface{}
+	if err := s.unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%s: %w", s.path, err)
+	}
+
+	values := make(map[string]string)
+	flatten(values, "", doc)
+
+	s.mu.Lock()
+	s.values = values
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Lookup returns the value of the variable from the file.
+func (s *fileSource) Lookup(name string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	value, found := s.values[normalizeKey(name)]
+
+	return value, found
+}
+
+// normalizeKey returns the form under which keys of structured files are
+// matched with variable names.
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+// flatten stores the scalar values of a decoded document under their
+// normalized, underscore-joined keys.
+func flatten(values map[string]string, prefix string, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+
+		// visit keys in order so that colliding keys resolve deterministically
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			key := normalizeKey(k)
+			if prefix != "" {
+				key = prefix + "_" + key
+			}
+
+			flatten(values, key, v[k])
+		}
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = e
+		}
+
+		flatten(values, prefix, m)
+	case []interface{}:
+		elems := make([]string, len(v))
+		for i, e := range v {
+			elems[i] = formatScalar(e)
+		}
+
+		values[prefix] = formatCSV(elems)
+	case nil:
+	default:
+		values[prefix] = formatScalar(v)
+	}
+}
+
+// formatScalar formats a decoded value, encoding non-scalar values as JSON.
+func formatScalar(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case encoding.TextMarshaler:
+		if text, err := v.MarshalText(); err == nil {
+			return string(text)
+		}
+
+		return fmt.Sprint(v)
+	case map[string]interface{}, []interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+
+		return string(b)
+	default:
+		return fmt.Sprint(v)
+	}
+}
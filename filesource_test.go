@@ -0,0 +1,58 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONFileSource(t *testing.T) {
+	var envs struct {
+		Workers  int
+		Ratio    float64
+		Debug    bool
+		Name     string
+		DBHost   string `env:"DB_HOST"`
+		DBPort   int    `env:"db.port"`
+		LogLevel string `env:"LOG_LEVEL"`
+		Hosts    []string
+		Empty    string `default:"default"`
+	}
+
+	os.Clearenv()
+	_ = os.Setenv("name", "from-env")
+
+	p, err := NewParser(Config{Sources: []Source{EnvSource(), JSONFileSource("testdata/config.json")}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+
+	assert.Equal(t, 8, envs.Workers)
+	assert.Equal(t, 0.25, envs.Ratio)
+	assert.True(t, envs.Debug)
+	assert.Equal(t, "from-env", envs.Name)
+	assert.Equal(t, "localhost", envs.DBHost)
+	assert.Equal(t, 5432, envs.DBPort)
+	assert.Equal(t, "info", envs.LogLevel)
+	assert.Equal(t, []string{"a", "b,c"}, envs.Hosts)
+	assert.Equal(t, "default", envs.Empty)
+}
+
+func TestJSONFileSourceErrors(t *testing.T) {
+	var envs struct {
+		Workers int
+	}
+
+	p, err := NewParser(Config{Sources: []Source{JSONFileSource("testdata/missing.json")}}, &envs)
+	require.NoError(t, err)
+
+	err = p.Parse()
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	p, err = NewParser(Config{Sources: []Source{JSONFileSource("testdata/config.json")}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, 8, envs.Workers)
+}
@@ -0,0 +1,24 @@
+package env
+
+import (
+	"io/ioutil"
+	"strings"
+)
+
+// readFileValue returns the contents of the file whose path is value, when
+// the option has the file option, without the trailing newline, as mounted
+// by Docker and Kubernetes for secrets.
+func (s *spec) readFileValue(value string) (string, error) {
+	if !s.file {
+		return value, nil
+	}
+
+	data, err := ioutil.ReadFile(value)
+	if err != nil {
+		return "", err
+	}
+
+	contents := strings.TrimSuffix(string(data), "\n")
+
+	return strings.TrimSuffix(contents, "\r"), nil
+}
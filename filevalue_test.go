@@ -0,0 +1,46 @@
+package env
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileValue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filevalue")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	token := filepath.Join(dir, "token")
+	require.NoError(t, ioutil.WriteFile(token, []byte("s3cr3t\n"), 0o600))
+
+	ports := filepath.Join(dir, "ports")
+	require.NoError(t, ioutil.WriteFile(ports, []byte("80,443\r\n"), 0o600))
+
+	var envs struct {
+		Token string `env:"name:TOKEN,file,secret"`
+		Ports []int  `env:"file"`
+		Key   string `env:"file,expand" default:"${SECRETS}/token"`
+	}
+
+	err = parse(envsMap{"TOKEN": token, "ports": ports, "SECRETS": dir}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", envs.Token)
+	assert.Equal(t, []int{80, 443}, envs.Ports)
+	assert.Equal(t, "s3cr3t", envs.Key)
+
+	var missing struct {
+		Token string `env:"name:TOKEN,file"`
+	}
+
+	err = parse(envsMap{"TOKEN": filepath.Join(dir, "missing")}, &missing)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+	assert.Contains(t, err.Error(), "TOKEN")
+}
@@ -0,0 +1,156 @@
+package env
+
+import (
+	"flag"
+	"strings"
+	"sync"
+)
+
+// flagSource is the Source holding the values of the command-line flags
+// registered with RegisterFlags. It takes precedence over Config.Sources.
+type flagSource struct {
+	mu     sync.RWMutex
+	values map[string][]string
+}
+
+// Lookup returns the value of the flag of the variable, if it was set.
+// Repeated flags of multiple variables are joined as a CSV string.
+func (s *flagSource) Lookup(name string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	values, found := s.values[name]
+	if !found {
+		return "", false
+	}
+
+	if len(values) == 1 {
+		return values[0], true
+	}
+
+	return formatCSV(values), true
+}
+
+// SourceName returns "flags".
+func (s *flagSource) SourceName() string {
+	return "flags"
+}
+
+func (s *flagSource) set(spec *spec, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if spec.multiple {
+		s.values[spec.name] = append(s.values[spec.name], value)
+	} else {
+		s.values[spec.name] = []string{value}
+	}
+}
+
+// FlagValue is the value of the command-line flag of a variable. It
+// implements flag.Value, and pflag.Value with Type.
+type FlagValue struct {
+	src  *flagSource
+	spec *spec
+}
+
+// String returns the value of the flag, or the default value of the
+// variable if the flag was not set.
+func (v *FlagValue) String() string {
+	if v.spec == nil {
+		return ""
+	}
+
+	if value, found := v.src.Lookup(v.spec.name); found {
+		return v.spec.redact(value)
+	}
+
+	return v.spec.displayDefault()
+}
+
+// Set records the value of the flag. Values are parsed along with the
+// other sources by Parse. Flags of multiple variables may be repeated.
+func (v *FlagValue) Set(value string) error {
+	v.src.set(v.spec, value)
+
+	return nil
+}
+
+// IsBoolFlag allows boolean variables to be set with a bare flag.
+func (v *FlagValue) IsBoolFlag() bool {
+	return v.spec.boolean && !v.spec.multiple
+}
+
+// Type returns the type of the variable, as described by WriteSpecs.
+func (v *FlagValue) Type() string {
+	if v.spec == nil {
+		return ""
+	}
+
+	return v.spec.typeName()
+}
+
+// Flag describes the command-line flag of a variable, for flag packages
+// other than the standard one, such as pflag and cobra.
+type Flag struct {
+	// Name is the name of the flag, see RegisterFlags.
+	Name string
+	// Usage is the help text of the variable.
+	Usage string
+	// DefValue is the default value of the variable as displayed in help.
+	DefValue string
+	// Boolean is true if the flag can be given without a value, in which
+	// case it means "true".
+	Boolean bool
+	// Value is the value of the flag.
+	Value *FlagValue
+}
+
+// RegisterFlags defines a flag in fs for every variable, named after the
+// variable in lower case with underscores and dots as dashes, such as
+// -workers or --db-host. The values of the flags that are set override all
+// the sources when Parse is called after fs.Parse, so that the same struct
+// can be configured from the environment in deployments and from the
+// command line in local runs.
+func (p *Parser) RegisterFlags(fs *flag.FlagSet) {
+	for _, f := range p.Flags() {
+		fs.Var(f.Value, f.Name, f.Usage)
+	}
+}
+
+// Flags returns the flags of the variables, to be registered with flag
+// packages other than the standard one, like RegisterFlags does. With
+// pflag or cobra:
+//
+//	for _, f := range p.Flags() {
+//		pf := cmd.PersistentFlags().VarPF(f.Value, f.Name, "", f.Usage)
+//		if f.Boolean {
+//			pf.NoOptDefVal = "true"
+//		}
+//	}
+func (p *Parser) Flags() []Flag {
+	if p.flags == nil {
+		p.flags = &flagSource{values: make(map[string][]string)}
+	}
+
+	flags := make([]Flag, len(p.specs))
+
+	for i, spec := range p.specs {
+		v := &FlagValue{src: p.flags, spec: spec}
+
+		flags[i] = Flag{
+			Name:     flagName(strings.TrimPrefix(spec.name, p.config.Prefix)),
+			Usage:    spec.help,
+			DefValue: spec.displayDefault(),
+			Boolean:  v.IsBoolFlag(),
+			Value:    v,
+		}
+	}
+
+	return flags
+}
+
+// flagName returns the name of the flag of a variable.
+func flagName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "-", ".", "-").Replace(name))
+}
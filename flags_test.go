@@ -0,0 +1,95 @@
+package env
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterFlags(t *testing.T) {
+	var envs struct {
+		Workers int      `env:"WORKERS" help:"number of workers"`
+		DBHost  string   `env:"DB_HOST" default:"localhost"`
+		Debug   bool     `env:"DEBUG"`
+		Hosts   []string `env:"HOSTS"`
+		Name    string   `env:"NAME"`
+	}
+
+	p, err := NewParser(Config{}, &envs)
+	require.NoError(t, err)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	p.RegisterFlags(fs)
+
+	os.Clearenv()
+	_ = os.Setenv("WORKERS", "4")
+	_ = os.Setenv("NAME", "foo")
+
+	err = fs.Parse([]string{"--workers", "8", "--db-host=db", "--debug", "--hosts", "a", "--hosts", "b,c"})
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+
+	assert.Equal(t, 8, envs.Workers)
+	assert.Equal(t, "db", envs.DBHost)
+	assert.True(t, envs.Debug)
+	assert.Equal(t, []string{"a", "b,c"}, envs.Hosts)
+	assert.Equal(t, "foo", envs.Name)
+}
+
+func TestRegisterFlagsUsage(t *testing.T) {
+	var envs struct {
+		Workers int    `env:"WORKERS" help:"number of workers"`
+		Token   string `env:"TOKEN,secret" default:"abc"`
+	}
+
+	p, err := NewParser(Config{}, &envs)
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(&b)
+	p.RegisterFlags(fs)
+	fs.PrintDefaults()
+
+	assert.Equal(t, `  -token value
+    	 (default ***)
+  -workers value
+    	number of workers
+`, b.String())
+}
+
+func TestFlags(t *testing.T) {
+	var envs struct {
+		Workers int           `env:"WORKERS" help:"number of workers"`
+		Timeout time.Duration `env:"TIMEOUT" default:"5s"`
+		Debug   bool          `env:"DEBUG"`
+	}
+
+	p, err := NewParser(Config{}, &envs)
+	require.NoError(t, err)
+
+	flags := p.Flags()
+	require.Len(t, flags, 3)
+	assert.Equal(t, "workers", flags[0].Name)
+	assert.Equal(t, "number of workers", flags[0].Usage)
+	assert.Equal(t, "int", flags[0].Value.Type())
+	assert.Equal(t, "5s", flags[1].DefValue)
+	assert.Equal(t, "duration", flags[1].Value.Type())
+	assert.False(t, flags[1].Boolean)
+	assert.True(t, flags[2].Boolean)
+
+	os.Clearenv()
+
+	require.NoError(t, flags[0].Value.Set("8"))
+	assert.Equal(t, "8", flags[0].Value.String())
+	assert.Equal(t, "5s", flags[1].Value.String())
+	require.NoError(t, p.Parse())
+	assert.Equal(t, 8, envs.Workers)
+	assert.Equal(t, 5*time.Second, envs.Timeout)
+}
@@ -0,0 +1,118 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Get resolves the variable with the given name or alias into dest, a
+// pointer to a value of the type of its field, the way Parse would but
+// without processing the other variables, and leaves the destination
+// structs untouched. Tools with many variables of which they only read a
+// few can call it instead of Parse, to skip the lookups, parsing and
+// defaults of the others. It does not make NewParser lazy: NewParser still
+// walks the structs and builds every option, validating their tags. The
+// sources are loaded by the first call, unless Parse or Reload loaded them
+// already. Templated defaults are rendered against the destination struct
+// as it is, and the slices of structs and the catch-all maps cannot be
+// resolved on their own.
+func (p *Parser) Get(name string, dest interface{}) error {
+	spec := p.specAliased(name)
+	if spec == nil {
+		return fmt.Errorf("%s: %w", name, ErrorUnknownVariable)
+	}
+
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("%s: %w", name, ErrorNilPointer)
+	}
+
+	if v.Type().Elem() != spec.typ {
+		return fmt.Errorf("%s: %s instead of %s: %w", name, v.Type().Elem(), spec.typ, ErrorTypeMismatch)
+	}
+
+	p.processMu.Lock()
+	defer p.processMu.Unlock()
+
+	if !p.parsed && !p.loaded {
+		if err := p.loadSources(); err != nil {
+			return err
+		}
+
+		p.loaded = true
+	}
+
+	value := reflect.New(spec.typ).Elem()
+	if err := p.resolveValue(value, spec); err != nil {
+		return err
+	}
+
+	v.Elem().Set(value)
+
+	return nil
+}
+
+// specAliased returns the option with the given name or alias, if any.
+func (p *Parser) specAliased(name string) *spec {
+	for _, spec := range p.specs {
+		if spec.name == name || containsString(spec.aliases, name) {
+			return spec
+		}
+	}
+
+	return nil
+}
+
+// resolveValue sets dest to the value of the variable of spec, or else to
+// its default value.
+func (p *Parser) resolveValue(dest reflect.Value, spec *spec) error {
+	sources := p.sources()
+
+	name, value, found := spec.lookup(sources)
+	if !found && spec.defaultEnv != nil {
+		name, value, found = p.lookupFallback(spec, sources, nil)
+	}
+
+	if found {
+		if err := p.captureValue(dest, spec, name, value); err != nil {
+			return p.fieldError(spec, name, err)
+		}
+
+		return nil
+	}
+
+	if spec.required {
+		return p.fieldError(spec, spec.name, p.requiredError(spec, nil, &suggester{p: p}))
+	}
+
+	if value, ok := p.dynamicDefault(spec); ok {
+		if err := p.parseDefault(dest, spec, value); err != nil {
+			return p.fieldError(spec, spec.name, p.messages.invalidDefault(spec.name, spec.redactError(err, value)))
+		}
+
+		return nil
+	}
+
+	switch {
+	case spec.defaultValue.IsValid():
+		dest.Set(cloneValue(spec.defaultValue))
+	case spec.defaultTemplate != nil:
+		p.mu.RLock()
+		value, err := spec.render(p.roots[spec.dest.root])
+		p.mu.RUnlock()
+
+		if err == nil {
+			err = p.parseDefault(dest, spec, value)
+		}
+
+		if err != nil {
+			return p.fieldError(spec, spec.name, p.messages.invalidDefault(spec.name, spec.redactError(err, value)))
+		}
+	case spec.defaultVal != "":
+		if err := p.parseDefault(dest, spec, spec.defaultVal); err != nil {
+			return p.fieldError(spec, spec.name, p.messages.invalidDefault(spec.name, spec.redactError(err, spec.defaultVal)))
+		}
+	}
+
+	return nil
+}
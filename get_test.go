@@ -0,0 +1,120 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	var envs struct {
+		Host    string        `env:",required"`
+		Ports   []int         `default:"80,443"`
+		Timeout time.Duration `env:",alias:deadline"`
+		Workers int
+		Addr    string `default:"{{ .Host }}:80"`
+	}
+
+	envs.Workers = 4
+
+	p, err := NewParser(Config{Sources: []Source{MapSource{"host": "example.com", "deadline": "1m"}}}, &envs)
+	require.NoError(t, err)
+
+	var host string
+	require.NoError(t, p.Get("host", &host))
+	assert.Equal(t, "example.com", host)
+
+	var ports []int
+	require.NoError(t, p.Get("ports", &ports))
+	assert.Equal(t, []int{80, 443}, ports)
+
+	var timeout time.Duration
+	require.NoError(t, p.Get("deadline", &timeout))
+	assert.Equal(t, time.Minute, timeout)
+
+	var workers int
+	require.NoError(t, p.Get("workers", &workers))
+	assert.Equal(t, 4, workers)
+
+	// templates are rendered against the struct, which was not parsed
+	var addr string
+	require.NoError(t, p.Get("addr", &addr))
+	assert.Equal(t, ":80", addr)
+
+	assert.Empty(t, envs.Host)
+}
+
+func TestGetErrors(t *testing.T) {
+	var envs struct {
+		Host    string `env:",required"`
+		Workers int
+	}
+
+	p, err := NewParser(Config{Sources: []Source{MapSource{"workers": "many"}}}, &envs)
+	require.NoError(t, err)
+
+	var host string
+	assert.True(t, errors.Is(p.Get("host", &host), ErrorFieldIsRequired))
+	assert.True(t, errors.Is(p.Get("port", &host), ErrorUnknownVariable))
+	assert.True(t, errors.Is(p.Get("workers", &host), ErrorTypeMismatch))
+	assert.True(t, errors.Is(p.Get("workers", nil), ErrorNilPointer))
+
+	var workers int
+
+	err = p.Get("workers", &workers)
+	require.Error(t, err)
+
+	var fieldErr *FieldError
+	require.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "workers", fieldErr.Name)
+}
+
+// wideStruct returns a pointer to a struct with n string fields, named F0
+// to Fn-1.
+func wideStruct(n int) interface{} {
+	fields := make([]reflect.StructField, n)
+	for i := range fields {
+		fields[i] = reflect.StructField{Name: fmt.Sprintf("F%d", i), Type: reflect.TypeOf("")}
+	}
+
+	return reflect.New(reflect.StructOf(fields)).Interface()
+}
+
+func BenchmarkParseWide(b *testing.B) {
+	p, err := NewParser(Config{Sources: []Source{MapSource{"f1": "a"}}}, wideStruct(100))
+	require.NoError(b, err)
+
+	for i := 0; i < b.N; i++ {
+		if err := p.Parse(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetWide(b *testing.B) {
+	p, err := NewParser(Config{Sources: []Source{MapSource{"f1": "a"}}}, wideStruct(100))
+	require.NoError(b, err)
+
+	var value string
+
+	for i := 0; i < b.N; i++ {
+		if err := p.Get("f1", &value); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNewParserWide(b *testing.B) {
+	dest := wideStruct(100)
+
+	for i := 0; i < b.N; i++ {
+		if _, err := NewParser(Config{Sources: []Source{MapSource{"f1": "a"}}}, dest); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
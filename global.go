@@ -0,0 +1,72 @@
+package env
+
+import "sync"
+
+// Global holds a package-level configuration that is parsed on first use,
+// avoiding init-order pitfalls, and that is safe for concurrent use. The
+// result of the first parse, including its error, is kept until Reset.
+//
+//	var config = env.NewGlobal(env.Config{}, func() interface{} { return &Config{} })
+//
+//	func Settings() *Config {
+//		return config.MustGet().(*Config)
+//	}
+type Global struct {
+	config  Config
+	newDest func() interface{}
+
+	mu   sync.Mutex
+	done bool
+	dest interface{}
+	err  error
+}
+
+// NewGlobal returns a Global parsing into the struct returned by newDest,
+// which must be a pointer to a struct, with the given configuration.
+func NewGlobal(config Config, newDest func() interface{}) *Global {
+	return &Global{config: config, newDest: newDest}
+}
+
+// Get returns the destination struct, parsing it on the first call.
+func (g *Global) Get() (interface{}, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.done {
+		g.dest = g.newDest()
+		g.err = g.parse()
+		g.done = true
+	}
+
+	return g.dest, g.err
+}
+
+// MustGet is like Get, but panics if the configuration could not be parsed.
+func (g *Global) MustGet() interface{} {
+	dest, err := g.Get()
+	if err != nil {
+		panic(err)
+	}
+
+	return dest
+}
+
+// Reset discards the parsed configuration, so that the next call to Get
+// parses it again, typically after a test changed the environment.
+func (g *Global) Reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.done = false
+	g.dest = nil
+	g.err = nil
+}
+
+func (g *Global) parse() error {
+	p, err := NewParser(g.config, g.dest)
+	if err != nil {
+		return err
+	}
+
+	return p.Parse()
+}
@@ -0,0 +1,71 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type globalEnvs struct {
+	Workers int `env:"WORKERS,required"`
+}
+
+func TestGlobal(t *testing.T) {
+	calls := 0
+
+	g := NewGlobal(Config{}, func() interface{} {
+		calls++
+
+		return &globalEnvs{}
+	})
+
+	os.Clearenv()
+	_ = os.Setenv("WORKERS", "4")
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			assert.Equal(t, 4, g.MustGet().(*globalEnvs).Workers)
+		}()
+	}
+
+	wg.Wait()
+	assert.Equal(t, 1, calls)
+
+	_ = os.Setenv("WORKERS", "8")
+	assert.Equal(t, 4, g.MustGet().(*globalEnvs).Workers)
+
+	g.Reset()
+	assert.Equal(t, 8, g.MustGet().(*globalEnvs).Workers)
+	assert.Equal(t, 2, calls)
+}
+
+func TestGlobalError(t *testing.T) {
+	g := NewGlobal(Config{}, func() interface{} { return &globalEnvs{} })
+
+	os.Clearenv()
+
+	_, err := g.Get()
+	assert.True(t, errors.Is(err, ErrorFieldIsRequired))
+	assert.Panics(t, func() { g.MustGet() })
+
+	_ = os.Setenv("WORKERS", "4")
+
+	_, err = g.Get()
+	require.Error(t, err)
+
+	g.Reset()
+
+	dest, err := g.Get()
+	require.NoError(t, err)
+	assert.Equal(t, &globalEnvs{Workers: 4}, dest)
+}
@@ -3,6 +3,8 @@ module github.com/Alex616/go-env
 go 1.14
 
 require (
+	github.com/BurntSushi/toml v0.3.1
 	github.com/alexflint/go-scalar v1.0.0
 	github.com/stretchr/testify v1.6.1
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 )
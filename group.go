@@ -0,0 +1,333 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Values of the onMissing tag.
+const (
+	// onMissingDisable marks a nested struct as an optional subsystem.
+	onMissingDisable = "disable"
+	// onMissingEnabled marks the bool field reporting whether the
+	// enclosing optional subsystem is enabled.
+	onMissingEnabled = "enabled"
+)
+
+// group represents an embedded or nested struct whose fields were expanded
+// into specs.
+type group struct {
+	dest    path   // path to the struct field
+	parent  *group // the enclosing group, if any
+	ptr     bool   // the struct field is a pointer to a struct
+	disable bool   // disable the group when none of its variables are set
+	enabled *path  // bool field set to whether the group is enabled
+	prefix  string // prepended to the names of the variables of the group
+	// allocated is set when the pointer was allocated by the caller, which
+	// keeps the group enabled, its fields providing the defaults
+	allocated bool
+}
+
+// structGroup returns the group of the struct field, and true, if the field
+// is expanded into a group. Its enabled marker, and the unexported pointer
+// fields, are not, but are not specs either.
+func structGroup(dest path, field *reflect.StructField, parent *group) (*group, bool, error) {
+	// Optional subsystems and their markers are handled as groups
+	if onMissing, exists := field.Tag.Lookup("onMissing"); exists {
+		sub, err := groupFromTag(dest, field, parent, onMissing)
+
+		return sub, true, err
+	}
+
+	// Pointers to structs are allocated when one of their variables is set,
+	// and left nil otherwise
+	if isStructPtr(field.Type) {
+		if field.PkgPath != "" {
+			return nil, true, nil
+		}
+
+		return &group{dest: dest, parent: parent, ptr: true, disable: true}, true, nil
+	}
+
+	// If this is an embedded or nested struct then recurse into its fields
+	if field.Anonymous && field.Type.Kind() == reflect.Struct || isNestedStruct(field.Type) {
+		return &group{dest: dest, parent: parent}, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// groupPrefix returns the prefix set by the env tag of a struct field
+// expanded into a group, such as DB_ for `env:"prefix:DB_"`.
+func groupPrefix(tag string) (string, error) {
+	var prefix string
+
+	for _, item := range strings.Split(tag, ",") {
+		if item == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(item, "prefix:") {
+			return "", fmt.Errorf("%q: %w", item, ErrorUnrecognizedTag)
+		}
+
+		if prefix = strings.TrimPrefix(item, "prefix:"); prefix == "" {
+			return "", fmt.Errorf("%q: prefix requires a value: %w", item, ErrorMalformedTag)
+		}
+	}
+
+	return prefix, nil
+}
+
+// namePrefix returns the prefixes of the group and of its ancestors, the
+// outermost first.
+func (g *group) namePrefix() string {
+	var prefix string
+	for ; g != nil; g = g.parent {
+		prefix = g.prefix + prefix
+	}
+
+	return prefix
+}
+
+// isStructPtr returns true if t is a pointer to a struct that is not parsed
+// from a single variable, such as *url.URL.
+func isStructPtr(t reflect.Type) bool {
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return false
+	}
+
+	parseable, _, _ := canParse(t)
+
+	return !parseable
+}
+
+// isNestedStruct returns true if t is a struct that is not parsed from a
+// single variable, such as time.Time.
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	parseable, _, _ := canParse(t)
+
+	return !parseable
+}
+
+// groupFromTag handles a field carrying an onMissing tag: either an optional
+// subsystem, which is expanded into a new group, or the enabled marker of
+// the enclosing group.
+func groupFromTag(dest path, field *reflect.StructField, parent *group, onMissing string) (*group, error) {
+	switch onMissing {
+	case onMissingDisable:
+		t := field.Type
+		ptr := t.Kind() == reflect.Ptr
+
+		if ptr {
+			t = t.Elem()
+		}
+
+		if parseable, _, _ := canParse(field.Type); parseable || t.Kind() != reflect.Struct {
+			return nil, ErrorOnMissingNotStruct
+		}
+
+		return &group{dest: dest, parent: parent, ptr: ptr, disable: true}, nil
+	case onMissingEnabled:
+		if field.Type.Kind() != reflect.Bool {
+			return nil, ErrorEnabledNotBool
+		}
+
+		if parent == nil || !parent.disable || parent.enabled != nil {
+			return nil, ErrorEnabledOutsideGroup
+		}
+
+		parent.enabled = &dest
+
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("onMissing:%q: %w", onMissing, ErrorUnrecognizedTag)
+	}
+}
+
+// disabledIn reports whether the group or one of its ancestors is disabled.
+func (g *group) disabledIn(disabled map[*group]bool) bool {
+	for ; g != nil; g = g.parent {
+		if disabled[g] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// contains reports whether the group is g or one of its ancestors.
+func (g *group) contains(other *group) bool {
+	for ; other != nil; other = other.parent {
+		if other == g {
+			return true
+		}
+	}
+
+	return false
+}
+
+// disabledGroups returns the optional groups for which no variable was set.
+func (p *Parser) disabledGroups(wasPresent map[*spec]bool) map[*group]bool {
+	disabled := make(map[*group]bool)
+
+	for _, g := range p.groups {
+		if g.disable && !g.allocated {
+			disabled[g] = true
+		}
+	}
+
+	for spec := range wasPresent {
+		for g := spec.group; g != nil; g = g.parent {
+			delete(disabled, g)
+		}
+	}
+
+	return disabled
+}
+
+// requiredError returns the error of the required variable of spec that is
+// not set, naming the variable that enabled its optional group, if any, and
+// the variable that was likely meant to set it.
+func (p *Parser) requiredError(spec *spec, wasPresent map[*spec]bool, suggest *suggester) error {
+	err := fmt.Errorf("%s: %w", spec.name, p.messages.fieldIsRequired())
+
+	if other := p.enabledBy(spec.group, wasPresent); other != nil {
+		err = fmt.Errorf("%s: %w %s %s", spec.name, p.messages.fieldIsRequired(), p.messages.RequiredWith, other.name)
+	}
+
+	if name := suggest.suggest(spec.name); name != "" {
+		err = fmt.Errorf("%w, %s", err, fmt.Sprintf(p.messages.DidYouMean, name))
+	}
+
+	return err
+}
+
+// enabledBy returns the first variable that was set in the innermost
+// optional group enclosing g, or nil when g is not in an optional group.
+func (p *Parser) enabledBy(g *group, wasPresent map[*spec]bool) *spec {
+	for g != nil && !g.disable {
+		g = g.parent
+	}
+
+	if g == nil {
+		return nil
+	}
+
+	for _, spec := range append(p.specs[:len(p.specs):len(p.specs)], p.compositeSpecs()...) {
+		if wasPresent[spec] && g.contains(spec.group) {
+			return spec
+		}
+	}
+
+	return nil
+}
+
+// markGroups records in the given roots whether each optional group is
+// enabled: disabled pointer groups are reset to nil, and the enabled markers
+// are set accordingly.
+func (p *Parser) markGroups(roots []reflect.Value, disabled map[*group]bool) {
+	for _, g := range p.groups {
+		if !g.disable {
+			continue
+		}
+
+		off := disabled[g]
+
+		if g.ptr && off {
+			if v := resolve(roots, g.dest); v.IsValid() {
+				v.Set(reflect.Zero(v.Type()))
+			}
+
+			continue
+		}
+
+		if g.enabled != nil {
+			if v := resolve(roots, *g.enabled); v.IsValid() {
+				v.SetBool(!off)
+			}
+		}
+	}
+}
+
+// copyRoots makes copies of the destination structs, so that the
+// environment can be processed without touching the originals. The structs
+// of pointer groups are copied as well since they are written through, and
+// the fields of the variables are reset to their values when the parser was
+// created, so that the variables removed since the last parse are too.
+func (p *Parser) copyRoots() []reflect.Value {
+	copies := make([]reflect.Value, len(p.roots))
+
+	for i, root := range p.roots {
+		c := reflect.New(root.Elem().Type())
+		c.Elem().Set(root.Elem())
+		copies[i] = c
+	}
+
+	for _, g := range p.groups {
+		if !g.ptr {
+			continue
+		}
+
+		if v := resolve(copies, g.dest); v.IsValid() && !v.IsNil() {
+			c := reflect.New(v.Type().Elem())
+			c.Elem().Set(v.Elem())
+			v.Set(c)
+		}
+	}
+
+	// the initial values are cloned, pointer fields and big numbers being
+	// parsed in place
+	for _, spec := range append(p.compositeSpecs(), p.specs...) {
+		v := resolve(copies, spec.dest)
+		if !v.IsValid() {
+			continue
+		}
+
+		if spec.defaultValue.IsValid() {
+			v.Set(cloneValue(spec.defaultValue))
+		} else {
+			v.Set(reflect.Zero(v.Type()))
+		}
+	}
+
+	return copies
+}
+
+// applyGroups makes the pointer groups and the enabled markers of the
+// destination structs match those of shadow, allocating or resetting the
+// groups as needed. It must be called with p.mu held, before the spec
+// values are applied.
+func (p *Parser) applyGroups(shadow []reflect.Value) {
+	for _, g := range p.groups {
+		if !g.ptr {
+			continue
+		}
+
+		dst, src := p.val(g.dest), resolve(shadow, g.dest)
+		if !dst.IsValid() || !src.IsValid() || dst.IsNil() == src.IsNil() {
+			continue
+		}
+
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+		} else {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+	}
+
+	for _, g := range p.groups {
+		if g.enabled == nil {
+			continue
+		}
+
+		if dst, src := p.val(*g.enabled), resolve(shadow, *g.enabled); dst.IsValid() && src.IsValid() {
+			dst.Set(src)
+		}
+	}
+}
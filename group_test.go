@@ -0,0 +1,236 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tracing struct {
+	Endpoint string  `env:"tracing_endpoint,required"`
+	Rate     float64 `env:"tracing_rate" default:"0.5"`
+}
+
+func TestOnMissingDisablePtr(t *testing.T) {
+	var envs struct {
+		Tracing *tracing `onMissing:"disable"`
+		Name    string
+	}
+
+	err := parse(envsMap{"name": "foo"}, &envs)
+	require.NoError(t, err)
+	assert.Nil(t, envs.Tracing)
+	assert.Equal(t, "foo", envs.Name)
+
+	err = parse(envsMap{"tracing_endpoint": "localhost:4317"}, &envs)
+	require.NoError(t, err)
+	require.NotNil(t, envs.Tracing)
+	assert.Equal(t, "localhost:4317", envs.Tracing.Endpoint)
+	assert.Equal(t, 0.5, envs.Tracing.Rate)
+}
+
+func TestPtrGroupAllocated(t *testing.T) {
+	type db struct {
+		Host string
+		Port int `default:"5432"`
+	}
+
+	var envs struct {
+		DB    *db
+		Cache *db `env:"prefix:CACHE_"`
+	}
+
+	envs.DB = &db{Host: "localhost"}
+
+	p, err := pparse(envsMap{}, &envs)
+	require.NoError(t, err)
+	require.NotNil(t, envs.DB)
+	assert.Equal(t, db{Host: "localhost", Port: 5432}, *envs.DB)
+	assert.Nil(t, envs.Cache)
+	assert.Contains(t, p.Help(), "host string [default: localhost]")
+
+	require.NoError(t, os.Setenv("host", "db"))
+	require.NoError(t, p.Parse())
+	assert.Equal(t, db{Host: "db", Port: 5432}, *envs.DB)
+}
+
+func TestOnMissingDisableRequired(t *testing.T) {
+	var envs struct {
+		Tracing *tracing `onMissing:"disable"`
+	}
+
+	// once the subsystem is configured its required variables are enforced
+	err := parse(envsMap{"tracing_rate": "1"}, &envs)
+	assert.True(t, errors.Is(err, ErrorFieldIsRequired))
+	assert.EqualError(t, err, "tracing_endpoint: field is required with tracing_rate")
+}
+
+func TestOnMissingDisableRequiredAll(t *testing.T) {
+	type DB struct {
+		Host     string `env:"db_host,required"`
+		User     string `env:"db_user,required"`
+		Password string `env:"db_password,required"`
+		Port     int    `env:"db_port" default:"5432"`
+	}
+
+	var envs struct {
+		DB DB `onMissing:"disable"`
+	}
+
+	require.NoError(t, parse(envsMap{}, &envs))
+	assert.Equal(t, DB{}, envs.DB)
+
+	err := parse(envsMap{"db_port": "5433", "db_user": "app"}, &envs)
+	assert.EqualError(t, err, "db_host: field is required with db_user\n"+
+		"db_password: field is required with db_user")
+
+	err = parse(envsMap{"db_host": "db", "db_user": "app", "db_password": "secret"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, DB{Host: "db", User: "app", Password: "secret", Port: 5432}, envs.DB)
+}
+
+func TestOnMissingDisableEmbedded(t *testing.T) {
+	type Tracing struct {
+		Enabled  bool   `onMissing:"enabled"`
+		Endpoint string `env:"tracing_endpoint,required"`
+	}
+
+	var envs struct {
+		Tracing `onMissing:"disable"`
+	}
+
+	err := parse(envsMap{}, &envs)
+	require.NoError(t, err)
+	assert.False(t, envs.Enabled)
+
+	err = parse(envsMap{"tracing_endpoint": "localhost:4317"}, &envs)
+	require.NoError(t, err)
+	assert.True(t, envs.Enabled)
+	assert.Equal(t, "localhost:4317", envs.Endpoint)
+}
+
+func TestOnMissingDisableReload(t *testing.T) {
+	var envs struct {
+		Tracing *tracing `onMissing:"disable"`
+	}
+
+	p, err := pparse(envsMap{"tracing_endpoint": "a"}, &envs)
+	require.NoError(t, err)
+
+	first := envs.Tracing
+	require.NotNil(t, first)
+
+	_ = os.Setenv("tracing_endpoint", "b")
+	require.NoError(t, p.Reload())
+	assert.Equal(t, "b", envs.Tracing.Endpoint)
+	assert.True(t, first == envs.Tracing, "changes are applied in place")
+
+	_ = os.Setenv("tracing_rate", "xyz")
+	require.Error(t, p.Reload())
+	assert.Equal(t, 0.5, first.Rate, "a failed reload must not write through the pointer")
+	_ = os.Unsetenv("tracing_rate")
+
+	os.Clearenv()
+	require.NoError(t, p.Reload())
+	assert.Nil(t, envs.Tracing)
+
+	_ = os.Setenv("tracing_endpoint", "c")
+	require.NoError(t, p.Reload())
+	require.NotNil(t, envs.Tracing)
+	assert.Equal(t, "c", envs.Tracing.Endpoint)
+	assert.Equal(t, 0.5, envs.Tracing.Rate)
+}
+
+func TestOnMissingErrors(t *testing.T) {
+	var notStruct struct {
+		Foo string `onMissing:"disable"`
+	}
+
+	err := parse(envsMap{}, &notStruct)
+	assert.True(t, errors.Is(err, ErrorOnMissingNotStruct))
+
+	var notBool struct {
+		Tracing struct {
+			Enabled string `onMissing:"enabled"`
+		} `onMissing:"disable"`
+	}
+
+	err = parse(envsMap{}, &notBool)
+	assert.True(t, errors.Is(err, ErrorEnabledNotBool))
+
+	var outside struct {
+		Enabled bool `onMissing:"enabled"`
+	}
+
+	err = parse(envsMap{}, &outside)
+	assert.True(t, errors.Is(err, ErrorEnabledOutsideGroup))
+
+	var unknown struct {
+		Tracing *tracing `onMissing:"ignore"`
+	}
+
+	err = parse(envsMap{}, &unknown)
+	assert.True(t, errors.Is(err, ErrorUnrecognizedTag))
+}
+
+func TestGroupPrefix(t *testing.T) {
+	type Pool struct {
+		Size int `default:"4"`
+	}
+
+	type DB struct {
+		Host string `env:"HOST,required"`
+		Pool Pool   `env:"prefix:POOL_"`
+	}
+
+	type Cache struct {
+		TTL int `env:"TTL,alias:EXPIRY"`
+	}
+
+	var envs struct {
+		Primary DB  `env:"prefix:PRIMARY_"`
+		Replica *DB `env:"prefix:REPLICA_"`
+		Cache   `env:"prefix:CACHE_"`
+	}
+
+	p, err := pparse(envsMap{
+		"PRIMARY_HOST":      "primary",
+		"PRIMARY_POOL_size": "8",
+		"REPLICA_HOST":      "replica",
+		"CACHE_EXPIRY":      "60",
+	}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "primary", envs.Primary.Host)
+	assert.Equal(t, 8, envs.Primary.Pool.Size)
+	require.NotNil(t, envs.Replica)
+	assert.Equal(t, "replica", envs.Replica.Host)
+	assert.Equal(t, 4, envs.Replica.Pool.Size)
+	assert.Equal(t, 60, envs.Cache.TTL)
+	assert.Contains(t, p.Help(), "REPLICA_POOL_size int")
+	assert.Contains(t, p.Help(), "aliases: CACHE_EXPIRY")
+
+	err = parse(envsMap{}, &struct {
+		Primary DB `env:"prefix:PRIMARY_"`
+	}{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "PRIMARY_HOST")
+}
+
+func TestGroupPrefixMalformed(t *testing.T) {
+	type DB struct {
+		Host string
+	}
+
+	_, err := NewParser(Config{}, &struct {
+		DB DB `env:"prefix:"`
+	}{})
+	assert.True(t, errors.Is(err, ErrorMalformedTag))
+
+	_, err = NewParser(Config{}, &struct {
+		DB DB `env:"required"`
+	}{})
+	assert.True(t, errors.Is(err, ErrorUnrecognizedTag))
+}
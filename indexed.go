@@ -0,0 +1,183 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// indexed is a slice of structs whose elements are read from numbered
+// variables, such as backends_0_host and backends_1_host for the Host field
+// of the elements of a slice named backends.
+type indexed struct {
+	spec *spec   // the slice field, named after the prefix of the variables
+	elem *Parser // parses a single element, with names relative to the prefix
+}
+
+// indexedElem returns the struct type of the elements of t if t is a slice
+// of structs, or of pointers to structs, that cannot be parsed as a list.
+func indexedElem(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Slice {
+		return nil, false
+	}
+
+	if parseable, _, _ := canParse(t); parseable {
+		return nil, false
+	}
+
+	elem := t.Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+
+	return elem, elem.Kind() == reflect.Struct
+}
+
+// newIndexed returns the indexed slice of the option, whose elements are
+// parsed with the same configuration as the enclosing struct, except for
+// the prefix which the name of the option already has.
+func newIndexed(config Config, sp *spec) (*indexed, error) {
+	config.Prefix = ""
+
+	elem, err := NewParser(config, reflect.New(sp.elem).Interface())
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", sp.dest, err)
+	}
+
+	return &indexed{spec: sp, elem: elem}, nil
+}
+
+// prefixed returns a copy of the parser whose variables are prefixed, for
+// parsing an element of an indexed slice.
+func (p *Parser) prefixed(prefix string) *Parser {
+	c := &Parser{groups: p.groups, config: p.config, messages: p.messages}
+	copies := make(map[*spec]*spec, len(p.specs))
+
+	for _, spec := range p.specs {
+		copies[spec] = spec.prefixed(prefix)
+		c.specs = append(c.specs, copies[spec])
+	}
+
+	for _, spec := range p.templated {
+		c.templated = append(c.templated, copies[spec])
+	}
+
+	for _, x := range p.indexed {
+		c.indexed = append(c.indexed, &indexed{spec: x.spec.prefixed(prefix), elem: x.elem})
+	}
+
+	for _, spec := range p.catchAll {
+		c.catchAll = append(c.catchAll, spec.prefixed(prefix))
+	}
+
+	return c
+}
+
+// prefixed returns a copy of the option whose names are prefixed.
+func (s *spec) prefixed(prefix string) *spec {
+	c := *s
+	c.name = prefix + s.name
+	c.aliases = prefixAll(prefix, s.aliases)
+	c.defaultEnv = prefixAll(prefix, s.defaultEnv)
+
+	return &c
+}
+
+func prefixAll(prefix string, names []string) []string {
+	if names == nil {
+		return nil
+	}
+
+	prefixed := make([]string, len(names))
+	for i, name := range names {
+		prefixed[i] = prefix + name
+	}
+
+	return prefixed
+}
+
+// isSet reports whether any variable of the parser is set in the sources.
+func (p *Parser) isSet(sources []Source) bool {
+	for _, spec := range p.specs {
+		if _, _, found := spec.lookup(sources); found {
+			return true
+		}
+	}
+
+	for _, x := range p.indexed {
+		if x.elem.prefixed(x.spec.name + "_0_").isSet(sources) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// captureIndexed fills the indexed slices with an element for each index
+// having a variable set, starting at 0 and stopping at the first gap.
+func (p *Parser) captureIndexed(roots []reflect.Value, wasPresent map[*spec]bool) Errors {
+	var errs Errors
+
+	sources := p.sources()
+
+	for _, x := range p.indexed {
+		field := qualifiedPath(p.roots[x.spec.dest.root].Type().Elem(), x.spec.dest)
+		slice := reflect.MakeSlice(x.spec.typ, 0, 0)
+
+		for i := 0; ; i++ {
+			elem := x.elem.prefixed(fmt.Sprintf("%s_%d_", x.spec.name, i))
+			if !elem.isSet(sources) {
+				break
+			}
+
+			if err := p.config.checkElements(p.messages, x.spec.name, i+1); err != nil {
+				errs = append(errs, p.fieldError(x.spec, x.spec.name, err))
+
+				break
+			}
+
+			elem.config.Sources = sources
+			elem.roots = []reflect.Value{reflect.New(x.spec.elem)}
+
+			for _, fe := range elem.processLoaded(elem.roots, make(map[*spec]string)) {
+				fe.Field = fmt.Sprintf("%s[%d].%s", field, i, strings.TrimPrefix(fe.Field, x.spec.elem.Name()+"."))
+				errs = append(errs, fe)
+			}
+
+			p.scrubs = append(p.scrubs, elem.scrubs...)
+
+			v := elem.roots[0]
+			if x.spec.typ.Elem().Kind() != reflect.Ptr {
+				v = v.Elem()
+			}
+
+			slice = reflect.Append(slice, v)
+		}
+
+		if slice.Len() > 0 {
+			wasPresent[x.spec] = true
+
+			if err := x.spec.checkItems(p.messages, x.spec.name, slice.Len()); err != nil {
+				errs = append(errs, p.fieldError(x.spec, x.spec.name, err))
+			}
+
+			resolveAlloc(roots, x.spec.dest).Set(slice)
+		}
+	}
+
+	return errs
+}
+
+// indexedOptions returns the options of the elements of the indexed
+// slices, with <n> in place of the index, as displayed in help.
+func (p *Parser) indexedOptions() []*spec {
+	var options []*spec
+
+	for _, x := range p.indexed {
+		elem := x.elem.prefixed(x.spec.name + "_<n>_")
+		options = append(options, elem.specs...)
+		options = append(options, elem.indexedOptions()...)
+	}
+
+	return options
+}
@@ -0,0 +1,135 @@
+package env
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type backend struct {
+	Host string `env:"required"`
+	Port int    `default:"80"`
+}
+
+func TestIndexed(t *testing.T) {
+	var envs struct {
+		Backends []backend
+		Workers  []*backend `env:"WORKERS"`
+	}
+
+	err := parse(envsMap{
+		"backends_0_host": "a",
+		"backends_1_host": "b",
+		"backends_1_port": "8080",
+		"backends_3_host": "ignored after the gap",
+		"WORKERS_0_host":  "c",
+	}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, []backend{{Host: "a", Port: 80}, {Host: "b", Port: 8080}}, envs.Backends)
+	require.Len(t, envs.Workers, 1)
+	assert.Equal(t, backend{Host: "c", Port: 80}, *envs.Workers[0])
+}
+
+func TestIndexedUnset(t *testing.T) {
+	envs := struct {
+		Backends []backend
+	}{
+		Backends: []backend{{Host: "default"}},
+	}
+
+	err := parse(envsMap{}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, []backend{{Host: "default"}}, envs.Backends)
+
+	var required struct {
+		Backends []backend `env:"required"`
+	}
+
+	err = parse(envsMap{}, &required)
+	assert.True(t, errors.Is(err, ErrorFieldIsRequired))
+}
+
+func TestIndexedErrors(t *testing.T) {
+	type config struct {
+		Backends []backend
+	}
+
+	var envs config
+
+	err := parse(envsMap{"backends_0_host": "a", "backends_1_port": "x"}, &envs)
+
+	var errs Errors
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs, 2)
+	assert.Equal(t, "config.Backends[1].Port", errs[0].Field)
+	assert.Equal(t, "backends_1_port", errs[0].Name)
+	assert.Equal(t, "config.Backends[1].Host", errs[1].Field)
+	assert.Equal(t, "backends_1_host", errs[1].Name)
+	assert.True(t, errors.Is(err, ErrorFieldIsRequired))
+}
+
+func TestIndexedNested(t *testing.T) {
+	var envs struct {
+		Pools []struct {
+			Name    string
+			Members []backend
+		}
+	}
+
+	err := parse(envsMap{
+		"pools_0_name":           "web",
+		"pools_0_members_0_host": "a",
+		"pools_0_members_1_host": "b",
+		"pools_1_members_0_host": "c",
+	}, &envs)
+	require.NoError(t, err)
+	require.Len(t, envs.Pools, 2)
+	assert.Equal(t, "web", envs.Pools[0].Name)
+	assert.Equal(t, []backend{{Host: "a", Port: 80}, {Host: "b", Port: 80}}, envs.Pools[0].Members)
+	assert.Equal(t, []backend{{Host: "c", Port: 80}}, envs.Pools[1].Members)
+}
+
+func TestIndexedDefault(t *testing.T) {
+	var envs struct {
+		Backends []backend `default:"a"`
+	}
+
+	_, err := NewParser(Config{}, &envs)
+	assert.True(t, errors.Is(err, ErrorDefaultValueForSlice))
+}
+
+func TestIndexedHelp(t *testing.T) {
+	var envs struct {
+		Backends []backend
+	}
+
+	p, err := NewParser(Config{}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "Environments:\n  backends_<n>_host string (required)\n  backends_<n>_port int [default: 80]\n", p.Help())
+}
+
+func TestIndexedReload(t *testing.T) {
+	var envs struct {
+		Backends []backend
+	}
+
+	src := MapSource{"backends_0_host": "a"}
+
+	p, err := NewParser(Config{Sources: []Source{src}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+
+	var changed []string
+
+	p.OnChange(func(field string, old, new interface{}) {
+		changed = append(changed, field)
+	})
+
+	src["backends_1_host"] = "b"
+
+	require.NoError(t, p.Reload())
+	assert.Equal(t, []string{"backends"}, changed)
+	assert.Equal(t, []backend{{Host: "a", Port: 80}, {Host: "b", Port: 80}}, envs.Backends)
+}
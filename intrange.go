@@ -0,0 +1,57 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// intRange returns the range of values of a sized integer type, or of the
+// elements of a slice or pointer of it, such as "0–255" for uint8.
+func intRange(t reflect.Type) (string, string, bool) {
+	if name := typeName(t); name != "int" && name != "uint" {
+		return "", "", false
+	}
+
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+
+	var lo, hi int64
+
+	switch t.Kind() {
+	case reflect.Int8:
+		lo, hi = math.MinInt8, math.MaxInt8
+	case reflect.Int16:
+		lo, hi = math.MinInt16, math.MaxInt16
+	case reflect.Int32:
+		lo, hi = math.MinInt32, math.MaxInt32
+	case reflect.Uint8:
+		hi = math.MaxUint8
+	case reflect.Uint16:
+		hi = math.MaxUint16
+	case reflect.Uint32:
+		hi = math.MaxUint32
+	default:
+		return "", "", false
+	}
+
+	return t.Kind().String(), fmt.Sprintf("%d–%d", lo, hi), true
+}
+
+// rangeError adds the range of valid values to out of range errors of
+// sized integer options.
+func (s *spec) rangeError(m Messages, err error) error {
+	if !errors.Is(err, strconv.ErrRange) {
+		return err
+	}
+
+	kind, rng, ok := intRange(s.typ)
+	if !ok {
+		return err
+	}
+
+	return fmt.Errorf("%w (%s)", err, fmt.Sprintf(m.OutOfRange, kind, rng))
+}
@@ -0,0 +1,52 @@
+package env
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntRangeHelp(t *testing.T) {
+	var envs struct {
+		Level   uint8
+		Offset  *int16
+		Ports   []uint16
+		Count   int
+		Verbose int8 `levels:"quiet,loud"`
+	}
+
+	p, err := NewParser(Config{}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, `Environments:
+  level uint [uint8: 0–255]
+  offset int [int16: -32768–32767]
+  ports uint,... [uint16: 0–65535]
+  count int
+  verbose int [levels: quiet, loud]
+`, p.Help())
+}
+
+func TestIntRangeErrors(t *testing.T) {
+	var envs struct {
+		Level uint8
+		Ports []int8
+		Count int
+	}
+
+	err := parse(envsMap{"level": "300", "ports": "1,200", "count": "99999999999999999999"}, &envs)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, strconv.ErrRange))
+
+	var errs Errors
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs, 3)
+	assert.EqualError(t, errs[0],
+		`error processing environment variable level: strconv.ParseUint: parsing "300": value out of range (uint8 range is 0–255)`)
+	assert.Equal(t, "expected a value of type uint8 in 0–255", errs[0].Hint)
+	assert.Contains(t, errs[1].Error(), "(int8 range is -128–127)")
+	assert.Equal(t, "expected a comma-separated list of int8 values in -128–127", errs[1].Hint)
+	assert.NotContains(t, errs[2].Error(), "range is")
+}
@@ -0,0 +1,60 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// k8sSecretPlaceholder is the name of the Secret holding the secret
+// variables when DocOptions.SecretName is not set.
+const k8sSecretPlaceholder = "<secret-name>"
+
+// WriteK8sEnv writes the env block of a Kubernetes container spec setting
+// the variables to their defaults, with their help as comments. Secret
+// variables are read from the keys of the same name of a Secret, see
+// DocOptions.SecretName, so that the values never appear in manifests.
+func (p *Parser) WriteK8sEnv(w io.Writer, opts ...DocOptions) error {
+	specs, o := p.docSpecs(opts)
+
+	secretName := o.SecretName
+	if secretName == "" {
+		secretName = k8sSecretPlaceholder
+	}
+
+	var b strings.Builder
+
+	b.WriteString(o.Header)
+	b.WriteString("env:\n")
+
+	for _, spec := range specs {
+		comment := spec.help
+		if spec.required {
+			comment = strings.TrimSpace(comment + " (required)")
+		}
+
+		if comment != "" {
+			fmt.Fprintf(&b, "  # %s\n", strings.Replace(comment, "\n", "\n  # ", -1))
+		}
+
+		fmt.Fprintf(&b, "  - name: %s\n", strconv.Quote(spec.name))
+
+		if spec.secret || spec.mask {
+			b.WriteString("    valueFrom:\n")
+			b.WriteString("      secretKeyRef:\n")
+			fmt.Fprintf(&b, "        name: %s\n", strconv.Quote(secretName))
+			fmt.Fprintf(&b, "        key: %s\n", strconv.Quote(spec.name))
+
+			continue
+		}
+
+		fmt.Fprintf(&b, "    value: %s\n", strconv.Quote(spec.defaultVal))
+	}
+
+	b.WriteString(o.Footer)
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
@@ -0,0 +1,60 @@
+package env_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Alex616/go-env"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteK8sEnv(t *testing.T) {
+	expected := `env:
+  # number of workers | threads (required)
+  - name: "WORKERS"
+    value: ""
+  - name: "NAME"
+    value: "foo"
+  # database host (required)
+  - name: "DB_HOST"
+    value: ""
+  - name: "DB_PASSWORD"
+    valueFrom:
+      secretKeyRef:
+        name: "<secret-name>"
+        key: "DB_PASSWORD"
+`
+
+	p, err := env.NewParser(env.Config{}, &DocsEnvs{})
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+
+	require.NoError(t, p.WriteK8sEnv(&b))
+	assert.Equal(t, expected, b.String())
+}
+
+func TestWriteK8sEnvOptions(t *testing.T) {
+	var envs struct {
+		Greeting string `env:"GREETING" default:"say \"hi\"\n"`
+		Token    string `env:"TOKEN,mask"`
+	}
+
+	p, err := env.NewParser(env.Config{}, &envs)
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+
+	require.NoError(t, p.WriteK8sEnv(&b, env.DocOptions{SecretName: "app", Header: "# generated\n"}))
+	assert.Equal(t, `# generated
+env:
+  - name: "GREETING"
+    value: "say \"hi\"\n"
+  - name: "TOKEN"
+    valueFrom:
+      secretKeyRef:
+        name: "app"
+        key: "TOKEN"
+`, b.String())
+}
@@ -0,0 +1,72 @@
+package env
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// KV is a key=value pair of a KVList.
+type KV struct {
+	Key   string
+	Value string
+}
+
+// KVList is a list of key=value pairs parsed from a comma-separated value
+// such as "a=1,b=2", keeping the pairs in order, duplicates included, so
+// that options can be forwarded verbatim to libraries that care about
+// their order. Pairs containing commas are quoted as in CSV.
+type KVList []KV
+
+// UnmarshalEnv parses the pairs of value.
+func (l *KVList) UnmarshalEnv(value string) error {
+	if value == "" {
+		*l = nil
+
+		return nil
+	}
+
+	items, err := csv.NewReader(strings.NewReader(value)).Read()
+	if err != nil {
+		return err
+	}
+
+	list := make(KVList, 0, len(items))
+
+	for _, item := range items {
+		i := strings.IndexByte(item, '=')
+		if i < 0 {
+			return fmt.Errorf("%q: %w", item, ErrorMalformedPair)
+		}
+
+		list = append(list, KV{Key: item[:i], Value: item[i+1:]})
+	}
+
+	*l = list
+
+	return nil
+}
+
+// MarshalText formats the list the way it is parsed.
+func (l KVList) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// String formats the list the way it is parsed.
+func (l KVList) String() string {
+	if len(l) == 0 {
+		return ""
+	}
+
+	return formatCSV(l.Strings())
+}
+
+// Strings returns the pairs in the key=value form.
+func (l KVList) Strings() []string {
+	items := make([]string, len(l))
+	for i, kv := range l {
+		items[i] = kv.Key + "=" + kv.Value
+	}
+
+	return items
+}
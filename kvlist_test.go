@@ -0,0 +1,47 @@
+package env
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKVList(t *testing.T) {
+	var envs struct {
+		Options  KVList
+		Defaults KVList `default:"b=2,a=1"`
+		Empty    KVList
+	}
+
+	p, err := pparse(envsMap{"options": `x=1,y=a=b,x=2,"z=c,d"`, "empty": ""}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, KVList{{"x", "1"}, {"y", "a=b"}, {"x", "2"}, {"z", "c,d"}}, envs.Options)
+	assert.Equal(t, KVList{{"b", "2"}, {"a", "1"}}, envs.Defaults)
+	assert.Nil(t, envs.Empty)
+	assert.Equal(t, []string{"x=1", "y=a=b", "x=2", "z=c,d"}, envs.Options.Strings())
+	assert.Equal(t, `x=1,y=a=b,x=2,"z=c,d"`, p.Snapshot()["options"])
+}
+
+func TestKVListMalformed(t *testing.T) {
+	var envs struct {
+		Options KVList
+	}
+
+	err := parse(envsMap{"options": "x=1,y"}, &envs)
+	assert.True(t, errors.Is(err, ErrorMalformedPair))
+	assert.Contains(t, err.Error(), `"y"`)
+}
+
+func TestKVListHelp(t *testing.T) {
+	envs := struct {
+		Options KVList
+	}{
+		Options: KVList{{"a", "1"}, {"b", "2"}},
+	}
+
+	p, err := NewParser(Config{}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "Environments:\n  options KVList [default: a=1,b=2]\n", p.Help())
+}
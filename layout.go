@@ -0,0 +1,125 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// timeType is the type of time.Time.
+var timeType = reflect.TypeOf(time.Time{}) // nolint:gochecknoglobals
+
+// namedLayouts are the layouts of the time package that the layout tag can
+// name instead of spelling them out.
+var namedLayouts = map[string]string{ // nolint:gochecknoglobals
+	"ANSIC":       time.ANSIC,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"DateTime":    "2006-01-02 15:04:05",
+	"DateOnly":    "2006-01-02",
+	"TimeOnly":    "15:04:05",
+}
+
+// isTime returns true if t is time.Time, a pointer to it, or a slice of
+// either.
+func isTime(t reflect.Type) bool {
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t == timeType
+}
+
+// lookAtLayout reads the layout tag of a time field, either a layout such
+// as 2006-01-02 or the name of a layout of the time package such as
+// RFC1123.
+func (s *spec) lookAtLayout(field *reflect.StructField) error {
+	layout, exists := field.Tag.Lookup("layout")
+	if !exists {
+		return nil
+	}
+
+	if !isTime(field.Type) {
+		return ErrorLayoutNotTime
+	}
+
+	if layout == "" {
+		return fmt.Errorf("layout: %w", ErrorMalformedTag)
+	}
+
+	if named, ok := namedLayouts[layout]; ok {
+		layout = named
+	}
+
+	s.layout = layout
+
+	return nil
+}
+
+// relayout returns the time in value, written with the layout of the
+// option, in the RFC 3339 form that time.Time parses. Times without a time
+// zone are in UTC.
+func (s *spec) relayout(value string) (string, error) {
+	if s.layout == "" {
+		return value, nil
+	}
+
+	t, err := time.Parse(s.layout, value)
+	if err != nil {
+		return "", err
+	}
+
+	return t.Format(time.RFC3339Nano), nil
+}
+
+// relayoutAll is relayout for the elements of a list.
+func (s *spec) relayoutAll(values []string) ([]string, error) {
+	if s.layout == "" {
+		return values, nil
+	}
+
+	relaid := make([]string, len(values))
+
+	for i, value := range values {
+		v, err := s.relayout(value)
+		if err != nil {
+			return nil, err
+		}
+
+		relaid[i] = v
+	}
+
+	return relaid, nil
+}
+
+// formatTime formats the time, pointer to a time or slice of them v with
+// layout.
+func formatTime(v reflect.Value, layout string) string {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return ""
+		}
+
+		return formatTime(v.Elem(), layout)
+	case reflect.Slice:
+		values := make([]string, v.Len())
+		for i := range values {
+			values[i] = formatTime(v.Index(i), layout)
+		}
+
+		return formatCSV(values)
+	}
+
+	return v.Interface().(time.Time).Format(layout)
+}
@@ -0,0 +1,77 @@
+package env
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTime(t *testing.T) {
+	var envs struct {
+		Start time.Time
+		End   *time.Time
+	}
+
+	err := parse(envsMap{"start": "2024-03-01T10:00:00Z", "end": "2024-03-02T10:00:00+02:00"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), envs.Start)
+	require.NotNil(t, envs.End)
+	assert.True(t, envs.End.Equal(time.Date(2024, 3, 2, 8, 0, 0, 0, time.UTC)))
+}
+
+func TestTimeLayout(t *testing.T) {
+	var envs struct {
+		Release  time.Time   `layout:"2006-01-02"`
+		Holidays []time.Time `layout:"DateOnly" default:"2024-12-25,2025-01-01"`
+		Expires  *time.Time  `layout:"RFC1123"`
+		Cutoff   time.Time   `layout:"15:04" default:"17:30"`
+	}
+
+	p, err := pparse(envsMap{"release": "2024-03-01", "expires": "Mon, 02 Jan 2006 15:04:05 MST"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), envs.Release)
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, envs.Holidays)
+	require.NotNil(t, envs.Expires)
+	assert.Equal(t, 2006, envs.Expires.Year())
+	assert.Equal(t, 17, envs.Cutoff.Hour())
+	assert.Equal(t, "2024-03-01", p.Snapshot()["release"])
+	assert.Equal(t, "2024-12-25,2025-01-01", p.Snapshot()["holidays"])
+
+	err = parse(envsMap{"release": "2024-03-01T10:00:00Z"}, &envs)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error processing environment variable release")
+}
+
+func TestTimeLayoutInitialValue(t *testing.T) {
+	envs := struct {
+		Release time.Time `layout:"2006-01-02"`
+	}{Release: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+
+	p, err := NewParser(Config{}, &envs)
+	require.NoError(t, err)
+	assert.Contains(t, p.Help(), "[default: 2024-03-01]")
+}
+
+func TestTimeLayoutErrors(t *testing.T) {
+	_, err := NewParser(Config{}, &struct {
+		Name string `layout:"2006"`
+	}{})
+	assert.True(t, errors.Is(err, ErrorLayoutNotTime))
+
+	_, err = NewParser(Config{}, &struct {
+		Release time.Time `layout:""`
+	}{})
+	assert.True(t, errors.Is(err, ErrorMalformedTag))
+
+	err = parse(envsMap{}, &struct {
+		Release time.Time `layout:"2006-01-02" default:"tomorrow"`
+	}{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error processing default value for release")
+}
@@ -0,0 +1,81 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// checkLength returns an error if the value of the variable is longer than
+// MaxValueLength.
+func (c Config) checkLength(m Messages, name, value string) error {
+	if c.MaxValueLength > 0 && len(value) > c.MaxValueLength {
+		return m.variable(name, limitError(ErrorValueTooLong, m.ValueTooLong, len(value), c.MaxValueLength))
+	}
+
+	return nil
+}
+
+// checkElements returns an error if a variable has more elements than
+// MaxElements.
+func (c Config) checkElements(m Messages, name string, n int) error {
+	if err := c.checkEntries(m, n); err != nil {
+		return m.variable(name, err)
+	}
+
+	return nil
+}
+
+// checkEntries is checkElements for the entries of maps, whose errors are
+// wrapped by the caller.
+func (c Config) checkEntries(m Messages, n int) error {
+	if c.MaxElements > 0 && n > c.MaxElements {
+		return limitError(ErrorTooManyElements, m.MoreElements, c.MaxElements)
+	}
+
+	return nil
+}
+
+// checkItems returns an error if a list of the variable has fewer elements
+// than the minitems of its tag, or more than its maxitems.
+func (s *spec) checkItems(m Messages, name string, n int) error {
+	if n < s.minItems {
+		return m.variable(name, limitError(ErrorTooFewElements, m.TooFewElements, n, s.minItems))
+	}
+
+	if s.maxItems > 0 && n > s.maxItems {
+		return m.variable(name, limitError(ErrorTooManyElements, m.TooManyElements, n, s.maxItems))
+	}
+
+	return nil
+}
+
+// checkUnsetItems is checkItems for a variable that is not set, counting
+// the elements of its default.
+func (s *spec) checkUnsetItems(m Messages, roots []reflect.Value) error {
+	if s.minItems == 0 && s.maxItems == 0 {
+		return nil
+	}
+
+	v := resolve(roots, s.dest)
+	if !v.IsValid() {
+		return nil
+	}
+
+	return s.checkItems(m, s.name, v.Len())
+}
+
+// itemsRange describes the number of elements allowed by the minitems and
+// maxitems of the tag, such as "1 to 10" or "at least 1", as displayed in
+// help.
+func (s *spec) itemsRange(m Messages) string {
+	switch {
+	case s.minItems > 0 && s.maxItems > 0:
+		return fmt.Sprintf(m.ItemsRange, s.minItems, s.maxItems)
+	case s.minItems > 0:
+		return fmt.Sprintf(m.ItemsAtLeast, s.minItems)
+	case s.maxItems > 0:
+		return fmt.Sprintf(m.ItemsAtMost, s.maxItems)
+	}
+
+	return ""
+}
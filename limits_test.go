@@ -0,0 +1,164 @@
+package env
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxValueLength(t *testing.T) {
+	var envs struct {
+		Name  string
+		Token string `env:"secret"`
+	}
+
+	src := MapSource{"name": "abcd", "token": strings.Repeat("x", 5)}
+
+	p, err := NewParser(Config{Sources: []Source{src}, MaxValueLength: 4}, &envs)
+	require.NoError(t, err)
+
+	err = p.Parse()
+
+	var errs Errors
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs, 1)
+	assert.Equal(t, "token", errs[0].Name)
+	assert.Equal(t, "use a shorter value", errs[0].Hint)
+	assert.True(t, errors.Is(err, ErrorValueTooLong))
+	assert.NotContains(t, err.Error(), "xxxxx")
+	assert.Empty(t, envs.Name)
+}
+
+func TestMaxElementsMap(t *testing.T) {
+	var envs struct {
+		Limits  map[string]int
+		Weights map[string]int `default:"a=1,b=2,c=3"`
+	}
+
+	src := MapSource{"limits": "a=1,b=2,c=3,d=4"}
+
+	p, err := NewParser(Config{Sources: []Source{src}, MaxElements: 2}, &envs)
+	require.NoError(t, err)
+
+	err = p.Parse()
+
+	var errs Errors
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs, 2)
+	assert.Equal(t, "limits", errs[0].Name)
+	assert.Equal(t, "use fewer elements", errs[0].Hint)
+	assert.True(t, errors.Is(errs[0], ErrorTooManyElements))
+	assert.Equal(t, "weights", errs[1].Name)
+	assert.True(t, errors.Is(errs[1], ErrorTooManyElements))
+	assert.Nil(t, envs.Limits)
+
+	require.NoError(t, p.ParseMap(map[string]string{"limits": "a=1,b=2", "weights": "c=3"}))
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, envs.Limits)
+}
+
+func TestMaxElements(t *testing.T) {
+	var envs struct {
+		Ports    []int
+		Backends []backend
+	}
+
+	src := MapSource{
+		"ports":           "1,2,3",
+		"backends_0_host": "a",
+		"backends_1_host": "b",
+		"backends_2_host": "c",
+	}
+
+	p, err := NewParser(Config{Sources: []Source{src}, MaxElements: 2}, &envs)
+	require.NoError(t, err)
+
+	err = p.Parse()
+
+	var errs Errors
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs, 2)
+	assert.Equal(t, "ports", errs[0].Name)
+	assert.Equal(t, "backends", errs[1].Name)
+	assert.Equal(t, "use fewer elements", errs[1].Hint)
+	assert.True(t, errors.Is(err, ErrorTooManyElements))
+
+	src["ports"] = "1,2"
+	delete(src, "backends_2_host")
+
+	require.NoError(t, p.Parse())
+	assert.Equal(t, []int{1, 2}, envs.Ports)
+	assert.Len(t, envs.Backends, 2)
+}
+
+func TestItems(t *testing.T) {
+	var envs struct {
+		Peers    []string  `env:"peers,minitems:1,maxitems:3"`
+		Backends []backend `env:"maxitems:1"`
+		Tags     []string  `env:"minitems:2"`
+	}
+
+	src := MapSource{"peers": "a,b,c,d", "backends_0_host": "a", "backends_1_host": "b"}
+
+	p, err := NewParser(Config{Sources: []Source{src}}, &envs)
+	require.NoError(t, err)
+
+	err = p.Parse()
+
+	var errs Errors
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs, 3)
+	assert.Equal(t, "peers", errs[0].Name)
+	assert.True(t, errors.Is(errs[0], ErrorTooManyElements))
+	assert.Equal(t, "backends", errs[1].Name)
+	assert.True(t, errors.Is(errs[1], ErrorTooManyElements))
+	assert.Equal(t, "tags", errs[2].Name)
+	assert.True(t, errors.Is(errs[2], ErrorTooFewElements))
+	assert.Equal(t, "use more elements", errs[2].Hint)
+
+	envs.Tags = []string{"x", "y"}
+	src["peers"] = "a"
+	delete(src, "backends_1_host")
+
+	p, err = NewParser(Config{Sources: []Source{src}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, []string{"a"}, envs.Peers)
+	assert.Equal(t, []string{"x", "y"}, envs.Tags)
+}
+
+func TestItemsTag(t *testing.T) {
+	var scalar struct {
+		Peer string `env:"minitems:1"`
+	}
+
+	_, err := NewParser(Config{}, &scalar)
+	assert.True(t, errors.Is(err, ErrorItemsNotSlice))
+
+	var inverted struct {
+		Peers []string `env:"minitems:3,maxitems:1"`
+	}
+
+	_, err = NewParser(Config{}, &inverted)
+	assert.True(t, errors.Is(err, ErrorMalformedTag))
+
+	var malformed struct {
+		Peers []string `env:"minitems:x"`
+	}
+
+	_, err = NewParser(Config{}, &malformed)
+	assert.True(t, errors.Is(err, ErrorMalformedTag))
+}
+
+func TestItemsHelp(t *testing.T) {
+	var envs struct {
+		Peers []string `env:"peers,minitems:1,maxitems:10"`
+		Tags  []string `env:"maxitems:2"`
+	}
+
+	p, err := NewParser(Config{}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "Environments:\n  peers string,... [items: 1 to 10]\n  tags string,... [items: at most 2]\n", p.Help())
+}
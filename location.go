@@ -0,0 +1,42 @@
+package env
+
+import (
+	"reflect"
+	"time"
+)
+
+var locationType = reflect.TypeOf(time.Location{}) // nolint:gochecknoglobals
+
+// isLocation returns true if t is time.Location or a pointer to it, which
+// are loaded with time.LoadLocation.
+func isLocation(t reflect.Type) bool {
+	return t == locationType || t == reflect.PtrTo(locationType)
+}
+
+// parseLocation loads the location with the given name, such as
+// "Europe/Paris", "UTC" or "Local", into dest.
+func parseLocation(dest reflect.Value, name string) error {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return err
+	}
+
+	if dest.Kind() == reflect.Ptr {
+		dest.Set(reflect.ValueOf(loc))
+	} else {
+		dest.Set(reflect.ValueOf(loc).Elem())
+	}
+
+	return nil
+}
+
+// formatLocation returns the name of the location held by v.
+func formatLocation(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		return v.Interface().(*time.Location).String()
+	}
+
+	loc := v.Interface().(time.Location)
+
+	return loc.String()
+}
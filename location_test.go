@@ -0,0 +1,55 @@
+package env
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocation(t *testing.T) {
+	var envs struct {
+		TZ      *time.Location `env:"TZ"`
+		Zones   []*time.Location
+		Default *time.Location `default:"UTC"`
+	}
+
+	p, err := pparse(envsMap{"TZ": "Europe/Paris", "zones": "UTC,America/New_York"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Paris", envs.TZ.String())
+	require.Len(t, envs.Zones, 2)
+	assert.Equal(t, "America/New_York", envs.Zones[1].String())
+	assert.Equal(t, time.UTC, envs.Default)
+	assert.Equal(t, map[string]string{"TZ": "Europe/Paris", "zones": "UTC,America/New_York", "default": "UTC"}, p.Snapshot())
+}
+
+func TestLocationInvalid(t *testing.T) {
+	var envs struct {
+		TZ *time.Location `env:"TZ"`
+	}
+
+	err := parse(envsMap{"TZ": "Mars/Olympus_Mons"}, &envs)
+
+	var errs Errors
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs, 1)
+	assert.Equal(t, "TZ", errs[0].Name)
+	assert.Equal(t, "expected a value of type Location", errs[0].Hint)
+}
+
+func TestLocationHelp(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	envs := struct {
+		TZ *time.Location `env:"TZ"`
+	}{
+		TZ: loc,
+	}
+
+	p, err := NewParser(Config{}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "Environments:\n  TZ Location [default: Asia/Tokyo]\n", p.Help())
+}
@@ -0,0 +1,30 @@
+package env
+
+// ValueLogger is implemented by the structured loggers taking a message
+// followed by alternating keys and values, such as *zap.SugaredLogger. See
+// LoggerFunc to adapt other loggers.
+type ValueLogger interface {
+	Infow(msg string, keysAndValues ...interface{})
+}
+
+// LoggerFunc adapts a logging function, such as the Info method of a
+// *slog.Logger, to a ValueLogger.
+type LoggerFunc func(msg string, keysAndValues ...interface{})
+
+// Infow calls f(msg, keysAndValues...).
+func (f LoggerFunc) Infow(msg string, keysAndValues ...interface{}) {
+	f(msg, keysAndValues...)
+}
+
+// logValuesMsg is the message of the records of LogValuesTo.
+const logValuesMsg = "config"
+
+// LogValuesTo logs the effective configuration, as returned by Values, with
+// one record per variable holding its name, field, source and redacted
+// value, so that every service logs its configuration the same way at
+// startup.
+func (p *Parser) LogValuesTo(logger ValueLogger) {
+	for _, v := range p.Values() {
+		logger.Infow(logValuesMsg, "name", v.Name, "field", v.Field, "source", v.Origin, "value", v.Value)
+	}
+}
@@ -0,0 +1,32 @@
+package env
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogValuesTo(t *testing.T) {
+	var envs struct {
+		Host     string
+		Port     int    `default:"80"`
+		Password string `env:"password,secret"`
+	}
+
+	p, err := pparse(envsMap{"host": "example.com", "password": "hunter2"}, &envs)
+	require.NoError(t, err)
+
+	var lines []string
+
+	p.LogValuesTo(LoggerFunc(func(msg string, keysAndValues ...interface{}) {
+		lines = append(lines, fmt.Sprintln(append([]interface{}{msg}, keysAndValues...)...))
+	}))
+
+	assert.Equal(t, []string{
+		"config name host field Host source env value example.com\n",
+		"config name port field Port source default value 80\n",
+		"config name password field Password source env value ***\n",
+	}, lines)
+}
@@ -0,0 +1,47 @@
+package env
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigLookup(t *testing.T) {
+	os.Clearenv()
+	require.NoError(t, os.Setenv("NAME", "process"))
+
+	defer os.Clearenv()
+
+	lookup := MapSource{"NAME": "synthetic", "WORKERS": "4"}.Lookup
+
+	var envs struct {
+		Name    string `env:"NAME"`
+		Workers int    `env:"WORKERS"`
+	}
+
+	p, err := NewParser(Config{Lookup: lookup}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, "synthetic", envs.Name)
+	assert.Equal(t, 4, envs.Workers)
+
+	_, source, _ := p.Lookup("NAME")
+	assert.Equal(t, "env", source)
+
+	// the environment among other sources is replaced too
+	var layered struct {
+		Name string `env:"NAME"`
+		Host string `env:"HOST"`
+	}
+
+	p, err = NewParser(Config{
+		Lookup:  lookup,
+		Sources: []Source{EnvSource(), MapSource{"NAME": "map", "HOST": "map"}},
+	}, &layered)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, "synthetic", layered.Name)
+	assert.Equal(t, "map", layered.Host)
+}
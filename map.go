@@ -0,0 +1,201 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// The default separators of the maps read from a single variable.
+const (
+	// defaultKVSep separates the keys from the values, as in a=1.
+	defaultKVSep = "="
+	// defaultItemSep separates the values of the maps of slices, as in
+	// accept=a|b.
+	defaultItemSep = "|"
+)
+
+// isMap returns true if t is a map with string keys whose values, or the
+// elements of their slices, can be parsed from a single value, which is
+// read from a list of key=value pairs.
+func isMap(t reflect.Type) bool {
+	if t.Kind() != reflect.Map || t.Key().Kind() != reflect.String {
+		return false
+	}
+
+	return canParseScalar(t.Elem()) || t.Elem().Kind() == reflect.Slice && canParseScalar(t.Elem().Elem())
+}
+
+// isMapOfSlices returns true if the values of the map type t are slices
+// whose elements are split on the item separator.
+func isMapOfSlices(t reflect.Type) bool {
+	return !canParseScalar(t.Elem())
+}
+
+// parseMap sets dest to the map holding the key=value pairs of entries.
+// The values of the maps of slices are split on the item separator, and
+// the values of repeated keys are appended.
+func (s *spec) parseMap(dest reflect.Value, entries []string, parse func(reflect.Value, string) error) error {
+	t := dest.Type()
+	m := reflect.MakeMapWithSize(t, len(entries))
+
+	for _, entry := range entries {
+		i := strings.Index(entry, s.kvSep)
+		if i < 0 {
+			return fmt.Errorf("%q: %w", entry, ErrorMalformedPair)
+		}
+
+		key := reflect.ValueOf(entry[:i]).Convert(t.Key())
+		value := entry[i+len(s.kvSep):]
+
+		if !isMapOfSlices(t) {
+			v := reflect.New(t.Elem()).Elem()
+			if err := parse(v, value); err != nil {
+				return fmt.Errorf("key %q: %w", entry[:i], err)
+			}
+
+			m.SetMapIndex(key, v)
+
+			continue
+		}
+
+		items := m.MapIndex(key)
+		if !items.IsValid() {
+			items = reflect.MakeSlice(t.Elem(), 0, 1)
+		}
+
+		if value != "" {
+			for _, item := range strings.Split(value, s.itemSep) {
+				v := reflect.New(t.Elem().Elem()).Elem()
+				if err := parse(v, item); err != nil {
+					return fmt.Errorf("key %q: %w", entry[:i], err)
+				}
+
+				items = reflect.Append(items, v)
+			}
+		}
+
+		m.SetMapIndex(key, items)
+	}
+
+	dest.Set(m)
+
+	return nil
+}
+
+// formatMap returns the pairs of the map v in the form they are parsed
+// from, sorted by key.
+func (s *spec) formatMap(v reflect.Value) string {
+	if v.IsNil() {
+		return ""
+	}
+
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	entries := make([]string, len(keys))
+
+	for i, key := range keys {
+		value := v.MapIndex(key)
+
+		if isMapOfSlices(v.Type()) {
+			items := make([]string, value.Len())
+			for j := range items {
+				items[j] = formatValue(value.Index(j))
+			}
+
+			entries[i] = key.String() + s.kvSep + strings.Join(items, s.itemSep)
+		} else {
+			entries[i] = key.String() + s.kvSep + formatValue(value)
+		}
+	}
+
+	return formatCSV(entries)
+}
+
+// cloneMap returns a copy of the map v, with copies of its slices.
+func cloneMap(v reflect.Value) reflect.Value {
+	if v.IsNil() {
+		return v
+	}
+
+	c := reflect.MakeMapWithSize(v.Type(), v.Len())
+
+	iter := v.MapRange()
+	for iter.Next() {
+		c.SetMapIndex(iter.Key(), cloneValue(iter.Value()))
+	}
+
+	return c
+}
+
+// parseMap parses the list of key=value pairs in value into the map dest.
+// The empty value is the empty map, and more pairs than MaxElements are
+// rejected.
+func (p *Parser) parseMap(dest reflect.Value, spec *spec, value string) error {
+	if value == "" {
+		dest.Set(reflect.MakeMap(dest.Type()))
+
+		return nil
+	}
+
+	entries, err := p.csvOptions(spec).split(value)
+	if err != nil {
+		return err
+	}
+
+	if err := p.config.checkEntries(p.messages, len(entries)); err != nil {
+		return err
+	}
+
+	return spec.parseMap(dest, entries, p.parseValue)
+}
+
+// lookAtSeparators reads the kvSep and itemSep tags of the map field, and
+// sets the default separators of maps.
+func (s *spec) lookAtSeparators(field *reflect.StructField) error {
+	if !s.mapped {
+		for _, key := range []string{"kvSep", "itemSep"} {
+			if _, exists := field.Tag.Lookup(key); exists {
+				return ErrorSeparatorNotMap
+			}
+		}
+
+		return nil
+	}
+
+	s.kvSep, s.itemSep = defaultKVSep, defaultItemSep
+
+	if sep, exists := field.Tag.Lookup("kvSep"); exists {
+		if sep == "" {
+			return fmt.Errorf("kvSep: %w", ErrorMalformedTag)
+		}
+
+		s.kvSep = sep
+	}
+
+	if sep, exists := field.Tag.Lookup("itemSep"); exists {
+		if sep == "" || !isMapOfSlices(field.Type) {
+			return fmt.Errorf("itemSep: %w", ErrorMalformedTag)
+		}
+
+		s.itemSep = sep
+	}
+
+	return nil
+}
+
+// format returns the value v of the option the way it would be written in
+// the environment.
+func (s *spec) format(v reflect.Value) string {
+	if s.mapped && v.IsValid() {
+		return s.formatMap(v)
+	}
+
+	if s.layout != "" && v.IsValid() {
+		return formatTime(v, s.layout)
+	}
+
+	return formatValue(v)
+}
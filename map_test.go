@@ -0,0 +1,144 @@
+package env
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapOfSlices(t *testing.T) {
+	var envs struct {
+		Headers map[string][]string `kvSep:":"`
+		Routes  map[string][]string `itemSep:";"`
+		Empty   map[string][]string
+	}
+
+	p, err := pparse(envsMap{
+		"headers": "Accept:text/html|application/json,Cache-Control:no-store,Accept:*/*",
+		"routes":  `"/api=a;b","/=c"`,
+		"empty":   "",
+	}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"Accept":        {"text/html", "application/json", "*/*"},
+		"Cache-Control": {"no-store"},
+	}, envs.Headers)
+	assert.Equal(t, map[string][]string{"/api": {"a", "b"}, "/": {"c"}}, envs.Routes)
+	assert.Equal(t, map[string][]string{}, envs.Empty)
+	assert.Equal(t, "Accept:text/html|application/json|*/*,Cache-Control:no-store", p.Snapshot()["headers"])
+}
+
+func TestMapOfStrings(t *testing.T) {
+	type Labels map[string]string
+
+	var envs struct {
+		Labels   Labels
+		Selector map[string]string `default:"app=web,tier=front"`
+	}
+
+	err := parse(envsMap{"labels": "team=core,query=a=b"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, Labels{"team": "core", "query": "a=b"}, envs.Labels)
+	assert.Equal(t, map[string]string{"app": "web", "tier": "front"}, envs.Selector)
+}
+
+func TestMapInitialValue(t *testing.T) {
+	envs := struct {
+		Labels map[string]string
+	}{Labels: map[string]string{"team": "core"}}
+
+	p, err := NewParser(Config{Sources: []Source{MapSource{}}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Contains(t, p.Help(), "[default: team=core]")
+
+	// the parsed map is not the initial one
+	envs.Labels["team"] = "web"
+
+	require.NoError(t, p.Parse())
+	assert.Equal(t, map[string]string{"team": "core"}, envs.Labels)
+}
+
+func TestMapErrors(t *testing.T) {
+	var envs struct {
+		Labels map[string]string
+	}
+
+	err := parse(envsMap{"labels": "team"}, &envs)
+	assert.True(t, errors.Is(err, ErrorMalformedPair))
+
+	var notMap struct {
+		Name string `kvSep:":"`
+	}
+
+	_, err = NewParser(Config{}, &notMap)
+	assert.True(t, errors.Is(err, ErrorSeparatorNotMap))
+
+	var notSlices struct {
+		Labels map[string]string `itemSep:";"`
+	}
+
+	_, err = NewParser(Config{}, &notSlices)
+	assert.True(t, errors.Is(err, ErrorMalformedTag))
+}
+
+func TestTypedMap(t *testing.T) {
+	var envs struct {
+		Limits   map[string]int
+		Timeouts map[string]time.Duration `default:"read=5s,write=1m"`
+		Hosts    map[string]net.IP
+		Ports    map[string][]uint16
+	}
+
+	err := parse(envsMap{
+		"limits": "cpu=2,memory=512",
+		"hosts":  "primary=10.0.0.1,replica=10.0.0.2",
+		"ports":  "web=80|443,ssh=22",
+	}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"cpu": 2, "memory": 512}, envs.Limits)
+	assert.Equal(t, map[string]time.Duration{"read": 5 * time.Second, "write": time.Minute}, envs.Timeouts)
+	assert.Equal(t, net.ParseIP("10.0.0.2"), envs.Hosts["replica"])
+	assert.Equal(t, map[string][]uint16{"web": {80, 443}, "ssh": {22}}, envs.Ports)
+}
+
+func TestTypedMapInitialValue(t *testing.T) {
+	envs := struct {
+		Timeouts map[string]time.Duration
+		Ports    map[string][]int
+	}{
+		Timeouts: map[string]time.Duration{"read": 90 * time.Second},
+		Ports:    map[string][]int{"web": {80, 443}},
+	}
+
+	p, err := NewParser(Config{Sources: []Source{MapSource{}}}, &envs)
+	require.NoError(t, err)
+	assert.Contains(t, p.Help(), "[default: read=1m30s]")
+	assert.Contains(t, p.Help(), "[default: web=80|443]")
+}
+
+func TestTypedMapErrors(t *testing.T) {
+	var envs struct {
+		Limits map[string]int
+		Ports  map[string][]int
+	}
+
+	err := parse(envsMap{"limits": "cpu=2,memory=lots"}, &envs)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `key "memory"`)
+
+	err = parse(envsMap{"ports": "web=80|http"}, &envs)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `key "web"`)
+
+	var unsupported struct {
+		Nested map[string]map[string]int
+	}
+
+	_, err = NewParser(Config{}, &unsupported)
+	assert.True(t, errors.Is(err, ErrorFieldsAreNotSupported))
+}
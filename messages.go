@@ -0,0 +1,209 @@
+package env
+
+import "fmt"
+
+// Messages are the user-facing strings of the help, of the errors returned
+// by Parse and of the hints of their FieldError, which can be translated,
+// see Config.Messages. The fields left empty keep their English text, given
+// in their comments; translations keep the verbs of the English format in
+// the same order. The errors of the sources, of the parser configuration
+// and of the values themselves, such as those of strconv, are not covered.
+type Messages struct {
+	// Help
+	Environments string // "Environments:", heading the list of variables
+	Required     string // "required", after the type of required variables
+	Aliases      string // "aliases"
+	Levels       string // "levels"
+	OneOf        string // "one of", before the allowed values
+	Items        string // "items", before the number of elements of lists
+	Fallback     string // "fallback", before the variables fallen back on
+	Default      string // "default"
+	Docs         string // "docs", before the link to the documentation
+	ItemsRange   string // "%d to %d", the number of elements of lists
+	ItemsAtLeast string // "at least %d"
+	ItemsAtMost  string // "at most %d"
+
+	// Errors
+	FieldIsRequired string // "field is required"
+	RequiredWith    string // "with", before the variable enabling an optional group
+	DidYouMean      string // "did you mean %s?", naming a variable with a close name
+	InvalidValue    string // "error processing environment variable %s"
+	InvalidDefault  string // "error processing default value for %s"
+	DefaultValue    string // "default value for %s", before the errors of defaults read from the tags
+	Variable        string // "environment variable %s", before the errors of the checks of values
+	ReadFile        string // "error reading the file of environment variable %s"
+	DecodeValue     string // "error decoding environment variable %s"
+	ReadList        string // "error reading a CSV string from environment variable %s with multiple values"
+	InvalidList     string // "error processing environment variable %s with multiple values"
+	ValueTooLong    string // "%d bytes, the limit is %d", see Config.MaxValueLength
+	MoreElements    string // "more than %d elements", see Config.MaxElements
+	TooFewElements  string // "%d elements, at least %d expected", see the minitems option
+	TooManyElements string // "%d elements, at most %d expected", see the maxitems option
+	OutOfRange      string // "%s range is %s", after the errors of integers out of range
+
+	// Hints, see FieldError
+	HintSet       string // "set %s"
+	HintNotEmpty  string // "set %s to a non-empty value or unset it"
+	HintShorter   string // "use a shorter value"
+	HintFewer     string // "use fewer elements"
+	HintMore      string // "use more elements"
+	HintDirectory string // "expected a directory"
+	HintExists    string // "expected an existing path"
+	HintOneOf     string // "expected one of: %s"
+	HintLevels    string // "expected an integer or one of: %s"
+	HintList      string // "expected a comma-separated list of %s values"
+	HintValue     string // "expected a value of type %s"
+	HintWithin    string // "%s in %s", adding the range of integers to HintList or HintValue
+}
+
+// defaultMessages are the English messages.
+var defaultMessages = Messages{ // nolint:gochecknoglobals
+	Environments:    "Environments:",
+	Required:        "required",
+	Aliases:         "aliases",
+	Levels:          "levels",
+	OneOf:           "one of",
+	Items:           "items",
+	Fallback:        "fallback",
+	Default:         "default",
+	Docs:            "docs",
+	ItemsRange:      "%d to %d",
+	ItemsAtLeast:    "at least %d",
+	ItemsAtMost:     "at most %d",
+	FieldIsRequired: ErrorFieldIsRequired.Error(),
+	RequiredWith:    "with",
+	DidYouMean:      "did you mean %s?",
+	InvalidValue:    "error processing environment variable %s",
+	InvalidDefault:  "error processing default value for %s",
+	DefaultValue:    "default value for %s",
+	Variable:        "environment variable %s",
+	ReadFile:        "error reading the file of environment variable %s",
+	DecodeValue:     "error decoding environment variable %s",
+	ReadList:        "error reading a CSV string from environment variable %s with multiple values",
+	InvalidList:     "error processing environment variable %s with multiple values",
+	ValueTooLong:    "%d bytes, the limit is %d",
+	MoreElements:    "more than %d elements",
+	TooFewElements:  "%d elements, at least %d expected",
+	TooManyElements: "%d elements, at most %d expected",
+	OutOfRange:      "%s range is %s",
+	HintSet:         "set %s",
+	HintNotEmpty:    "set %s to a non-empty value or unset it",
+	HintShorter:     "use a shorter value",
+	HintFewer:       "use fewer elements",
+	HintMore:        "use more elements",
+	HintDirectory:   "expected a directory",
+	HintExists:      "expected an existing path",
+	HintOneOf:       "expected one of: %s",
+	HintLevels:      "expected an integer or one of: %s",
+	HintList:        "expected a comma-separated list of %s values",
+	HintValue:       "expected a value of type %s",
+	HintWithin:      "%s in %s",
+}
+
+// withDefaults returns the messages with the empty ones set to their
+// English text.
+func (m Messages) withDefaults() Messages {
+	for _, s := range []struct {
+		msg *string
+		def string
+	}{
+		{&m.Environments, defaultMessages.Environments},
+		{&m.Required, defaultMessages.Required},
+		{&m.Aliases, defaultMessages.Aliases},
+		{&m.Levels, defaultMessages.Levels},
+		{&m.OneOf, defaultMessages.OneOf},
+		{&m.Items, defaultMessages.Items},
+		{&m.Fallback, defaultMessages.Fallback},
+		{&m.Default, defaultMessages.Default},
+		{&m.Docs, defaultMessages.Docs},
+		{&m.ItemsRange, defaultMessages.ItemsRange},
+		{&m.ItemsAtLeast, defaultMessages.ItemsAtLeast},
+		{&m.ItemsAtMost, defaultMessages.ItemsAtMost},
+		{&m.FieldIsRequired, defaultMessages.FieldIsRequired},
+		{&m.RequiredWith, defaultMessages.RequiredWith},
+		{&m.DidYouMean, defaultMessages.DidYouMean},
+		{&m.InvalidValue, defaultMessages.InvalidValue},
+		{&m.InvalidDefault, defaultMessages.InvalidDefault},
+		{&m.DefaultValue, defaultMessages.DefaultValue},
+		{&m.Variable, defaultMessages.Variable},
+		{&m.ReadFile, defaultMessages.ReadFile},
+		{&m.DecodeValue, defaultMessages.DecodeValue},
+		{&m.ReadList, defaultMessages.ReadList},
+		{&m.InvalidList, defaultMessages.InvalidList},
+		{&m.ValueTooLong, defaultMessages.ValueTooLong},
+		{&m.MoreElements, defaultMessages.MoreElements},
+		{&m.TooFewElements, defaultMessages.TooFewElements},
+		{&m.TooManyElements, defaultMessages.TooManyElements},
+		{&m.OutOfRange, defaultMessages.OutOfRange},
+		{&m.HintSet, defaultMessages.HintSet},
+		{&m.HintNotEmpty, defaultMessages.HintNotEmpty},
+		{&m.HintShorter, defaultMessages.HintShorter},
+		{&m.HintFewer, defaultMessages.HintFewer},
+		{&m.HintMore, defaultMessages.HintMore},
+		{&m.HintDirectory, defaultMessages.HintDirectory},
+		{&m.HintExists, defaultMessages.HintExists},
+		{&m.HintOneOf, defaultMessages.HintOneOf},
+		{&m.HintLevels, defaultMessages.HintLevels},
+		{&m.HintList, defaultMessages.HintList},
+		{&m.HintValue, defaultMessages.HintValue},
+		{&m.HintWithin, defaultMessages.HintWithin},
+	} {
+		if *s.msg == "" {
+			*s.msg = s.def
+		}
+	}
+
+	return m
+}
+
+// fieldIsRequired returns ErrorFieldIsRequired, with the translated message
+// if any.
+func (m Messages) fieldIsRequired() error {
+	if m.FieldIsRequired == ErrorFieldIsRequired.Error() {
+		return ErrorFieldIsRequired
+	}
+
+	return translatedError{msg: m.FieldIsRequired, err: ErrorFieldIsRequired}
+}
+
+// invalidValue returns the error of the value of the variable name.
+func (m Messages) invalidValue(name string, err error) error {
+	return wrapf(m.InvalidValue, name, err)
+}
+
+// invalidDefault returns the error of the default value of the variable
+// name.
+func (m Messages) invalidDefault(name string, err error) error {
+	return wrapf(m.InvalidDefault, name, err)
+}
+
+// variable returns err prefixed with the variable name.
+func (m Messages) variable(name string, err error) error {
+	return wrapf(m.Variable, name, err)
+}
+
+// limitError returns the sentinel err with the message format applied to
+// args.
+func limitError(err error, format string, args ...interface{}) error {
+	return fmt.Errorf("%s: %w", fmt.Sprintf(format, args...), err)
+}
+
+// wrapf returns err prefixed with the message format applied to name.
+func wrapf(format, name string, err error) error {
+	return fmt.Errorf("%s: %w", fmt.Sprintf(format, name), err)
+}
+
+// translatedError is a sentinel error with a translated message, which
+// errors.Is still matches.
+type translatedError struct {
+	msg string
+	err error
+}
+
+func (e translatedError) Error() string {
+	return e.msg
+}
+
+func (e translatedError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,107 @@
+package env
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// frenchMessages translate part of the messages.
+var frenchMessages = Messages{ // nolint:gochecknoglobals
+	Environments:    "Variables d'environnement :",
+	Required:        "obligatoire",
+	Default:         "défaut",
+	FieldIsRequired: "variable obligatoire",
+	DidYouMean:      "vouliez-vous dire %s ?",
+	InvalidValue:    "valeur invalide pour %s",
+}
+
+func TestMessagesHelp(t *testing.T) {
+	var envs struct {
+		Host    string `env:",required" help:"hôte"`
+		Workers int    `default:"4" env:",alias:threads"`
+	}
+
+	p, err := NewParser(Config{Messages: frenchMessages}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "Variables d'environnement :\n"+
+		"  host string (obligatoire)\n"+
+		"                         hôte\n"+
+		"  workers int [aliases: threads, défaut: 4]\n", p.Help())
+}
+
+func TestMessagesErrors(t *testing.T) {
+	var envs struct {
+		Host    string `env:",required"`
+		Workers int
+	}
+
+	p, err := NewParser(Config{
+		Sources:  []Source{MapSource{"hostt": "x", "workers": "many"}},
+		Messages: frenchMessages,
+	}, &envs)
+	require.NoError(t, err)
+
+	err = p.Parse()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrorFieldIsRequired))
+	assert.Contains(t, err.Error(), "host: variable obligatoire, vouliez-vous dire hostt ?")
+	assert.Contains(t, err.Error(), "valeur invalide pour workers: ")
+}
+
+func TestMessagesDefaults(t *testing.T) {
+	assert.Equal(t, defaultMessages, Messages{}.withDefaults())
+	assert.Equal(t, ErrorFieldIsRequired, Messages{}.withDefaults().fieldIsRequired())
+}
+
+func TestMessagesLimitsAndHints(t *testing.T) {
+	var envs struct {
+		Name  string
+		Ports []uint8 `env:",minitems:2"`
+		Level uint8
+	}
+
+	p, err := NewParser(Config{
+		Sources:        []Source{MapSource{"name": "too long", "ports": "80", "level": "300"}},
+		MaxValueLength: 4,
+		Messages: Messages{
+			Variable:       "variable %s",
+			InvalidValue:   "valeur invalide pour %s",
+			ValueTooLong:   "%d octets, la limite est %d",
+			TooFewElements: "%d éléments, au moins %d attendus",
+			OutOfRange:     "plage de %s : %s",
+			HintShorter:    "raccourcir la valeur",
+			HintMore:       "ajouter des éléments",
+			HintValue:      "valeur de type %s attendue",
+			HintWithin:     "%s dans %s",
+		},
+	}, &envs)
+	require.NoError(t, err)
+
+	err = p.Parse()
+	require.Error(t, err)
+
+	errs := err.(Errors)
+	require.Len(t, errs, 3)
+	assert.True(t, errors.Is(errs[0], ErrorValueTooLong))
+	assert.Equal(t, "variable name: 8 octets, la limite est 4: "+ErrorValueTooLong.Error(), errs[0].Error())
+	assert.Equal(t, "raccourcir la valeur", errs[0].Hint)
+	assert.True(t, errors.Is(errs[1], ErrorTooFewElements))
+	assert.Equal(t, "variable ports: 1 éléments, au moins 2 attendus: "+ErrorTooFewElements.Error(), errs[1].Error())
+	assert.Equal(t, "ajouter des éléments", errs[1].Hint)
+	assert.Contains(t, errs[2].Error(), "valeur invalide pour level: ")
+	assert.Contains(t, errs[2].Error(), "(plage de uint8 : 0–255)")
+	assert.Equal(t, "valeur de type uint8 attendue dans 0–255", errs[2].Hint)
+}
+
+func TestMessagesItemsRange(t *testing.T) {
+	var envs struct {
+		Hosts []string `env:",minitems:1,maxitems:3"`
+	}
+
+	p, err := NewParser(Config{Messages: Messages{ItemsRange: "de %d à %d"}}, &envs)
+	require.NoError(t, err)
+	assert.Contains(t, p.Help(), "[items: de 1 à 3]")
+}
@@ -0,0 +1,87 @@
+package env
+
+import (
+	"bufio"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultMetricName is the name of the metric written by WriteMetrics when
+// none is given.
+const DefaultMetricName = "app_config_info"
+
+// configInfo returns the effective values of the variables that are not
+// secret, by name, with their source.
+func (p *Parser) configInfo() map[string]map[string]string {
+	info := make(map[string]map[string]string)
+
+	for _, v := range p.Values() {
+		if v.Secret {
+			continue
+		}
+
+		info[v.Name] = map[string]string{"value": v.Value, "source": v.Origin}
+	}
+
+	return info
+}
+
+// PublishExpvar publishes the effective values of the variables that are
+// not secret, with their source, as the expvar with the given name, which
+// is served on /debug/vars. Like expvar.Publish, it panics if the name is
+// already in use.
+func (p *Parser) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return p.configInfo()
+	}))
+}
+
+// WriteMetrics writes the effective values of the variables that are not
+// secret in the Prometheus text format, as a gauge set to 1 with the name,
+// value and source labels, such as
+//
+//	app_config_info{name="port",value="8080",source="env"} 1
+//
+// The metric is named DefaultMetricName if metric is empty.
+func (p *Parser) WriteMetrics(w io.Writer, metric string) error {
+	if metric == "" {
+		metric = DefaultMetricName
+	}
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "# HELP %s Effective configuration of the process.\n", metric)
+	fmt.Fprintf(bw, "# TYPE %s gauge\n", metric)
+
+	for _, v := range p.Values() {
+		if v.Secret {
+			continue
+		}
+
+		fmt.Fprintf(bw, "%s{name=\"%s\",value=\"%s\",source=\"%s\"} 1\n",
+			metric, escapeLabel(v.Name), escapeLabel(v.Value), escapeLabel(v.Origin))
+	}
+
+	return bw.Flush()
+}
+
+// MetricsHandler returns the HTTP handler serving the metric written by
+// WriteMetrics, to be scraped by Prometheus.
+func (p *Parser) MetricsHandler(metric string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+		_ = p.WriteMetrics(w, metric)
+	})
+}
+
+// labelEscaper escapes the values of Prometheus labels.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`) // nolint:gochecknoglobals
+
+// escapeLabel escapes value for a Prometheus label.
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
@@ -0,0 +1,68 @@
+package env
+
+import (
+	"bytes"
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type metricsConfig struct {
+	Host     string
+	Port     int    `default:"80"`
+	Motd     string `env:"motd"`
+	Password string `env:"password,secret"`
+}
+
+func TestWriteMetrics(t *testing.T) {
+	var envs metricsConfig
+
+	p, err := pparse(envsMap{"host": "example.com", "motd": "say \"hi\"\n", "password": "hunter2"}, &envs)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, p.WriteMetrics(&buf, ""))
+	assert.Equal(t, "# HELP app_config_info Effective configuration of the process.\n"+
+		"# TYPE app_config_info gauge\n"+
+		"app_config_info{name=\"host\",value=\"example.com\",source=\"env\"} 1\n"+
+		"app_config_info{name=\"port\",value=\"80\",source=\"default\"} 1\n"+
+		"app_config_info{name=\"motd\",value=\"say \\\"hi\\\"\\n\",source=\"env\"} 1\n", buf.String())
+}
+
+func TestMetricsHandler(t *testing.T) {
+	var envs metricsConfig
+
+	p, err := pparse(envsMap{"host": "example.com"}, &envs)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	p.MetricsHandler("myapp_config_info").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, rec.Body.String(), "myapp_config_info{name=\"host\",value=\"example.com\",source=\"env\"} 1\n")
+}
+
+func TestPublishExpvar(t *testing.T) {
+	var envs metricsConfig
+
+	p, err := pparse(envsMap{"host": "example.com", "password": "hunter2"}, &envs)
+	require.NoError(t, err)
+
+	p.PublishExpvar("test_config")
+
+	var info map[string]map[string]string
+
+	require.NoError(t, json.Unmarshal([]byte(expvar.Get("test_config").String()), &info))
+	assert.Equal(t, map[string]map[string]string{
+		"host": {"value": "example.com", "source": "env"},
+		"port": {"value": "80", "source": "default"},
+		"motd": {"value": "", "source": ""},
+	}, info)
+}
@@ -0,0 +1,157 @@
+package env
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// FieldError describes a variable that could not be processed.
+type FieldError struct {
+	// Field is the dotted path of the destination field from its root
+	// struct type, such as "Config.DB.Host", see FieldInfo.
+	Field string
+	// Name is the name of the variable, or of the alias that was set.
+	Name string
+	// Hint suggests how to fix the variable.
+	Hint string
+	// Err is the underlying error.
+	Err error
+}
+
+func (p *Parser) fieldError(spec *spec, name string, err error) *FieldError {
+	return &FieldError{
+		Field: qualifiedPath(p.roots[spec.dest.root].Type().Elem(), spec.dest),
+		Name:  name,
+		Hint:  spec.hint(p.messages, err),
+		Err:   err,
+	}
+}
+
+func (e *FieldError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// MarshalJSON renders the error as an object with the field, env, reason
+// and hint keys.
+func (e *FieldError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Field  string `json:"field,omitempty"`
+		Env    string `json:"env,omitempty"`
+		Reason string `json:"reason"`
+		Hint   string `json:"hint,omitempty"`
+	}{e.Field, e.Name, e.Err.Error(), e.Hint})
+}
+
+// Errors is returned by Parse and Reload when one or more variables could
+// not be processed, with one FieldError per variable.
+type Errors []*FieldError
+
+// Error returns the messages of the errors, one per line.
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// Is reports whether any of the errors matches target.
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first of the errors that matches target.
+func (e Errors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WriteErrorsJSON writes err as a JSON array with one object per variable
+// that could not be processed, so that deployment tools can present the
+// errors in their own way. Errors that are not about a particular variable
+// are written as a single object with only a reason.
+func WriteErrorsJSON(w io.Writer, err error) error {
+	var errs Errors
+	if !errors.As(err, &errs) {
+		errs = Errors{{Err: err}}
+	}
+
+	return json.NewEncoder(w).Encode(errs)
+}
+
+// hint suggests how to fix the variable that failed with err.
+func (s *spec) hint(m Messages, err error) string {
+	if errors.Is(err, ErrorFieldIsRequired) {
+		return fmt.Sprintf(m.HintSet, s.name)
+	}
+
+	if errors.Is(err, ErrorEmptyValue) {
+		return fmt.Sprintf(m.HintNotEmpty, s.name)
+	}
+
+	if errors.Is(err, ErrorValueTooLong) {
+		return m.HintShorter
+	}
+
+	if errors.Is(err, ErrorTooManyElements) {
+		return m.HintFewer
+	}
+
+	if errors.Is(err, ErrorTooFewElements) {
+		return m.HintMore
+	}
+
+	if errors.Is(err, ErrorNotDirectory) {
+		return m.HintDirectory
+	}
+
+	if errors.Is(err, os.ErrNotExist) {
+		return m.HintExists
+	}
+
+	if s.values != nil {
+		return fmt.Sprintf(m.HintOneOf, strings.Join(s.values, ", "))
+	}
+
+	if s.levels != nil {
+		return fmt.Sprintf(m.HintLevels, strings.Join(s.levelNames(), ", "))
+	}
+
+	typ := s.valueType()
+	kind, rng, ok := intRange(s.typ)
+
+	if ok {
+		typ = kind
+	}
+
+	hint := fmt.Sprintf(m.HintValue, typ)
+	if s.multiple {
+		hint = fmt.Sprintf(m.HintList, typ)
+	}
+
+	if ok {
+		hint = fmt.Sprintf(m.HintWithin, hint, rng)
+	}
+
+	return hint
+}
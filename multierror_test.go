@@ -0,0 +1,104 @@
+package env
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorsAreAggregated(t *testing.T) {
+	var envs struct {
+		Workers int `env:"workers,required"`
+		Ratio   float64
+		Host    string `env:"host,required"`
+		Name    string
+	}
+
+	err := parse(envsMap{"workers": "xyz", "ratio": "abc", "name": "foo"}, &envs)
+	require.Error(t, err)
+
+	var errs Errors
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs, 3)
+	assert.Equal(t, "Workers", errs[0].Field)
+	assert.Equal(t, "workers", errs[0].Name)
+	assert.Equal(t, "expected a value of type int", errs[0].Hint)
+	assert.Equal(t, "Ratio", errs[1].Field)
+	assert.Equal(t, "expected a value of type float", errs[1].Hint)
+	assert.Equal(t, "Host", errs[2].Field)
+	assert.Equal(t, "set host", errs[2].Hint)
+	assert.True(t, errors.Is(err, ErrorFieldIsRequired))
+	assert.Empty(t, envs.Name, "the struct is left untouched on failure")
+}
+
+func TestParseLeavesStructOnError(t *testing.T) {
+	envs := struct {
+		Host    string
+		Workers int
+		Tags    []string
+		DB      *struct {
+			User string `env:"db_user"`
+		} `onMissing:"disable"`
+	}{Host: "localhost"}
+
+	err := parse(envsMap{"host": "example.com", "tags": "a,b", "db_user": "u", "workers": "xyz"}, &envs)
+	require.Error(t, err)
+	assert.Equal(t, "localhost", envs.Host)
+	assert.Nil(t, envs.Tags)
+	assert.Nil(t, envs.DB)
+}
+
+func TestSingleErrorMessage(t *testing.T) {
+	var envs struct {
+		Host string `env:"host,required"`
+	}
+
+	err := parse(envsMap{}, &envs)
+	assert.EqualError(t, err, "host: field is required")
+}
+
+func TestWriteErrorsJSON(t *testing.T) {
+	var envs struct {
+		Workers []int
+		IP      net.IP
+		Host    string `env:"host,required"`
+	}
+
+	err := parse(envsMap{"workers": "1,x", "ip": "foo"}, &envs)
+	require.Error(t, err)
+
+	var b bytes.Buffer
+
+	require.NoError(t, WriteErrorsJSON(&b, err))
+	assert.JSONEq(t, `[
+		{
+			"field": "Workers",
+			"env": "workers",
+			"reason": "error processing environment variable workers with multiple values: strconv.ParseInt: parsing \"x\": invalid syntax",
+			"hint": "expected a comma-separated list of int values"
+		},
+		{
+			"field": "IP",
+			"env": "ip",
+			"reason": "error processing environment variable ip: invalid IP address: foo",
+			"hint": "expected a value of type IP"
+		},
+		{
+			"field": "Host",
+			"env": "host",
+			"reason": "host: field is required",
+			"hint": "set host"
+		}
+	]`, b.String())
+}
+
+func TestWriteErrorsJSONOtherError(t *testing.T) {
+	var b bytes.Buffer
+
+	require.NoError(t, WriteErrorsJSON(&b, ErrorNotStruct))
+	assert.JSONEq(t, `[{"reason": "must be structs"}]`, b.String())
+}
@@ -0,0 +1,41 @@
+package env
+
+import "strings"
+
+// NameStyle derives the names of the variables from the names of the
+// fields that do not set one in their tag.
+type NameStyle int
+
+const (
+	// LowerCase lower-cases the field name, MaxRetryCount giving
+	// maxretrycount. It is the default.
+	LowerCase NameStyle = iota
+	// ScreamingSnakeCase upper-cases the words of the field name and joins
+	// them with underscores, MaxRetryCount giving MAX_RETRY_COUNT and
+	// DBHost giving DB_HOST.
+	ScreamingSnakeCase
+)
+
+// name returns the name of the variable of the field named field.
+func (s NameStyle) name(field string) string {
+	if s == ScreamingSnakeCase {
+		return strings.ToUpper(strings.Join(splitWords(field), "_"))
+	}
+
+	return strings.ToLower(field)
+}
+
+// fieldName returns the name of the variable of the field at dest, for
+// fields that do not set one in their tag.
+func (c Config) fieldName(dest path) string {
+	if c.NameMapper != nil {
+		names := make([]string, len(dest.fields))
+		for i, f := range dest.fields {
+			names[i] = f.Name
+		}
+
+		return c.NameMapper(names)
+	}
+
+	return c.NameStyle.name(dest.fields[len(dest.fields)-1].Name)
+}
@@ -0,0 +1,120 @@
+package env
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScreamingSnakeCase(t *testing.T) {
+	var envs struct {
+		MaxRetryCount int
+		DBHost        string
+		HTTPProxyURL  string
+		Named         string `env:"custom"`
+		Backends      []struct {
+			HostName string
+		}
+	}
+
+	p, err := NewParser(Config{
+		NameStyle: ScreamingSnakeCase,
+		Sources: []Source{MapSource{
+			"MAX_RETRY_COUNT":      "3",
+			"DB_HOST":              "db",
+			"HTTP_PROXY_URL":       "http://proxy",
+			"custom":               "x",
+			"BACKENDS_0_HOST_NAME": "a",
+		}},
+	}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, 3, envs.MaxRetryCount)
+	assert.Equal(t, "db", envs.DBHost)
+	assert.Equal(t, "http://proxy", envs.HTTPProxyURL)
+	assert.Equal(t, "x", envs.Named)
+	require.Len(t, envs.Backends, 1)
+	assert.Equal(t, "a", envs.Backends[0].HostName)
+}
+
+func TestNameStyle(t *testing.T) {
+	assert.Equal(t, "maxretrycount", LowerCase.name("MaxRetryCount"))
+	assert.Equal(t, "MAX_RETRY_COUNT", ScreamingSnakeCase.name("MaxRetryCount"))
+	assert.Equal(t, "PORT2", ScreamingSnakeCase.name("Port2"))
+	assert.Equal(t, "ID", ScreamingSnakeCase.name("ID"))
+}
+
+func TestNameMapper(t *testing.T) {
+	type DB struct {
+		Host string
+	}
+
+	var envs struct {
+		DB
+		Port  int
+		Named string `env:"custom"`
+	}
+
+	var paths [][]string
+
+	p, err := NewParser(Config{
+		NameMapper: func(fieldPath []string) string {
+			paths = append(paths, fieldPath)
+
+			return "team_" + strings.ToLower(strings.Join(fieldPath, "."))
+		},
+		Sources: []Source{MapSource{"team_db.host": "db", "team_port": "80", "custom": "x"}},
+	}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, [][]string{{"DB", "Host"}, {"Port"}}, paths)
+	assert.Equal(t, "db", envs.Host)
+	assert.Equal(t, 80, envs.Port)
+	assert.Equal(t, "x", envs.Named)
+}
+
+func TestPrefix(t *testing.T) {
+	var envs struct {
+		Iter     int    `env:",required"`
+		Debug    bool   `env:",alias:VERBOSE"`
+		Home     string `defaultEnv:"HOME_DIR"`
+		Backends []struct {
+			Host string
+		}
+	}
+
+	p, err := NewParser(Config{
+		Prefix:    "MYAPP_",
+		NameStyle: ScreamingSnakeCase,
+		Sources: []Source{MapSource{
+			"MYAPP_ITER":             "3",
+			"MYAPP_VERBOSE":          "true",
+			"HOME_DIR":               "/home/me",
+			"MYAPP_BACKENDS_0_HOST":  "a",
+			"ITER":                   "4",
+			"MYAPP_MYAPP_BACKENDS_0": "b",
+		}},
+	}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, 3, envs.Iter)
+	assert.True(t, envs.Debug)
+	assert.Equal(t, "/home/me", envs.Home)
+	require.Len(t, envs.Backends, 1)
+	assert.Equal(t, "a", envs.Backends[0].Host)
+
+	help := p.Help()
+	assert.Contains(t, help, "MYAPP_ITER int (required)")
+	assert.Contains(t, help, "aliases: MYAPP_VERBOSE")
+	assert.Contains(t, help, "MYAPP_BACKENDS_<n>_HOST")
+	assert.Equal(t, "iter", p.Flags()[0].Name)
+
+	p, err = NewParser(Config{Prefix: "MYAPP_", Sources: []Source{MapSource{}}}, &envs)
+	require.NoError(t, err)
+
+	err = p.Parse()
+	require.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "MYAPP_iter: "), err.Error())
+}
@@ -0,0 +1,32 @@
+package env
+
+// Attribute is a resource attribute, whose key is given by the otel tag of
+// a field, such as otel:"service.version".
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// ResourceAttributes returns the attributes of the fields tagged otel, in
+// the order of the fields, with their effective values formatted as they
+// would be written in the environment. Secret values are redacted, and the
+// fields without a value are left out. The attributes can be turned into
+// OpenTelemetry ones with attribute.String(a.Key, a.Value).
+func (p *Parser) ResourceAttributes() []Attribute {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var attributes []Attribute
+
+	for _, spec := range p.specs {
+		if spec.attribute == "" {
+			continue
+		}
+
+		if value := spec.redact(spec.format(p.val(spec.dest))); value != "" {
+			attributes = append(attributes, Attribute{Key: spec.attribute, Value: value})
+		}
+	}
+
+	return attributes
+}
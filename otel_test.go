@@ -0,0 +1,37 @@
+package env
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceAttributes(t *testing.T) {
+	var envs struct {
+		Version string `env:"VERSION" otel:"service.version"`
+		Region  string `env:"REGION" otel:"cloud.region"`
+		Stage   string `env:"STAGE" otel:"deployment.environment" default:"dev"`
+		Token   string `env:"TOKEN,secret" otel:"token"`
+		Host    string `env:"HOST"`
+	}
+
+	p, err := pparse(envsMap{"VERSION": "1.2.3", "TOKEN": "hunter2", "HOST": "example.com"}, &envs)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Attribute{
+		{Key: "service.version", Value: "1.2.3"},
+		{Key: "deployment.environment", Value: "dev"},
+		{Key: "token", Value: "***"},
+	}, p.ResourceAttributes())
+}
+
+func TestResourceAttributesEmptyKey(t *testing.T) {
+	var envs struct {
+		Version string `otel:""`
+	}
+
+	_, err := NewParser(Config{}, &envs)
+	assert.True(t, errors.Is(err, ErrorMalformedTag))
+}
@@ -2,13 +2,17 @@ package env
 
 import (
 	"encoding"
-	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
-
-	scalar "github.com/alexflint/go-scalar"
+	"sync"
+	"text/template"
+	"time"
 )
 
 // path represents a sequence of steps to find the output location for an
@@ -50,14 +54,50 @@ type spec struct {
 	dest       path
 	typ        reflect.Type
 	name       string
+	aliases    []string // fallback names, in priority order
 	help       string
 	defaultVal string // default value for this option
-	multiple   bool
-	required   bool
-	boolean    bool
+	// defaultValue is the value of the field when the parser was created,
+	// restored as is when the variable is not set
+	defaultValue reflect.Value
+	defaultText  string // how the default value is displayed, if set
+	multiple     bool
+	required     bool
+	boolean      bool
+	secret       bool // hide the value in help, dumps and error messages
+	mask         bool // hide the value in help and dumps
+	invert       bool // the variable holds the negation of the bool field
+	count        bool // the variable holds a count such as "vvv" or "true"
+	levels       []level
+	values       []string                     // allowed values of Enumerated types
+	decode       func(string) (string, error) // transcodes values to UTF-8
+	defaultEnv   []string                     // variables to fall back on when unset
+	docs         string                       // link to the documentation of the variable
+	sourceOrder  []string                     // names of the sources to look up first
+	elem         reflect.Type                 // struct of the elements of an indexed slice
+	catchAll     bool                         // the map receives the unbound variables under the name
+	unset        bool                         // remove the variable from the process environment once applied
+	scrubbed     string                       // origin of the value captured before the variable was unset
+	scrubbedVal  reflect.Value                // value captured before the variable was unset
+	notEmpty     bool                         // reject variables set to an empty value
+	expand       bool                         // expand the ${VAR} references of the values
+	file         bool                         // the values are the paths of files holding them
+	pathCheck    pathCheck                    // check of the paths of Path fields
+	minItems     int                          // fewest elements of a slice, if not zero
+	maxItems     int                          // most elements of a slice, if not zero
+	attribute    string                       // key of the resource attribute holding the value
+	csv          *CSVOptions                  // how lists are split, if not as configured
+	mapped       bool                         // the map is read from a list of key=value pairs
+	kvSep        string                       // separates the keys from the values of maps
+	itemSep      string                       // separates the values of maps of slices
+	layout       string                       // layout of the values of time fields, if not RFC 3339
+	// defaultTemplate renders the default value from the other fields
+	defaultTemplate *template.Template
 
 	hasDefault bool
 	changeName bool
+
+	group *group // the nested struct this option belongs to, if any
 }
 
 func (s *spec) setDefault(def string) {
@@ -65,26 +105,83 @@ func (s *spec) setDefault(def string) {
 	s.hasDefault = true
 }
 
+// displayDefault returns the default value as displayed in help and specs.
+func (s *spec) displayDefault() string {
+	if s.defaultText != "" {
+		return s.redact(s.defaultText)
+	}
+
+	return s.redact(s.defaultVal)
+}
+
 func (s *spec) setName(name string) {
 	s.name = name
 	s.changeName = true
 }
 
-// MustParse processes command line arguments and exits upon failure.
+// MustParse processes the environment into dest with the default
+// configuration, and exits upon failure, see Parser.MustParse. Invalid
+// destination structs are reported the same way.
 func MustParse(dest ...interface{}) (*Parser, error) {
 	p, err := NewParser(Config{}, dest...)
 	if err != nil {
-		return nil, err
+		return nil, Config{}.fail(err, "")
 	}
 
-	err = p.Parse()
-	if err != nil {
+	if err := p.MustParse(); err != nil {
 		return nil, err
 	}
 
 	return p, nil
 }
 
+// MustParse is Parse, but upon failure it writes the errors, with their
+// hints, followed by the help to Config.Out and calls Config.Exit with
+// status 2. The error is returned only if Config.Exit returns, as it may in
+// tests.
+func (p *Parser) MustParse() error {
+	if err := p.Parse(); err != nil {
+		return p.config.fail(err, p.Help())
+	}
+
+	return nil
+}
+
+// fail writes err and help to Out and calls Exit, returning err if Exit
+// returns.
+func (c Config) fail(err error, help string) error {
+	out := c.Out
+	if out == nil {
+		out = os.Stderr
+	}
+
+	var errs Errors
+	if errors.As(err, &errs) {
+		for _, fe := range errs {
+			if fe.Hint != "" {
+				fmt.Fprintf(out, "error: %v (%s)\n", fe, fe.Hint)
+			} else {
+				fmt.Fprintf(out, "error: %v\n", fe)
+			}
+		}
+	} else {
+		fmt.Fprintf(out, "error: %v\n", err)
+	}
+
+	if help != "" {
+		fmt.Fprintf(out, "\n%s", help)
+	}
+
+	exit := c.Exit
+	if exit == nil {
+		exit = os.Exit
+	}
+
+	exit(2)
+
+	return err
+}
+
 // Parse processes command line arguments and stores them in dest.
 func Parse(dest ...interface{}) error {
 	p, err := NewParser(Config{}, dest...)
@@ -96,14 +193,120 @@ func Parse(dest ...interface{}) error {
 }
 
 // Config represents configuration options for an argument parser.
-type Config struct{}
+type Config struct {
+	// WaitRequired makes Parse poll for missing required variables until
+	// they become available or this much time has passed. Zero disables
+	// waiting.
+	WaitRequired time.Duration
+	// WaitInterval is the initial delay between two polls, doubled after
+	// every attempt. Defaults to 100ms.
+	WaitInterval time.Duration
+	// Sources provide the values of the variables, the first source that
+	// has a variable taking precedence over the others. Struct defaults are
+	// used last. Defaults to the process environment only.
+	Sources []Source
+	// Redact treats the variables whose name or alias matches any of these
+	// patterns, such as (?i)(token|password|key), as if they were tagged
+	// secret, hiding their values in help, dumps and error messages.
+	Redact []*regexp.Regexp
+	// MaxValueLength rejects the values longer than this many bytes, so
+	// that an untrusted environment cannot cause pathological memory use.
+	// Zero means no limit.
+	MaxValueLength int
+	// OnConflict is called for each variable set to different values by
+	// several sources, which helps to notice shadowed values while
+	// migrating between sources.
+	OnConflict func(Conflict)
+	// StrictConflicts makes variables set to different values by several
+	// sources fail with ErrorConflictingSources.
+	StrictConflicts bool
+	// NameStyle derives the names of the variables from the names of the
+	// fields that do not set one in their tag. Defaults to LowerCase.
+	NameStyle NameStyle
+	// NameMapper, if set, takes precedence over NameStyle to derive the
+	// names of the variables. It is called with the names of the struct
+	// fields leading to the field, embedded and nested structs included,
+	// such as ["DB", "Host"].
+	NameMapper func(fieldPath []string) string
+	// MaxElements rejects the lists, the maps and the slices of structs
+	// having more than this many elements or entries. Zero means no limit.
+	MaxElements int
+	// Profile activates the overrides of a profile: the variables named
+	// like others followed by two underscores and the profile, such as
+	// DB_HOST__STAGING in the staging profile, take precedence over them.
+	// The profile is upper-cased for upper-case names and lower-cased
+	// otherwise.
+	Profile string
+	// ProfileEnv names the variable holding the profile, such as APP_ENV,
+	// when Profile is not set.
+	ProfileEnv string
+	// Exit is called by MustParse with status 2 upon failure. Defaults to
+	// os.Exit.
+	Exit func(int)
+	// Out receives the errors and the help written by MustParse upon
+	// failure. Defaults to os.Stderr.
+	Out io.Writer
+	// CSV controls how the values of lists are split, for the fields
+	// without a csv tag. Defaults to strict CSV with comma separators.
+	CSV CSVOptions
+	// AllowSharedNames lets the fields of several destination structs be
+	// bound to the same variable, which sets all of them. NewParser fails
+	// with ErrorSharedName otherwise.
+	AllowSharedNames bool
+	// Prefix is prepended to the names and aliases of all the variables,
+	// such as MYAPP_ giving MYAPP_WORKERS for a variable named WORKERS.
+	// The help, the errors and the other outputs show the prefixed names,
+	// but the fallbacks of defaultEnv tags and the flags are not prefixed.
+	Prefix string
+	// Parsers parse the values of the fields of the given types, or of
+	// pointers or slices of them, taking precedence over the parsing of
+	// the package. They let third-party types, such as UUIDs or decimals,
+	// be used without wrapper types.
+	Parsers map[reflect.Type]ParserFunc
+	// Lookup, if set, replaces os.LookupEnv to read the process
+	// environment, both as the default source and where EnvSource is one
+	// of the Sources, such as to parse a synthetic environment in tests
+	// without changing the real one.
+	Lookup func(name string) (string, bool)
+	// Files are .env files of KEY=value lines, read before every Parse,
+	// providing the variables that none of the Sources provides, the
+	// first file taking precedence. Missing files are skipped. Lines may
+	// start with export, and otherwise follow the syntax of
+	// SystemdEnvFileSource. The sources tag refers to them as dotenv.
+	Files []string
+	// Messages translates the headings and labels of the help and the
+	// errors returned by Parse. Defaults to English.
+	Messages Messages
+}
 
 // Parser represents a set of command line options with destination values.
 type Parser struct {
 	specs       []*spec
+	groups      []*group
 	roots       []reflect.Value
 	config      Config
+	messages    Messages // config.Messages with their defaults
 	description string
+	version     string
+	epilogue    string
+	flags       *flagSource // set by RegisterFlags
+	files       []Source    // the dotenvSources of Config.Files and LoadDotenv
+	only        []Source    // the sources replacing all the others during ParseMap
+	indexed     []*indexed  // slices of structs read from numbered variables
+	catchAll    []*spec     // maps receiving the variables not bound to a field
+	scrubs      []scrub     // variables to unset once the values captured from them are applied
+	templated   []*spec     // options with templated defaults, in dependency order
+
+	// processMu serializes Parse and Reload, which process the sources
+	// into copies of roots that are then applied under mu
+	processMu sync.Mutex
+	mu        sync.RWMutex // guards roots while values are applied
+	origins   map[*spec]string
+	parsed    bool // a Parse or Reload succeeded
+	loaded    bool // the sources were loaded by Get
+	onChange  []ChangeFunc
+	onField   map[string][]FieldChangeFunc
+	onError   []func(error)
 }
 
 // Described is the interface that the destination struct should implement to
@@ -114,31 +317,43 @@ type Described interface {
 	Description() string
 }
 
-type visitorFn func(field reflect.StructField, owner reflect.Type) (bool, error)
+// Versioned is the interface that the destination struct should implement to
+// make a version string appear at the top of the help message.
+type Versioned interface {
+	// Version returns the string that will be printed on a line by itself
+	// at the top of the help message, above the description.
+	Version() string
+}
 
-// walkFields calls a function for each field of a struct, recursively expanding struct fields.
-func walkFields(t reflect.Type, visit visitorFn) error {
-	return walkFieldsImpl(t, visit, nil)
+// Epilogued is the interface that the destination struct should implement to
+// make an epilogue string, such as examples or links, appear at the end of
+// the help message.
+type Epilogued interface {
+	// Epilogue returns the string that will be printed after a blank line at
+	// the end of the help message.
+	Epilogue() string
 }
 
-func walkFieldsImpl(t reflect.Type, visit visitorFn, path []int) error {
+type visitorFn func(dest path, field reflect.StructField, owner reflect.Type, grp *group) (*group, error)
+
+// walkFields calls a function for each field of a struct, recursively expanding
+// the struct fields for which the visitor returns a group.
+func walkFields(dest path, t reflect.Type, grp *group, visit visitorFn) error {
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
-		field.Index = make([]int, len(path)+1)
-		copy(field.Index, append(path, i))
-		expand, err := visit(field, t)
 
+		sub, err := visit(dest, field, t, grp)
 		if err != nil {
 			return err
 		}
 
-		if expand {
-			var subpath []int
-			if field.Anonymous {
-				subpath = append(path, i) // nolint:gocritic
+		if sub != nil {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
 			}
 
-			err := walkFieldsImpl(field.Type, visit, subpath)
+			err := walkFields(sub.dest, ft, sub, visit)
 			if err != nil {
 				return err
 			}
@@ -152,8 +367,13 @@ func walkFieldsImpl(t reflect.Type, visit visitorFn, path []int) error {
 func NewParser(config Config, dests ...interface{}) (*Parser, error) {
 	// construct a parser
 	p := Parser{
-		config: config,
-		specs:  make([]*spec, 0),
+		config:   config,
+		messages: config.Messages.withDefaults(),
+		specs:    make([]*spec, 0),
+	}
+
+	for _, file := range config.Files {
+		p.files = append(p.files, newDotenvSource(file, true))
 	}
 
 	// make a list of roots
@@ -163,80 +383,263 @@ func NewParser(config Config, dests ...interface{}) (*Parser, error) {
 
 	// process each of the destination values
 	for i, dest := range dests {
+		if err := checkDest(path{root: i}, dest); err != nil {
+			return nil, err
+		}
+
 		t := reflect.TypeOf(dest)
 
-		specs, err := specsFromStruct(path{root: i}, t)
+		specs, groups, err := cachedSpecsFromStruct(path{root: i}, t, config.Parsers)
 		if err != nil {
 			return nil, err
 		}
 
+		p.groups = append(p.groups, groups...)
+
+		// the pointers to structs allocated by the caller are kept
+		for _, g := range groups {
+			if v := p.val(g.dest); g.ptr && v.IsValid() && !v.IsNil() {
+				g.allocated = true
+			}
+		}
+
+		if err := p.checkSourceOrder(specs); err != nil {
+			return nil, err
+		}
+
 		// add nonzero field values as defaults
 		for _, spec := range specs {
-			if v := p.val(spec.dest); v.IsValid() && !isZero(v) {
-				spec.defaultVal = fmt.Sprintf("%v", v)
+			if !spec.changeName {
+				spec.name = config.fieldName(spec.dest)
+			}
+
+			if prefix := config.Prefix + spec.group.namePrefix(); prefix != "" {
+				spec.name = prefix + spec.name
+				spec.aliases = prefixAll(prefix, spec.aliases)
+			}
 
-				if defaultVal, ok := v.Interface().(encoding.TextMarshaler); ok {
-					str, err := defaultVal.MarshalText()
-					if err != nil {
-						return nil, fmt.Errorf("%v: error marshaling default value to string: %w", spec.dest, err)
-					}
+			if spec.elem != nil {
+				x, err := newIndexed(config, spec)
+				if err != nil {
+					return nil, err
+				}
 
-					spec.defaultVal = string(str)
+				if v := p.val(spec.dest); v.IsValid() && !isZero(v) {
+					spec.defaultValue = cloneValue(v)
 				}
+
+				p.indexed = append(p.indexed, x)
+
+				continue
+			}
+
+			if spec.catchAll {
+				if v := p.val(spec.dest); v.IsValid() && !isZero(v) {
+					spec.defaultValue = cloneValue(v)
+				}
+
+				p.catchAll = append(p.catchAll, spec)
+
+				continue
+			}
+
+			if v := p.val(spec.dest); v.IsValid() && !isZero(v) {
+				str, err := formatDefault(v)
+				if err != nil {
+					return nil, fmt.Errorf("%v: error marshaling default value to string: %w", spec.dest, err)
+				}
+
+				if spec.mapped || spec.layout != "" {
+					str = spec.format(v)
+				}
+
+				spec.defaultVal = str
+				spec.defaultValue = cloneValue(v)
+			}
+
+			if config.redacts(spec) {
+				spec.secret = true
 			}
-		}
 
-		p.specs = append(p.specs, specs...)
+			p.specs = append(p.specs, spec)
+		}
 
 		if dest, ok := dest.(Described); ok {
 			p.description = dest.Description()
 		}
+
+		if dest, ok := dest.(Versioned); ok {
+			p.version = dest.Version()
+		}
+
+		if dest, ok := dest.(Epilogued); ok {
+			p.epilogue = dest.Epilogue()
+		}
+	}
+
+	if err := p.orderTemplates(); err != nil {
+		return nil, err
+	}
+
+	if !config.AllowSharedNames {
+		if err := p.checkSharedNames(); err != nil {
+			return nil, err
+		}
 	}
 
 	return &p, nil
 }
 
-func specsFromStruct(dest path, t reflect.Type) ([]*spec, error) {
+// checkSharedNames returns an error if a name or an alias is bound to the
+// fields of several destination structs, which would all be set from the
+// same variable.
+func (p *Parser) checkSharedNames() error {
+	owners := make(map[string]*spec)
+
+	for _, spec := range append(p.compositeSpecs(), p.specs...) {
+		for _, name := range append([]string{spec.name}, spec.aliases...) {
+			other, exists := owners[name]
+			if !exists {
+				owners[name] = spec
+
+				continue
+			}
+
+			if other.dest.root != spec.dest.root {
+				return fmt.Errorf("%s: %s and %s: %w", name, p.destPath(other), p.destPath(spec), ErrorSharedName)
+			}
+		}
+	}
+
+	return nil
+}
+
+// destPath returns the path of the field of spec, prefixed with the
+// position of its destination struct when it has no type name.
+func (p *Parser) destPath(spec *spec) string {
+	root := p.roots[spec.dest.root].Type().Elem()
+	if root.Name() == "" {
+		return fmt.Sprintf("#%d.%s", spec.dest.root+1, spec.dest.fieldPath())
+	}
+
+	return qualifiedPath(root, spec.dest)
+}
+
+var reflectValueType = reflect.TypeOf(reflect.Value{}) // nolint:gochecknoglobals
+
+// checkDest returns an error explaining how to fix a destination whose
+// fields could not be written, or whose writes would be lost on a copy.
+func checkDest(dest path, v interface{}) error {
+	if v == nil {
+		return fmt.Errorf("%s: nil - %w", dest, ErrorNotPointers)
+	}
+
+	t := reflect.TypeOf(v)
+
+	switch {
+	case t == reflectValueType || t == reflect.PtrTo(reflectValueType):
+		return fmt.Errorf("%s:%s - %w, pass v.Interface() instead of a reflect.Value", dest, t, ErrorNotPointers)
+	case t.Kind() == reflect.Struct:
+		return fmt.Errorf("%s:%s - %w, the struct was passed by value and would be copied, pass its address",
+			dest, t, ErrorNotPointers)
+	case t.Kind() == reflect.Map:
+		return fmt.Errorf("%s:%s - %w, map elements cannot be written in place, "+
+			"copy the element to a variable and pass its address", dest, t, ErrorNotPointers)
+	case t.Kind() != reflect.Ptr:
+		return nil
+	case reflect.ValueOf(v).IsNil():
+		return fmt.Errorf("%s:%s - %w", dest, t, ErrorNilPointer)
+	case t.Elem().Kind() == reflect.Ptr:
+		return fmt.Errorf("%s:%s - %w, pass the pointer to the struct rather than its address", dest, t, ErrorNotStruct)
+	case t.Elem().Kind() == reflect.Interface:
+		return fmt.Errorf("%s:%s - %w, writes would replace the interface value, "+
+			"pass the pointer to the struct stored in it", dest, t, ErrorNotStruct)
+	}
+
+	return nil
+}
+
+func specsFromStruct(dest path, t reflect.Type, parsers map[reflect.Type]ParserFunc) ([]*spec, []*group, error) {
 	// commands can only be created from pointers to structs
 	if t.Kind() != reflect.Ptr {
-		return nil, fmt.Errorf("%s:%s - %w",
+		return nil, nil, fmt.Errorf("%s:%s - %w",
 			dest, t.Kind(), ErrorNotPointers)
 	}
 
 	t = t.Elem()
 	if t.Kind() != reflect.Struct {
-		return nil, fmt.Errorf("%s:%s - %w",
+		return nil, nil, fmt.Errorf("%s:%s - %w",
 			dest, t.Kind(), ErrorNotStruct)
 	}
 
+	root := t
+
 	specs := make([]*spec, 0)
+	groups := make([]*group, 0)
+
+	err := walkFields(dest, t, nil, func(dest path, field reflect.StructField, t reflect.Type, grp *group) (*group, error) {
+		sp, sub, err := walker(dest, &field, t, grp, parsers)
+		if err != nil {
+			fe := &FieldError{Field: qualifiedPath(root, dest.Child(&field)), Err: err}
+			if sp != nil {
+				fe.Name = sp.name
+			}
+
+			return nil, fe
+		}
 
-	err := walkFields(t, func(field reflect.StructField, t reflect.Type) (bool, error) {
-		sp, expand, err := walker(dest, &field, t)
 		if sp != nil {
+			sp.group = grp
 			specs = append(specs, sp)
 		}
 
-		return expand, err
+		if sub != nil {
+			groups = append(groups, sub)
+		}
+
+		return sub, err
 	})
 
-	return specs, err
+	return specs, groups, err
 }
 
-func walker(dest path, field *reflect.StructField, t reflect.Type) (*spec, bool, error) {
+func walker(dest path, field *reflect.StructField, t reflect.Type, grp *group,
+	parsers map[reflect.Type]ParserFunc) (*spec, *group, error) {
 	// Check for the ignore switch in the tag
 	tag := field.Tag.Get("env")
 	if tag == "-" {
-		return nil, false, nil
+		return nil, nil, nil
 	}
 
-	// If this is an embedded struct then recurse into its fields
-	if field.Anonymous && field.Type.Kind() == reflect.Struct {
-		return nil, true, nil
+	// Unexported fields cannot be written, which is only reported when
+	// the field has an env tag
+	if field.PkgPath != "" && !field.Anonymous {
+		if _, exists := field.Tag.Lookup("env"); exists {
+			return nil, nil, fmt.Errorf("%s.%s: %w, export the field", t.Name(), field.Name, ErrorFieldIsNotWritable)
+		}
+
+		return nil, nil, nil
 	}
 
 	// duplicate the entire path to avoid slice overwrites
 	subdest := dest.Child(field)
+
+	// the types with a parser of their own are parsed like scalars
+	custom := hasParser(parsers, field.Type)
+
+	// Structs are expanded into groups, whose variables can be prefixed
+	if sub, ok, err := structGroup(subdest, field, grp); !custom && (ok || err != nil) {
+		if err == nil && sub != nil {
+			sub.prefix, err = groupPrefix(tag)
+		}
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+		}
+
+		return nil, sub, nil
+	}
+
 	sp := &spec{
 		dest: subdest,
 		name: strings.ToLower(field.Name),
@@ -249,56 +652,262 @@ func walker(dest path, field *reflect.StructField, t reflect.Type) (*spec, bool,
 
 	if defaultVal, exists := field.Tag.Lookup("default"); exists {
 		sp.setDefault(defaultVal)
+
+		if isTemplate(defaultVal) {
+			tmpl, err := newDefaultTemplate(defaultVal)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s.%s: default: %w", t.Name(), field.Name, err)
+			}
+
+			sp.defaultTemplate = tmpl
+		}
+	}
+
+	if docs, exists := field.Tag.Lookup("docs"); exists {
+		sp.docs = docs
+	}
+
+	if defaultText, exists := field.Tag.Lookup("defaultText"); exists {
+		sp.defaultText = defaultText
+	}
+
+	if levels, exists := field.Tag.Lookup("levels"); exists {
+		var err error
+		if sp.levels, err = parseLevels(levels); err != nil {
+			return nil, nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+		}
+	}
+
+	if defaultEnv, exists := field.Tag.Lookup("defaultEnv"); exists {
+		var err error
+		if sp.defaultEnv, err = parseDefaultEnv(defaultEnv); err != nil {
+			return nil, nil, fmt.Errorf("%s.%s: defaultEnv %q: %w", t.Name(), field.Name, defaultEnv, err)
+		}
+	}
+
+	if order, exists := field.Tag.Lookup("sources"); exists {
+		var err error
+		if sp.sourceOrder, err = parseSourceOrder(order); err != nil {
+			return nil, nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+		}
+	}
+
+	if tag, exists := field.Tag.Lookup("csv"); exists {
+		var err error
+		if sp.csv, err = parseCSVOptions(tag); err != nil {
+			return nil, nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+		}
+	}
+
+	if attribute, exists := field.Tag.Lookup("otel"); exists {
+		if attribute == "" {
+			return nil, nil, fmt.Errorf("%s.%s: otel: %w", t.Name(), field.Name, ErrorMalformedTag)
+		}
+
+		sp.attribute = attribute
+	}
+
+	if check, exists := field.Tag.Lookup("path"); exists {
+		var err error
+		if sp.pathCheck, err = parsePathCheck(check, field.Type); err != nil {
+			return nil, nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+		}
+	}
+
+	if encoding, exists := field.Tag.Lookup("encoding"); exists {
+		var err error
+		if sp.decode, err = charset(encoding); err != nil {
+			return nil, nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+		}
 	}
 
 	// Look at the tag
 	err := lookAtTag(tag, sp)
 	if err != nil {
-		return nil, false, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+		return nil, nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+	}
+
+	if sp.notEmpty && field.Type.Kind() == reflect.Ptr {
+		return sp, nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, ErrorNotEmptyPointer)
+	}
+
+	if sp.catchAll {
+		if !isCatchAllMap(field.Type) {
+			return sp, nil, fmt.Errorf("%s.%s: %s - %w", t.Name(), field.Name, field.Type.String(), ErrorCatchAllNotMap)
+		}
+
+		if sp.minItems > 0 || sp.maxItems > 0 {
+			return sp, nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, ErrorItemsNotSlice)
+		}
+
+		return sp, nil, nil
+	}
+
+	// Slices of structs are read from numbered variables
+	if elem, ok := indexedElem(field.Type); ok && !custom {
+		if sp.hasDefault {
+			return sp, nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, ErrorDefaultValueForSlice)
+		}
+
+		sp.elem = elem
+		sp.multiple = true
+
+		return sp, nil, nil
 	}
 
 	var parseable bool
 	parseable, sp.boolean, sp.multiple = canParse(field.Type)
 
+	if !parseable && isMap(field.Type) {
+		parseable, sp.mapped = true, true
+	}
+
+	if custom {
+		// a parser for the type itself takes the whole value, one for
+		// the elements of a slice each element of the list
+		direct := parsers[field.Type] != nil || field.Type.Kind() == reflect.Ptr && parsers[field.Type.Elem()] != nil
+		parseable, sp.boolean, sp.mapped = true, false, false
+		sp.multiple = !direct && field.Type.Kind() == reflect.Slice
+	}
+
+	if err := sp.lookAtSeparators(field); err != nil {
+		return sp, nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+	}
+
+	if err := sp.lookAtLayout(field); err != nil {
+		return sp, nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+	}
+
 	if !parseable {
-		return sp, false, fmt.Errorf("%s.%s: %s - %w", t.Name(), field.Name, field.Type.String(), ErrorFieldsAreNotSupported)
+		return sp, nil, fmt.Errorf("%s.%s: %s - %w", t.Name(), field.Name, field.Type.String(), ErrorFieldsAreNotSupported)
+	}
+
+	if (sp.minItems > 0 || sp.maxItems > 0) && !sp.multiple {
+		return sp, nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, ErrorItemsNotSlice)
+	}
+
+	if sp.csv != nil && !sp.multiple && !sp.mapped {
+		return sp, nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, ErrorCSVNotSlice)
 	}
 
-	if sp.multiple && sp.hasDefault {
-		return sp, false, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, ErrorDefaultValueForSlice)
+	if sp.invert && (!sp.boolean || sp.multiple) {
+		return sp, nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, ErrorInvertNotBool)
 	}
 
-	// if this was an embedded field then we already returned true up above
-	return sp, false, nil
+	if (sp.count || sp.levels != nil) && !isInteger(field.Type) {
+		return sp, nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, ErrorCountNotInteger)
+	}
+
+	if isSecretType(field.Type) {
+		sp.secret = true
+	}
+
+	sp.values = enumValues(field.Type)
+
+	return sp, nil, nil
 }
 
-// lookAtTag fill spec from tag annotation.
+// lookAtTag fill spec from tag annotation. See the package documentation
+// for the grammar of the tag.
 func lookAtTag(tag string, sp *spec) error {
-	for _, key := range strings.Split(tag, ",") {
-		if key == "" {
+	for _, item := range strings.Split(tag, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
 			continue
 		}
 
-		key = strings.TrimLeft(key, " ")
+		key, value, hasValue := item, "", false
+		if pos := strings.Index(item, ":"); pos != -1 {
+			key, value, hasValue = item[:pos], item[pos+1:], true
+		}
 
-		var value string
-		if pos := strings.Index(key, ":"); pos != -1 {
-			value = key[pos+1:]
-			key = key[:pos]
+		if err := lookAtTagItem(sp, key, value, hasValue); err != nil {
+			return fmt.Errorf("%q: %w", item, err)
 		}
+	}
+
+	if sp.required && sp.hasDefault {
+		return ErrorRequiredWithDefault
+	}
+
+	if sp.invert && sp.count {
+		return fmt.Errorf("'invert' and 'count' cannot be combined: %w", ErrorConflictingTag)
+	}
+
+	if sp.maxItems > 0 && sp.minItems > sp.maxItems {
+		return fmt.Errorf("minitems %d is above maxitems %d: %w", sp.minItems, sp.maxItems, ErrorMalformedTag)
+	}
+
+	return nil
+}
+
+// lookAtTagItem fill spec from a single item of the tag annotation.
+func lookAtTagItem(sp *spec, key, value string, hasValue bool) error {
+	switch key {
+	case "required", "secret", "mask", "invert", "count", "verbatim", "catchall", "unset", "notEmpty", "expand", "file":
+		if hasValue {
+			return fmt.Errorf("option %s takes no value: %w", key, ErrorMalformedTag)
+		}
+	case "name", "alias":
+		if !hasValue {
+			break
+		}
+
+		if value == "" {
+			return fmt.Errorf("%s requires a value: %w", key, ErrorMalformedTag)
+		}
+	case "minitems", "maxitems":
+		if n, err := strconv.Atoi(value); !hasValue || err != nil || n < 0 {
+			return fmt.Errorf("%s requires a number of elements: %w", key, ErrorMalformedTag)
+		}
+	default:
+		if hasValue {
+			return ErrorUnrecognizedTag
+		}
+	}
+
+	switch {
+	case key == "required":
+		sp.required = true
+	case key == "secret":
+		sp.secret = true
+	case key == "mask":
+		sp.mask = true
+	case key == "invert":
+		sp.invert = true
+	case key == "count":
+		sp.count = true
+	case key == "catchall":
+		sp.catchAll = true
+	case key == "unset":
+		sp.unset = true
+	case key == "notEmpty":
+		sp.notEmpty = true
+	case key == "expand":
+		sp.expand = true
+	case key == "file":
+		sp.file = true
+	case key == "alias" && hasValue:
+		sp.aliases = append(sp.aliases, value)
+	case key == "minitems":
+		sp.minItems, _ = strconv.Atoi(value)
+	case key == "maxitems":
+		sp.maxItems, _ = strconv.Atoi(value)
+	default:
+		name := key
 
 		switch {
-		case key == "required":
-			if sp.hasDefault {
-				return ErrorRequiredWithDefault
-			}
+		case hasValue:
+			name = value
+		case key == "verbatim":
+			name = sp.dest.fields[len(sp.dest.fields)-1].Name
+		}
 
-			sp.required = true
-		case value == "" && !sp.changeName:
-			sp.setName(key)
-		default:
-			return fmt.Errorf("%s-%w", key, ErrorUnrecognizedTag)
+		if sp.changeName {
+			return fmt.Errorf("name already set to %q: %w", sp.name, ErrorConflictingTag)
 		}
+
+		sp.setName(name)
 	}
 
 	return nil
@@ -306,50 +915,235 @@ func lookAtTag(tag string, sp *spec) error {
 
 // Parse processes the given command line option, storing the results in the field
 // of the structs from which NewParser was constructed.
+//
+// The environment is processed into copies of the structs, whose values are
+// then written to the structs while holding the lock taken by View, only if
+// every variable could be processed: a failed Parse leaves the structs
+// untouched rather than half-updated. Parse may be called again, or Reload,
+// from another goroutine while readers access the structs from View. Parse
+// and Reload run one at a time.
 func (p *Parser) Parse() error {
-	return p.process()
+	p.processMu.Lock()
+	defer p.processMu.Unlock()
+
+	return p.parseLocked()
+}
+
+// ParseMap is like Parse, but reads the variables from values only, in
+// place of the sources of the parser, the flags and the .env files, so
+// that callers and tests can provide them without changing the process
+// environment. Later calls to Parse read the sources again.
+func (p *Parser) ParseMap(values map[string]string) error {
+	p.processMu.Lock()
+	defer p.processMu.Unlock()
+
+	p.only = []Source{MapSource(values)}
+	defer func() { p.only = nil }()
+
+	return p.parseLocked()
+}
+
+// parseLocked is Parse with p.processMu held.
+func (p *Parser) parseLocked() error {
+	shadow := p.copyRoots()
+
+	var err error
+	if p.config.WaitRequired > 0 {
+		err = p.waitRequired(shadow)
+	} else {
+		err = p.process(shadow)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	changes := p.apply(shadow, true)
+	p.scrub()
+
+	if !p.parsed {
+		changes = nil
+	}
+
+	p.parsed = true
+	callbacks := append([]ChangeFunc(nil), p.onChange...)
+	p.mu.Unlock()
+
+	notify(changes, callbacks)
+
+	return nil
 }
 
 // process environment vars for the given arguments.
-func (p *Parser) captureEnvVars(specs []*spec, wasPresent map[*spec]bool) error {
+func (p *Parser) captureEnvVars(roots []reflect.Value, specs []*spec, wasPresent map[*spec]bool,
+	origins map[*spec]string) Errors {
+	var errs Errors
+
+	sources := p.sources()
+
 	for _, spec := range specs {
-		value, found := os.LookupEnv(spec.name)
+		name, value, found := spec.lookup(sources)
+		if !found && spec.defaultEnv != nil {
+			name, value, found = p.lookupFallback(spec, sources, nil)
+		}
+
 		if !found {
+			// scrubbed variables keep the value captured before they
+			// were unset
+			if spec.scrubbed != "" {
+				resolveAlloc(roots, spec.dest).Set(cloneValue(spec.scrubbedVal))
+				wasPresent[spec] = true
+				origins[spec] = spec.scrubbed
+			}
+
 			continue
 		}
 
-		if spec.multiple {
-			// expect a CSV string in an environment
-			// variable in the case of multiple values
-			values, err := csv.NewReader(strings.NewReader(value)).Read()
-			if err != nil {
-				return fmt.Errorf( // nolint:goerr113
-					"error reading a CSV string from environment variable %s with multiple values: %w",
-					spec.name,
-					err,
-				)
+		// a variable that failed to parse is still present, so that it is
+		// not also reported as missing
+		wasPresent[spec] = true
+
+		if p.config.detectsConflicts() {
+			if err := p.checkConflict(spec, sources); err != nil {
+				errs = append(errs, p.fieldError(spec, name, err))
+
+				continue
 			}
+		}
 
-			if err = setSlice(p.val(spec.dest), values); err != nil {
-				return fmt.Errorf(
-					"error processing environment variable %s with multiple values: %w",
-					spec.name,
-					err,
-				)
+		if err := p.captureValue(resolveAlloc(roots, spec.dest), spec, name, value); err != nil {
+			errs = append(errs, p.fieldError(spec, name, err))
+
+			continue
+		}
+
+		origins[spec] = spec.origin(sources, name)
+
+		if spec.unset {
+			p.scrubLater(roots, spec, sources, name, origins[spec])
+		}
+	}
+
+	return errs
+}
+
+// captureValue parses the value of a variable into dest.
+func (p *Parser) captureValue(dest reflect.Value, spec *spec, name, value string) error {
+	value, err := spec.readFileValue(p.expand(spec, value))
+	if err != nil {
+		return wrapf(p.messages.ReadFile, name, err)
+	}
+
+	if err := p.config.checkLength(p.messages, name, value); err != nil {
+		return err
+	}
+
+	if spec.notEmpty && value == "" {
+		return p.messages.variable(name, ErrorEmptyValue)
+	}
+
+	if spec.decode != nil {
+		decoded, err := spec.decode(value)
+		if err != nil {
+			return wrapf(p.messages.DecodeValue, name, spec.redactError(err, value))
+		}
+
+		value = decoded
+	}
+
+	if spec.invert {
+		inverted, err := invertBool(value)
+		if err != nil {
+			return p.messages.invalidValue(name, spec.redactError(err, value))
+		}
+
+		value = inverted
+	}
+
+	value = spec.decodeLevel(value)
+
+	if !spec.multiple {
+		relaid, err := spec.relayout(value)
+		if err != nil {
+			return p.messages.invalidValue(name, spec.redactError(err, value))
+		}
+
+		value = relaid
+	}
+
+	if spec.mapped {
+		if err := p.parseMap(dest, spec, value); err != nil {
+			return p.messages.invalidValue(name, spec.redactError(err, value))
+		}
+	} else if spec.multiple {
+		// expect a CSV string in an environment
+		// variable in the case of multiple values
+		values, err := p.csvOptions(spec).split(value)
+		if err != nil {
+			return wrapf(p.messages.ReadList, name, spec.redactError(err, value))
+		}
+
+		if err = p.config.checkElements(p.messages, name, len(values)); err != nil {
+			return err
+		}
+
+		if values, err = spec.relayoutAll(values); err != nil {
+			return p.messages.invalidValue(name, spec.redactError(err, value))
+		}
+
+		for _, v := range values {
+			if err := spec.checkValue(v); err != nil {
+				return p.messages.invalidValue(name, spec.redactError(err, v))
 			}
-		} else if err := scalar.ParseValue(p.val(spec.dest), value); err != nil {
-			return fmt.Errorf("error processing environment variable %s: %w", spec.name, err)
 		}
 
-		wasPresent[spec] = true
+		if err = p.setSlice(dest, values); err != nil {
+			return wrapf(p.messages.InvalidList, name, spec.rangeError(p.messages, spec.redactError(err, value)))
+		}
+
+		if err = spec.checkItems(p.messages, name, len(values)); err != nil {
+			return err
+		}
+	} else if err := spec.checkValue(value); err != nil {
+		return p.messages.invalidValue(name, spec.redactError(err, value))
+	} else if err := p.parseValue(dest, value); err != nil {
+		return p.messages.invalidValue(name, spec.rangeError(p.messages, spec.redactError(err, value)))
+	}
+
+	if err := spec.checkPath(dest); err != nil {
+		return p.messages.variable(name, err)
 	}
 
 	return nil
 }
 
 // process goes through arguments one-by-one, parses them, and assigns the result to
-// the underlying struct field.
-func (p *Parser) process() error {
+// the underlying struct field of the given roots. Every variable is processed
+// even if some fail, and the failures are returned together as Errors.
+func (p *Parser) process(roots []reflect.Value) error {
+	err := p.loadSources()
+	if err != nil {
+		return err
+	}
+
+	p.scrubs = nil
+	origins := make(map[*spec]string)
+
+	if errs := p.processLoaded(roots, origins); len(errs) > 0 {
+		return errs
+	}
+
+	p.mu.Lock()
+	p.origins = origins
+	p.mu.Unlock()
+
+	return nil
+}
+
+// processLoaded is process once the sources are loaded. The origins of the
+// values are recorded in origins.
+func (p *Parser) processLoaded(roots []reflect.Value, origins map[*spec]string) Errors {
 	// track the options we have seen
 	wasPresent := make(map[*spec]bool)
 
@@ -358,43 +1152,138 @@ func (p *Parser) process() error {
 	copy(specs, p.specs)
 
 	// deal with environment vars
-	err := p.captureEnvVars(specs, wasPresent)
-	if err != nil {
-		return err
-	}
+	errs := p.captureEnvVars(roots, specs, wasPresent, origins)
+	errs = append(errs, p.captureIndexed(roots, wasPresent)...)
+	errs = append(errs, p.captureCatchAll(roots, wasPresent)...)
+
+	// optional subsystems without any variable set are disabled
+	disabled := p.disabledGroups(wasPresent)
+	suggest := &suggester{p: p}
+	templated := make(map[*spec]bool)
 
 	// fill in defaults and check that all the required args were provided
 	for _, spec := range specs {
-		if wasPresent[spec] {
+		if wasPresent[spec] || spec.group.disabledIn(disabled) {
 			continue
 		}
 
 		name := spec.name
 
 		if spec.required {
-			return fmt.Errorf("%s: %w", name, ErrorFieldIsRequired)
+			errs = append(errs, p.fieldError(spec, name, p.requiredError(spec, wasPresent, suggest)))
+
+			continue
+		}
+
+		if spec.hasDefault || spec.defaultValue.IsValid() {
+			origins[spec] = OriginDefault
 		}
 
-		if spec.defaultVal != "" {
-			err := scalar.ParseValue(p.val(spec.dest), spec.defaultVal)
+		if value, ok := p.dynamicDefault(spec); ok {
+			origins[spec] = OriginDefault
+
+			if err := p.parseDefault(resolveAlloc(roots, spec.dest), spec, value); err != nil {
+				err = p.messages.invalidDefault(name, spec.redactError(err, value))
+				errs = append(errs, p.fieldError(spec, name, err))
+			}
+		} else if spec.defaultValue.IsValid() {
+			resolveAlloc(roots, spec.dest).Set(cloneValue(spec.defaultValue))
+		} else if spec.defaultTemplate != nil {
+			// rendered once the fields it refers to are filled in
+			templated[spec] = true
+
+			continue
+		} else if spec.defaultVal != "" {
+			err := p.parseDefault(resolveAlloc(roots, spec.dest), spec, spec.defaultVal)
 			if err != nil {
-				return fmt.Errorf("error processing default value for %s: %w", name, err)
+				err = p.messages.invalidDefault(name, spec.redactError(err, spec.defaultVal))
+				errs = append(errs, p.fieldError(spec, name, err))
 			}
 		}
+
+		if err := spec.checkUnsetItems(p.messages, roots); err != nil {
+			errs = append(errs, p.fieldError(spec, name, err))
+		}
+
+		if err := spec.checkPath(resolve(roots, spec.dest)); err != nil {
+			errs = append(errs, p.fieldError(spec, name, wrapf(p.messages.DefaultValue, name, err)))
+		}
 	}
 
+	for _, spec := range p.templated {
+		if templated[spec] {
+			errs = append(errs, p.renderDefault(roots, spec)...)
+		}
+	}
+
+	for _, spec := range p.compositeSpecs() {
+		if wasPresent[spec] || spec.group.disabledIn(disabled) {
+			continue
+		}
+
+		if spec.required {
+			errs = append(errs, p.fieldError(spec, spec.name, p.requiredError(spec, wasPresent, suggest)))
+
+			continue
+		}
+
+		if spec.defaultValue.IsValid() {
+			resolveAlloc(roots, spec.dest).Set(cloneValue(spec.defaultValue))
+		}
+
+		if err := spec.checkUnsetItems(p.messages, roots); err != nil {
+			errs = append(errs, p.fieldError(spec, spec.name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	p.markGroups(roots, disabled)
+
 	return nil
 }
 
+// compositeSpecs returns the slices of structs and the catch-all maps,
+// which are not parsed from a single variable.
+func (p *Parser) compositeSpecs() []*spec {
+	specs := make([]*spec, 0, len(p.indexed)+len(p.catchAll))
+	for _, x := range p.indexed {
+		specs = append(specs, x.spec)
+	}
+
+	return append(specs, p.catchAll...)
+}
+
 // val returns a reflect.Value corresponding to the current value for the
 // given path.
 func (p *Parser) val(dest path) reflect.Value {
-	v := p.roots[dest.root]
+	return resolve(p.roots, dest)
+}
+
+// resolve returns a reflect.Value corresponding to the given path within
+// the given roots, or an invalid value if the path crosses a nil pointer.
+func resolve(roots []reflect.Value, dest path) reflect.Value {
+	return resolvePath(roots, dest, false)
+}
+
+// resolveAlloc is like resolve, but allocates the nil pointers on the way.
+func resolveAlloc(roots []reflect.Value, dest path) reflect.Value {
+	return resolvePath(roots, dest, true)
+}
+
+func resolvePath(roots []reflect.Value, dest path, alloc bool) reflect.Value {
+	v := roots[dest.root]
 
 	for _, field := range dest.fields {
 		if v.Kind() == reflect.Ptr {
 			if v.IsNil() {
-				return reflect.Value{}
+				if !alloc {
+					return reflect.Value{}
+				}
+
+				v.Set(reflect.New(v.Type().Elem()))
 			}
 
 			v = v.Elem()
@@ -416,7 +1305,7 @@ func (p *Parser) val(dest path) reflect.Value {
 }
 
 // parse a value as the appropriate type and store it in the struct.
-func setSlice(dest reflect.Value, values []string) error {
+func (p *Parser) setSlice(dest reflect.Value, values []string) error {
 	if !dest.CanSet() {
 		return ErrorFieldIsNotWritable
 	}
@@ -424,19 +1313,18 @@ func setSlice(dest reflect.Value, values []string) error {
 	var ptr bool
 
 	elem := dest.Type().Elem()
-	if elem.Kind() == reflect.Ptr && !elem.Implements(textUnmarshalerType) {
+	if elem.Kind() == reflect.Ptr && !elem.Implements(textUnmarshalerType) && !isUnmarshaler(elem.Elem()) {
 		ptr = true
 		elem = elem.Elem()
 	}
 
-	// Truncate the dest slice in case default values exist
-	if !dest.IsNil() {
-		dest.SetLen(0)
-	}
+	// Start from a fresh slice in case default values exist, since the
+	// backing array may be shared with a copy of the destination struct
+	dest.Set(reflect.MakeSlice(dest.Type(), 0, len(values)))
 
 	for _, s := range values {
 		v := reflect.New(elem)
-		if err := scalar.ParseValue(v.Elem(), s); err != nil {
+		if err := p.parseValue(v.Elem(), s); err != nil {
 			return err
 		}
 
@@ -450,13 +1338,111 @@ func setSlice(dest reflect.Value, values []string) error {
 	return nil
 }
 
+// invertBool parses a boolean and returns its negation.
+func invertBool(s string) (string, error) {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.FormatBool(!b), nil
+}
+
+// formatDefault formats the value of a field for display as its default
+// value. Structs that can only be unmarshaled from text have no sensible
+// rendering and are displayed as empty.
+func formatDefault(v reflect.Value) (string, error) {
+	m, ok := v.Interface().(encoding.TextMarshaler)
+	if !ok && v.CanAddr() {
+		m, ok = v.Addr().Interface().(encoding.TextMarshaler)
+	}
+
+	if ok {
+		str, err := m.MarshalText()
+
+		return string(str), err
+	}
+
+	if str, ok := stringSetter(v); ok {
+		return str, nil
+	}
+
+	if d, ok := v.Interface().(time.Duration); ok {
+		return formatDuration(d), nil
+	}
+
+	if isFileMode(v.Type()) {
+		return formatFileMode(v), nil
+	}
+
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		return formatDefault(v.Elem())
+	case reflect.Slice:
+		// in the CSV form of the default tag
+		values := make([]string, v.Len())
+		for i := range values {
+			value, err := formatDefault(v.Index(i))
+			if err != nil {
+				return "", err
+			}
+
+			values[i] = value
+		}
+
+		return formatCSV(values), nil
+	case reflect.Struct:
+		return "", nil
+	default:
+		return fmt.Sprintf("%v", v), nil
+	}
+}
+
+// cloneValue returns a copy of v that does not share the storage of v,
+// copying the backing array of slices and the target of pointers.
+func cloneValue(v reflect.Value) reflect.Value {
+	if isBigNumber(v.Type()) {
+		return cloneBigNumber(v)
+	}
+
+	if v.Kind() == reflect.Map {
+		return cloneMap(v)
+	}
+
+	switch v.Kind() {
+	case reflect.Slice:
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(c, v)
+
+		return c
+	case reflect.Ptr:
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(v.Elem())
+
+		return c
+	default:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+
+		return c
+	}
+}
+
 // isZero returns true if v contains the zero value for its type.
 func isZero(v reflect.Value) bool {
 	t := v.Type()
-	if t.Kind() == reflect.Slice {
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
 		return v.IsNil()
 	}
 
+	if isBigNumber(t) && t.Kind() != reflect.Ptr {
+		return v.IsZero()
+	}
+
 	if !t.Comparable() {
 		return false
 	}
@@ -1,10 +1,14 @@
 package env
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"net"
 	"net/mail"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,6 +18,8 @@ import (
 
 type envsMap = map[string]string
 
+var errTestMissingPeriod = errors.New("missing period")
+
 func parse(envs envsMap, dest interface{}) error {
 	_, err := pparse(envs, dest)
 
@@ -367,6 +373,57 @@ func TestErrorOnNonStruct(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestErrorOnLostWrites(t *testing.T) {
+	type config struct {
+		Foo string
+	}
+
+	var nilPtr *config
+
+	err := parse(envsMap{}, nilPtr)
+	assert.True(t, errors.Is(err, ErrorNilPointer))
+
+	ptr := &config{}
+	err = parse(envsMap{}, &ptr)
+	assert.True(t, errors.Is(err, ErrorNotStruct))
+	assert.Contains(t, err.Error(), "pass the pointer to the struct")
+
+	var iface interface{} = ptr
+	err = parse(envsMap{}, &iface)
+	assert.True(t, errors.Is(err, ErrorNotStruct))
+
+	err = parse(envsMap{}, map[string]config{"a": {}})
+	assert.True(t, errors.Is(err, ErrorNotPointers))
+	assert.Contains(t, err.Error(), "map elements cannot be written in place")
+
+	err = parse(envsMap{}, reflect.ValueOf(ptr))
+	assert.True(t, errors.Is(err, ErrorNotPointers))
+	assert.Contains(t, err.Error(), "pass v.Interface()")
+
+	err = parse(envsMap{}, nil)
+	assert.True(t, errors.Is(err, ErrorNotPointers))
+}
+
+func TestUnexportedFields(t *testing.T) {
+	var envs struct {
+		Foo string
+		bar string
+	}
+
+	err := parse(envsMap{"foo": "a", "bar": "b"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "a", envs.Foo)
+	assert.Equal(t, "", envs.bar)
+
+	var tagged struct {
+		bar string `env:"BAR"`
+	}
+
+	err = parse(envsMap{}, &tagged)
+	assert.True(t, errors.Is(err, ErrorFieldIsNotWritable))
+	assert.Equal(t, "", tagged.bar)
+}
+
 func TestUnsupportedType(t *testing.T) {
 	var envs struct {
 		Foo interface{}
@@ -391,15 +448,58 @@ func TestUnknownTag(t *testing.T) {
 	}
 
 	err := parse(envsMap{"foo": "xyz"}, &envs)
-	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrorUnrecognizedTag))
+	assert.EqualError(t, err, `.Foo: "this_is_not_valid:1": unrecognized tag`)
 
 	var envs2 struct {
 		Foo string `env:"name,name2"`
 	}
 
 	err = parse(envsMap{"name": "xyz"}, &envs2)
-	assert.Error(t, err)
-	assert.EqualError(t, err, ".Foo: name2-unrecognized tag")
+	assert.True(t, errors.Is(err, ErrorConflictingTag))
+	assert.EqualError(t, err, `.Foo: "name2": name already set to "name": conflicting tag options`)
+}
+
+func TestMalformedTag(t *testing.T) {
+	var envs struct {
+		Foo string `env:"required:yes"`
+	}
+
+	err := parse(envsMap{}, &envs)
+	assert.True(t, errors.Is(err, ErrorMalformedTag))
+	assert.EqualError(t, err, `.Foo: "required:yes": option required takes no value: malformed tag`)
+
+	var envs2 struct {
+		Foo string `env:"alias:"`
+	}
+
+	err = parse(envsMap{}, &envs2)
+	assert.True(t, errors.Is(err, ErrorMalformedTag))
+	assert.EqualError(t, err, `.Foo: "alias:": alias requires a value: malformed tag`)
+}
+
+func TestConflictingTag(t *testing.T) {
+	var envs struct {
+		Foo string `env:"foo, name:bar"`
+	}
+
+	err := parse(envsMap{}, &envs)
+	assert.True(t, errors.Is(err, ErrorConflictingTag))
+
+	var envs2 struct {
+		Foo int `env:"foo, invert, count"`
+	}
+
+	err = parse(envsMap{}, &envs2)
+	assert.True(t, errors.Is(err, ErrorConflictingTag))
+
+	var envs3 struct {
+		Foo int `env:" foo , required ,alias:bar "`
+	}
+
+	err = parse(envsMap{"bar": "1"}, &envs3)
+	require.NoError(t, err)
+	assert.Equal(t, 1, envs3.Foo)
 }
 
 func TestParse(t *testing.T) {
@@ -433,6 +533,25 @@ func TestMustParse(t *testing.T) {
 	assert.NotNil(t, parser)
 }
 
+func TestMustParseFails(t *testing.T) {
+	var envs struct {
+		Workers int `env:"required"`
+	}
+
+	var out bytes.Buffer
+
+	code := 0
+
+	p, err := NewParser(Config{Sources: []Source{MapSource{}}, Out: &out, Exit: func(c int) { code = c }}, &envs)
+	require.NoError(t, err)
+
+	err = p.MustParse()
+	assert.True(t, errors.Is(err, ErrorFieldIsRequired))
+	assert.Equal(t, 2, code)
+	assert.Equal(t, "error: workers: field is required (set workers)\n\n"+
+		"Environments:\n  workers int (required)\n", out.String())
+}
+
 type textUnmarshaler struct {
 	val int
 }
@@ -638,13 +757,49 @@ func TestEmbedded(t *testing.T) {
 }
 
 func TestEmbeddedPtr(t *testing.T) {
-	// embedded pointer fields are not supported so this should return an error
+	// embedded pointer fields are allocated when one of their variables is set
 	var envs struct {
 		*A
 	}
 
 	err := parse(envsMap{"x": "hello"}, &envs)
-	require.Error(t, err)
+	require.NoError(t, err)
+	require.NotNil(t, envs.A)
+	assert.Equal(t, "hello", envs.X)
+}
+
+func TestEmbeddedPtrUnset(t *testing.T) {
+	// and left nil when none is set
+	var envs struct {
+		*A
+		B
+	}
+
+	err := parse(envsMap{"y": "321"}, &envs)
+	require.NoError(t, err)
+	assert.Nil(t, envs.A)
+	assert.Equal(t, 321, envs.Y)
+}
+
+func TestNestedPtr(t *testing.T) {
+	type DBConfig struct {
+		Host string `env:"db_host,required"`
+		Port int    `env:"db_port" default:"5432"`
+	}
+
+	var envs struct {
+		DB *DBConfig
+	}
+
+	require.NoError(t, parse(envsMap{}, &envs))
+	assert.Nil(t, envs.DB)
+
+	require.NoError(t, parse(envsMap{"db_host": "db"}, &envs))
+	require.NotNil(t, envs.DB)
+	assert.Equal(t, DBConfig{Host: "db", Port: 5432}, *envs.DB)
+
+	err := parse(envsMap{"db_port": "5433"}, &envs)
+	assert.True(t, errors.Is(err, ErrorFieldIsRequired))
 }
 
 func TestEmbeddedPtrIgnored(t *testing.T) {
@@ -661,6 +816,44 @@ func TestEmbeddedPtrIgnored(t *testing.T) {
 	assert.Equal(t, 321, envs.Y)
 }
 
+type serverConfig struct {
+	Host string
+	Port int
+}
+
+type clientConfig struct {
+	Endpoint string `env:"url,alias:host"`
+}
+
+func TestSharedNames(t *testing.T) {
+	var server serverConfig
+	var client clientConfig
+
+	_, err := NewParser(Config{}, &server, &client)
+	assert.True(t, errors.Is(err, ErrorSharedName))
+	assert.EqualError(t, err,
+		"host: serverConfig.Host and clientConfig.Endpoint: variable bound to fields of several destination structs")
+
+	var a, b struct {
+		Port int
+	}
+
+	_, err = NewParser(Config{}, &a, &b)
+	assert.EqualError(t, err, "port: #1.Port and #2.Port: variable bound to fields of several destination structs")
+}
+
+func TestAllowSharedNames(t *testing.T) {
+	var server serverConfig
+	var client clientConfig
+
+	p, err := NewParser(Config{Sources: []Source{MapSource{"host": "example.com"}}, AllowSharedNames: true},
+		&server, &client)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, "example.com", server.Host)
+	assert.Equal(t, "example.com", client.Endpoint)
+}
+
 func TestEmbeddedWithDuplicateField(t *testing.T) {
 	type T struct {
 		A string `env:"cat"`
@@ -719,6 +912,32 @@ func TestReuseParser(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestParseMap(t *testing.T) {
+	os.Clearenv()
+	require.NoError(t, os.Setenv("foo", "env"))
+
+	defer os.Clearenv()
+
+	var envs struct {
+		Foo string `env:"required"`
+		Bar int    `default:"1"`
+	}
+
+	p, err := NewParser(Config{}, &envs)
+	require.NoError(t, err)
+
+	require.NoError(t, p.ParseMap(map[string]string{"foo": "map", "bar": "2"}))
+	assert.Equal(t, "map", envs.Foo)
+	assert.Equal(t, 2, envs.Bar)
+
+	// the map replaces the sources of the parser
+	err = p.ParseMap(map[string]string{"bar": "3"})
+	assert.True(t, errors.Is(err, ErrorFieldIsRequired))
+
+	require.NoError(t, p.Parse())
+	assert.Equal(t, "env", envs.Foo)
+}
+
 func TestDefaultOptionValues(t *testing.T) {
 	var envs struct {
 		A int      `default:"123"`
@@ -762,13 +981,39 @@ func TestDefaultValuesNotAllowedWithRequired(t *testing.T) {
 	assert.EqualError(t, err, ".A: 'required' cannot be used when a default value is specified")
 }
 
-func TestDefaultValuesNotAllowedWithSlice(t *testing.T) {
+func TestSliceDefault(t *testing.T) {
+	var envs struct {
+		A []int    `default:"1,2,3"`
+		B []string `default:"x,\"y,z\""`
+	}
+
+	err := parse(envsMap{}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, envs.A)
+	assert.Equal(t, []string{"x", "y,z"}, envs.B)
+
+	err = parse(envsMap{"a": "4"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, []int{4}, envs.A)
+}
+
+func TestSliceDefaultHelp(t *testing.T) {
+	var envs struct {
+		A []int `default:"1,2,3"`
+	}
+
+	p, err := NewParser(Config{}, &envs)
+	require.NoError(t, err)
+	assert.Contains(t, p.Help(), "default: 1,2,3")
+}
+
+func TestInvalidSliceDefault(t *testing.T) {
 	var envs struct {
-		A []int `default:"123"` // required not allowed with default!
+		A []int `default:"1,x"`
 	}
 
 	err := parse(envsMap{}, &envs)
-	assert.EqualError(t, err, ".A: default values are not supported for slice fields")
+	assert.EqualError(t, err, `error processing default value for a: strconv.ParseInt: parsing "x": invalid syntax`)
 }
 
 func TestMultipleOptions(t *testing.T) {
@@ -783,3 +1028,200 @@ func TestMultipleOptions(t *testing.T) {
 	assert.True(t, errors.Is(err, ErrorFieldIsRequired))
 	assert.EqualError(t, err, "a: field is required")
 }
+
+func TestNameKey(t *testing.T) {
+	var envs struct {
+		Foo string `env:"name:FOO_BAR"`
+	}
+
+	err := parse(envsMap{"FOO_BAR": "xyz"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "xyz", envs.Foo)
+}
+
+func TestInvert(t *testing.T) {
+	var envs struct {
+		EnableCache bool  `env:"name:DISABLE_CACHE,invert"`
+		EnableLogs  bool  `env:"name:DISABLE_LOGS,invert" default:"true"`
+		EnableDebug *bool `env:"name:NO_DEBUG,invert"`
+	}
+
+	err := parse(envsMap{"DISABLE_CACHE": "false", "NO_DEBUG": "1"}, &envs)
+	require.NoError(t, err)
+	assert.True(t, envs.EnableCache)
+	assert.True(t, envs.EnableLogs)
+	require.NotNil(t, envs.EnableDebug)
+	assert.False(t, *envs.EnableDebug)
+
+	envs.EnableDebug = nil
+	err = parse(envsMap{"DISABLE_CACHE": "true", "DISABLE_LOGS": "true"}, &envs)
+	require.NoError(t, err)
+	assert.False(t, envs.EnableCache)
+	assert.False(t, envs.EnableLogs)
+
+	err = parse(envsMap{"DISABLE_CACHE": "maybe"}, &envs)
+	assert.EqualError(t, err, `error processing environment variable DISABLE_CACHE: strconv.ParseBool: parsing "maybe": invalid syntax`)
+}
+
+func TestInvertNotBool(t *testing.T) {
+	var envs struct {
+		Workers int `env:"invert"`
+	}
+
+	err := parse(envsMap{}, &envs)
+	assert.True(t, errors.Is(err, ErrorInvertNotBool))
+}
+
+func TestAliases(t *testing.T) {
+	var envs struct {
+		URL string `env:"name:DATABASE_URL, alias:DB_URL, alias:POSTGRES_URL"`
+	}
+
+	err := parse(envsMap{"POSTGRES_URL": "c"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "c", envs.URL)
+
+	err = parse(envsMap{"DB_URL": "b", "POSTGRES_URL": "c"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "b", envs.URL)
+
+	err = parse(envsMap{"DATABASE_URL": "a", "DB_URL": "b", "POSTGRES_URL": "c"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "a", envs.URL)
+}
+
+func TestAliasesErrorMentionsAlias(t *testing.T) {
+	var envs struct {
+		Port int `env:"name:PORT, alias:HTTP_PORT, required"`
+	}
+
+	err := parse(envsMap{"HTTP_PORT": "x"}, &envs)
+	assert.EqualError(t, err, `error processing environment variable HTTP_PORT: strconv.ParseInt: parsing "x": invalid syntax`)
+
+	err = parse(envsMap{}, &envs)
+	assert.True(t, errors.Is(err, ErrorFieldIsRequired))
+}
+
+func TestVerbatim(t *testing.T) {
+	var envs struct {
+		TestEnv string `env:"verbatim"`
+		Other   string `env:"verbatim, alias:OTHER"`
+	}
+
+	err := parse(envsMap{"TestEnv": "a", "testenv": "b", "OTHER": "c"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "a", envs.TestEnv)
+	assert.Equal(t, "c", envs.Other)
+
+	var conflict struct {
+		TestEnv string `env:"test_env, verbatim"`
+	}
+
+	err = parse(envsMap{}, &conflict)
+	assert.True(t, errors.Is(err, ErrorConflictingTag))
+}
+
+type onlyUnmarshaler struct {
+	Head, Tail string
+}
+
+func (n *onlyUnmarshaler) UnmarshalText(b []byte) error {
+	s := string(b)
+	pos := strings.Index(s, ".")
+
+	if pos == -1 {
+		return fmt.Errorf("%s: %w", s, errTestMissingPeriod)
+	}
+
+	n.Head, n.Tail = s[:pos], s[pos+1:]
+
+	return nil
+}
+
+func TestProgrammaticDefaults(t *testing.T) {
+	var envs struct {
+		Values []int
+		Ptr    *int
+		Name   onlyUnmarshaler
+	}
+
+	n := 8
+	envs.Values = []int{1, 2}
+	envs.Ptr = &n
+	envs.Name = onlyUnmarshaler{"file", "txt"}
+
+	p, err := pparse(envsMap{"values": "3"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, []int{3}, envs.Values)
+	assert.Equal(t, 8, *envs.Ptr)
+	assert.Equal(t, onlyUnmarshaler{"file", "txt"}, envs.Name)
+
+	os.Clearenv()
+	require.NoError(t, p.Parse())
+	assert.Equal(t, []int{1, 2}, envs.Values)
+
+	// the defaults are copies of the original values
+	envs.Values[0] = 42
+	*envs.Ptr = 42
+	require.NoError(t, p.Parse())
+	assert.Equal(t, []int{1, 2}, envs.Values)
+	assert.Equal(t, 8, *envs.Ptr)
+	assert.Equal(t, 8, n)
+}
+
+func TestNotEmpty(t *testing.T) {
+	var envs struct {
+		Host  string `env:"notEmpty"`
+		Label string
+	}
+
+	err := parse(envsMap{"host": "", "label": ""}, &envs)
+
+	var errs Errors
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs, 1)
+	assert.Equal(t, "host", errs[0].Name)
+	assert.True(t, errors.Is(err, ErrorEmptyValue))
+	assert.Equal(t, "set host to a non-empty value or unset it", errs[0].Hint)
+
+	require.NoError(t, parse(envsMap{"label": ""}, &envs))
+	require.NoError(t, parse(envsMap{"host": "h"}, &envs))
+	assert.Equal(t, "h", envs.Host)
+
+	var ptr struct {
+		Host *string `env:"notEmpty"`
+	}
+
+	_, err = NewParser(Config{}, &ptr)
+	assert.True(t, errors.Is(err, ErrorNotEmptyPointer))
+}
+
+func TestNestedStruct(t *testing.T) {
+	type DBConfig struct {
+		Host string `env:"db_host,required"`
+		Port int    `env:"db_port" default:"5432"`
+	}
+
+	var envs struct {
+		DB      DBConfig
+		Started time.Time `default:"2020-01-02T03:04:05Z"`
+		Inner   struct {
+			Deeper struct {
+				Level int
+			}
+		}
+	}
+
+	p, err := pparse(envsMap{"db_host": "db", "level": "3"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, DBConfig{Host: "db", Port: 5432}, envs.DB)
+	assert.Equal(t, 2020, envs.Started.Year())
+	assert.Equal(t, 3, envs.Inner.Deeper.Level)
+
+	info, ok := p.FieldInfo("level")
+	require.True(t, ok)
+	assert.Equal(t, "Inner.Deeper.Level", info.Path)
+
+	err = parse(envsMap{}, &envs)
+	assert.True(t, errors.Is(err, ErrorFieldIsRequired))
+}
@@ -0,0 +1,73 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ParserFunc parses the value of a variable into a value of the type it is
+// registered for in Config.Parsers.
+type ParserFunc func(value string) (interface{}, error)
+
+// hasParser returns true if one of the parsers is registered for t, for
+// the pointers to t, or for the elements of the slice t.
+func hasParser(parsers map[reflect.Type]ParserFunc, t reflect.Type) bool {
+	if len(parsers) == 0 {
+		return false
+	}
+
+	for {
+		if parsers[t] != nil {
+			return true
+		}
+
+		if t.Kind() != reflect.Ptr && t.Kind() != reflect.Slice {
+			return false
+		}
+
+		t = t.Elem()
+	}
+}
+
+// parseValue parses value into dest with the parser of Config.Parsers
+// registered for its type or, for pointers, for the type they point to,
+// and with the parsing of the package otherwise.
+func (p *Parser) parseValue(dest reflect.Value, value string) error {
+	t := dest.Type()
+
+	if parse := p.config.Parsers[t]; parse != nil {
+		return setParsed(dest, parse, value)
+	}
+
+	if t.Kind() == reflect.Ptr {
+		if parse := p.config.Parsers[t.Elem()]; parse != nil {
+			v := reflect.New(t.Elem())
+			if err := setParsed(v.Elem(), parse, value); err != nil {
+				return err
+			}
+
+			dest.Set(v)
+
+			return nil
+		}
+	}
+
+	return parseValue(dest, value)
+}
+
+// setParsed sets dest to the value returned by parse.
+func setParsed(dest reflect.Value, parse ParserFunc, value string) error {
+	parsed, err := parse(value)
+	if err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(parsed)
+	if !v.IsValid() || !v.Type().AssignableTo(dest.Type()) {
+		return fmt.Errorf("parser of %s returned %T: %w", dest.Type(), parsed, ErrorTypeMismatch)
+	}
+
+	dest.Set(v)
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// version is a third-party like type, parsed only through Config.Parsers.
+type version struct {
+	Major, Minor int
+}
+
+func parseVersion(value string) (interface{}, error) {
+	var v version
+
+	if err := parseValue(reflect.ValueOf(&v.Major).Elem(), strings.SplitN(value, ".", 2)[0]); err != nil {
+		return nil, err
+	}
+
+	if i := strings.Index(value, "."); i >= 0 {
+		if err := parseValue(reflect.ValueOf(&v.Minor).Elem(), value[i+1:]); err != nil {
+			return nil, err
+		}
+	}
+
+	return v, nil
+}
+
+func parseWithParsers(envs envsMap, parsers map[reflect.Type]ParserFunc, dest interface{}) error {
+	p, err := NewParser(Config{Sources: []Source{MapSource(envs)}, Parsers: parsers}, dest)
+	if err != nil {
+		return err
+	}
+
+	return p.Parse()
+}
+
+func TestParsers(t *testing.T) {
+	var envs struct {
+		Version  version
+		Pinned   *version
+		Versions []version `default:"1.0"`
+		Name     upper
+	}
+
+	parsers := map[reflect.Type]ParserFunc{
+		reflect.TypeOf(version{}): parseVersion,
+		reflect.TypeOf(upper("")): func(value string) (interface{}, error) {
+			return upper("custom " + value), nil
+		},
+	}
+
+	err := parseWithParsers(envsMap{
+		"version":  "2.1",
+		"pinned":   "3",
+		"versions": "1.2,1.3",
+		"name":     "web",
+	}, parsers, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, version{2, 1}, envs.Version)
+	assert.Equal(t, &version{3, 0}, envs.Pinned)
+	assert.Equal(t, []version{{1, 2}, {1, 3}}, envs.Versions)
+	assert.Equal(t, upper("custom web"), envs.Name)
+}
+
+func TestParsersWithoutRegistry(t *testing.T) {
+	var envs struct {
+		Version version
+	}
+
+	// without a parser the struct is expanded into its fields
+	err := parse(envsMap{"major": "4"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, version{Major: 4}, envs.Version)
+}
+
+func TestParsersErrors(t *testing.T) {
+	var envs struct {
+		Version version
+	}
+
+	errParse := errors.New("bad version")
+	err := parseWithParsers(envsMap{"version": "x"}, map[reflect.Type]ParserFunc{
+		reflect.TypeOf(version{}): func(string) (interface{}, error) { return nil, errParse },
+	}, &envs)
+	assert.True(t, errors.Is(err, errParse))
+
+	err = parseWithParsers(envsMap{"version": "1"}, map[reflect.Type]ParserFunc{
+		reflect.TypeOf(version{}): func(string) (interface{}, error) { return "1", nil },
+	}, &envs)
+	assert.True(t, errors.Is(err, ErrorTypeMismatch))
+}
@@ -0,0 +1,121 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// Path is a file system path, expanded when parsed: a leading ~ is replaced
+// by the home directory of the user and relative paths are made absolute.
+// The path tag of a Path field checks the path when it is parsed:
+// path:"mustExist" requires it to exist, and path:"mustDir" to be an
+// existing directory.
+type Path string
+
+var envPathType = reflect.TypeOf(Path("")) // nolint:gochecknoglobals
+
+// UnmarshalText expands and makes the path absolute. The empty path is left
+// empty.
+func (p *Path) UnmarshalText(text []byte) error {
+	s := string(text)
+	if s == "" {
+		*p = ""
+
+		return nil
+	}
+
+	if s == "~" || strings.HasPrefix(s, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+
+		s = home + s[1:]
+	}
+
+	abs, err := filepath.Abs(s)
+	if err != nil {
+		return err
+	}
+
+	*p = Path(abs)
+
+	return nil
+}
+
+// String returns the path.
+func (p Path) String() string {
+	return string(p)
+}
+
+// pathCheck is the check of the path tag.
+type pathCheck int
+
+const (
+	pathExists pathCheck = iota + 1
+	pathIsDir
+)
+
+// parsePathCheck parses the path tag of a field of type t.
+func parsePathCheck(tag string, t reflect.Type) (pathCheck, error) {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+
+	if t != envPathType {
+		return 0, ErrorPathTagNotPath
+	}
+
+	switch tag {
+	case "mustExist":
+		return pathExists, nil
+	case "mustDir":
+		return pathIsDir, nil
+	}
+
+	return 0, fmt.Errorf("path %q: %w", tag, ErrorUnrecognizedTag)
+}
+
+// checkPath checks the paths of the field at v against the path tag. Empty
+// paths are not checked.
+func (s *spec) checkPath(v reflect.Value) error {
+	if s.pathCheck == 0 || !v.IsValid() {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+
+		return s.checkPath(v.Elem())
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := s.checkPath(v.Index(i)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	path := v.String()
+	if path == "" {
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if s.pathCheck == pathIsDir && !info.IsDir() {
+		return fmt.Errorf("%s: %w", path, ErrorNotDirectory)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPath(t *testing.T) {
+	var envs struct {
+		Config Path
+		Data   Path `default:"~/data"`
+		Dirs   []Path
+	}
+
+	home := "/home/gopher"
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	p, err := pparse(envsMap{"HOME": home, "config": "conf/app.toml", "dirs": "/tmp,~"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, Path(filepath.Join(wd, "conf/app.toml")), envs.Config)
+	assert.Equal(t, Path(filepath.Join(home, "data")), envs.Data)
+	assert.Equal(t, []Path{"/tmp", Path(home)}, envs.Dirs)
+	assert.Equal(t, "Environments:\n  config path\n  data path [default: ~/data]\n  dirs path,...\n", p.Help())
+}
+
+func TestPathCheck(t *testing.T) {
+	var envs struct {
+		Config Path   `path:"mustExist"`
+		Data   Path   `path:"mustDir"`
+		Cache  *Path  `path:"mustDir"`
+		Dirs   []Path `path:"mustDir"`
+	}
+
+	require.NoError(t, parse(envsMap{"config": "testdata/secrets/user", "data": "testdata", "dirs": "testdata"}, &envs))
+	assert.Nil(t, envs.Cache)
+
+	err := parse(envsMap{"config": "testdata/missing", "data": "testdata/secrets/user", "dirs": "testdata,testdata/none"}, &envs)
+
+	var errs Errors
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs, 3)
+	assert.True(t, errors.Is(errs[0], os.ErrNotExist))
+	assert.Equal(t, "expected an existing path", errs[0].Hint)
+	assert.True(t, errors.Is(errs[1], ErrorNotDirectory))
+	assert.Equal(t, "expected a directory", errs[1].Hint)
+	assert.True(t, errors.Is(errs[2], os.ErrNotExist))
+}
+
+func TestPathCheckDefault(t *testing.T) {
+	var envs struct {
+		Data Path `default:"testdata/missing" path:"mustDir"`
+	}
+
+	err := parse(envsMap{}, &envs)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestPathTag(t *testing.T) {
+	var str struct {
+		Config string `path:"mustExist"`
+	}
+
+	_, err := NewParser(Config{}, &str)
+	assert.True(t, errors.Is(err, ErrorPathTagNotPath))
+
+	var unknown struct {
+		Config Path `path:"readable"`
+	}
+
+	_, err = NewParser(Config{}, &unknown)
+	assert.True(t, errors.Is(err, ErrorUnrecognizedTag))
+}
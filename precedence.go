@@ -0,0 +1,163 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Named is implemented by the sources that have a name, which the sources
+// tag of a field refers to. The process environment is named "env" and the
+// flags registered with RegisterFlags are named "flags".
+type Named interface {
+	// SourceName returns the name of the source.
+	SourceName() string
+}
+
+// namedSource gives a name to a source.
+type namedSource struct {
+	name string
+	Source
+}
+
+// NamedSource returns src under the given name, so that fields can give it
+// precedence over the other sources with the sources tag. Sources
+// implementing Loader, Prefetcher or Lister keep doing so.
+func NamedSource(name string, src Source) Source {
+	return namedSource{name: name, Source: src}
+}
+
+// SourceName returns the name of the source.
+func (s namedSource) SourceName() string {
+	return s.name
+}
+
+// Load loads the underlying source if it implements Loader.
+func (s namedSource) Load() error {
+	if l, ok := s.Source.(Loader); ok {
+		return l.Load()
+	}
+
+	return nil
+}
+
+// Prefetch prefetches the variables of the underlying source if it
+// implements Prefetcher.
+func (s namedSource) Prefetch(names []string) error {
+	if f, ok := s.Source.(Prefetcher); ok {
+		return f.Prefetch(names)
+	}
+
+	return nil
+}
+
+// Names returns the names of the variables of the underlying source if it
+// implements Lister.
+func (s namedSource) Names() []string {
+	if l, ok := s.Source.(Lister); ok {
+		return l.Names()
+	}
+
+	return nil
+}
+
+// sourceName returns the name of src, or "" if it has none.
+func sourceName(src Source) string {
+	if n, ok := src.(Named); ok {
+		return n.SourceName()
+	}
+
+	return ""
+}
+
+// parseSourceOrder parses a sources tag such as "file,env".
+func parseSourceOrder(tag string) ([]string, error) {
+	names := strings.Split(tag, ",")
+
+	for i, name := range names {
+		names[i] = strings.TrimSpace(name)
+		if names[i] == "" {
+			return nil, fmt.Errorf("sources %q: %w", tag, ErrorMalformedTag)
+		}
+	}
+
+	return names, nil
+}
+
+// ordered returns the sources in the order in which the option looks them
+// up: the sources named in its sources tag first, in the order of the tag,
+// then the others in their usual order.
+func (s *spec) ordered(sources []Source) []Source {
+	if s.sourceOrder == nil {
+		return sources
+	}
+
+	ordered := make([]Source, 0, len(sources))
+	for _, i := range s.order(sources) {
+		ordered = append(ordered, sources[i])
+	}
+
+	return ordered
+}
+
+// order returns the indexes of the sources in the order in which the option
+// looks them up, see ordered.
+func (s *spec) order(sources []Source) []int {
+	order := make([]int, 0, len(sources))
+
+	for _, name := range s.sourceOrder {
+		for i, src := range sources {
+			if sourceName(src) == name {
+				order = append(order, i)
+			}
+		}
+	}
+
+	for i, src := range sources {
+		if !containsString(s.sourceOrder, sourceName(src)) {
+			order = append(order, i)
+		}
+	}
+
+	return order
+}
+
+// checkSourceOrder returns an error if an option names a source that the
+// parser does not have.
+func (p *Parser) checkSourceOrder(specs []*spec) error {
+	names := []string{"flags"}
+	for _, src := range p.sources() {
+		names = append(names, sourceName(src))
+	}
+
+	for _, spec := range specs {
+		for _, name := range spec.sourceOrder {
+			if !containsString(names, name) {
+				return fmt.Errorf("%s: source %q: %w", spec.name, name, ErrorUnknownSource)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Lookup returns the value of the variable with the given name or alias
+// the way Parse would find it, and the name of the source providing it,
+// which is empty for unnamed sources. Fallbacks and defaults are not
+// considered. It is meant for debugging migrations between sources.
+func (p *Parser) Lookup(name string) (value, source string, found bool) {
+	for _, spec := range p.specs {
+		if spec.name != name && !containsString(spec.aliases, name) {
+			continue
+		}
+
+		for _, src := range spec.ordered(p.sources()) {
+			if value, found := src.Lookup(name); found {
+				return value, sourceName(src), true
+			}
+		}
+
+		return "", "", false
+	}
+
+	return "", "", false
+}
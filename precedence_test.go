@@ -0,0 +1,81 @@
+package env
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceOrder(t *testing.T) {
+	var envs struct {
+		Host    string
+		Port    int    `sources:"file"`
+		Name    string `sources:"vault, file"`
+		Workers int    `sources:"vault"`
+	}
+
+	p, err := NewParser(Config{Sources: []Source{
+		NamedSource("env", MapSource{"host": "env", "port": "1", "name": "env"}),
+		NamedSource("file", MapSource{"host": "file", "port": "2", "name": "file"}),
+		NamedSource("vault", MapSource{"workers": "3"}),
+	}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, "env", envs.Host)
+	assert.Equal(t, 2, envs.Port)
+	assert.Equal(t, "file", envs.Name)
+	assert.Equal(t, 3, envs.Workers)
+
+	value, source, found := p.Lookup("port")
+	assert.True(t, found)
+	assert.Equal(t, "2", value)
+	assert.Equal(t, "file", source)
+
+	_, source, _ = p.Lookup("host")
+	assert.Equal(t, "env", source)
+
+	_, _, found = p.Lookup("unknown")
+	assert.False(t, found)
+}
+
+func TestSourceOrderDefaultEnv(t *testing.T) {
+	var envs struct {
+		Port int `sources:"env"`
+	}
+
+	err := parse(envsMap{"port": "8080"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, 8080, envs.Port)
+}
+
+func TestSourceOrderUnknown(t *testing.T) {
+	var envs struct {
+		Port int `sources:"file"`
+	}
+
+	_, err := NewParser(Config{}, &envs)
+	assert.True(t, errors.Is(err, ErrorUnknownSource))
+
+	var malformed struct {
+		Port int `sources:"env,"`
+	}
+
+	_, err = NewParser(Config{}, &malformed)
+	assert.True(t, errors.Is(err, ErrorMalformedTag))
+}
+
+func TestNamedSourceLoader(t *testing.T) {
+	src := NamedSource("secrets", DirSource("testdata/secrets"))
+
+	var envs struct {
+		Password string `env:"password"`
+	}
+
+	p, err := NewParser(Config{Sources: []Source{src}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.NotEmpty(t, envs.Password)
+	assert.Contains(t, src.(Lister).Names(), "password")
+}
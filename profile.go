@@ -0,0 +1,87 @@
+package env
+
+import "strings"
+
+// profileSeparator separates the name of a variable from the profile whose
+// value it overrides, as in DB_HOST__STAGING.
+const profileSeparator = "__"
+
+// profile returns the active profile: Config.Profile, or else the value of
+// the variable named by Config.ProfileEnv in the sources.
+func (p *Parser) profile(sources []Source) string {
+	if p.config.Profile != "" || p.config.ProfileEnv == "" {
+		return p.config.Profile
+	}
+
+	for _, src := range sources {
+		if value, found := src.Lookup(p.config.ProfileEnv); found {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// profileName returns the name of the variable overriding name in profile,
+// with the profile upper-cased for upper-case names and lower-cased
+// otherwise, such as DB_HOST__STAGING or db_host__staging.
+func profileName(name, profile string) string {
+	if strings.ToUpper(name) == name {
+		return name + profileSeparator + strings.ToUpper(profile)
+	}
+
+	return name + profileSeparator + strings.ToLower(profile)
+}
+
+// profileSource looks up the variables of a source overridden for the
+// active profile first.
+type profileSource struct {
+	profile string
+	Source
+}
+
+// Lookup returns the value of the variable overridden for the profile if
+// it is set, or else the value of the variable.
+func (s profileSource) Lookup(name string) (string, bool) {
+	if value, found := s.Source.Lookup(profileName(name, s.profile)); found {
+		return value, true
+	}
+
+	return s.Source.Lookup(name)
+}
+
+// SourceName returns the name of the underlying source if it has one.
+func (s profileSource) SourceName() string {
+	return sourceName(s.Source)
+}
+
+// Names returns the names of the variables of the underlying source if it
+// implements Lister, without the overrides of the profile, which are not
+// variables of their own.
+func (s profileSource) Names() []string {
+	l, ok := s.Source.(Lister)
+	if !ok {
+		return nil
+	}
+
+	var names []string
+
+	for _, name := range l.Names() {
+		if !strings.HasSuffix(name, profileSeparator+strings.ToUpper(s.profile)) &&
+			!strings.HasSuffix(name, profileSeparator+strings.ToLower(s.profile)) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// withProfile returns the sources looking up the overrides of profile first.
+func withProfile(sources []Source, profile string) []Source {
+	profiled := make([]Source, len(sources))
+	for i, src := range sources {
+		profiled[i] = profileSource{profile: profile, Source: src}
+	}
+
+	return profiled
+}
@@ -0,0 +1,66 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile(t *testing.T) {
+	var envs struct {
+		Host  string `env:"DB_HOST"`
+		Port  int
+		Debug bool `env:"DEBUG"`
+	}
+
+	src := MapSource{
+		"DB_HOST":           "localhost",
+		"DB_HOST__STAGING":  "staging.example.com",
+		"DB_HOST__PROD":     "prod.example.com",
+		"port":              "5432",
+		"port__staging":     "6432",
+		"DEBUG__PRODUCTION": "false",
+	}
+
+	p, err := NewParser(Config{Sources: []Source{src}, Profile: "staging"}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, "staging.example.com", envs.Host)
+	assert.Equal(t, 6432, envs.Port)
+
+	p, err = NewParser(Config{Sources: []Source{src}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, "localhost", envs.Host)
+	assert.Equal(t, 5432, envs.Port)
+}
+
+func TestProfileEnv(t *testing.T) {
+	var envs struct {
+		Host string `env:"DB_HOST"`
+	}
+
+	src := MapSource{"APP_ENV": "prod", "DB_HOST": "localhost", "DB_HOST__PROD": "prod.example.com"}
+
+	p, err := NewParser(Config{Sources: []Source{NamedSource("file", src)}, ProfileEnv: "APP_ENV"}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, "prod.example.com", envs.Host)
+	assert.Equal(t, "file", p.Values()[0].Origin)
+}
+
+func TestProfileCatchAll(t *testing.T) {
+	var envs struct {
+		Host  string            `env:"DB_HOST"`
+		Extra map[string]string `env:"catchall"`
+	}
+
+	src := MapSource{"DB_HOST__PROD": "prod.example.com", "extra_level": "debug", "extra_level__prod": "info"}
+
+	p, err := NewParser(Config{Sources: []Source{src}, Profile: "prod"}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, "prod.example.com", envs.Host)
+	assert.Equal(t, map[string]string{"level": "info"}, envs.Extra)
+}
@@ -11,7 +11,7 @@ var textUnmarshalerType = reflect.TypeOf([]encoding.TextUnmarshaler{}).Elem() //
 
 // canParse returns true if the type can be parsed from a string.
 func canParse(t reflect.Type) (parseable, boolean, multiple bool) {
-	parseable = scalar.CanParse(t)
+	parseable = canParseScalar(t)
 	boolean = isBoolean(t)
 
 	if parseable {
@@ -28,7 +28,7 @@ func canParse(t reflect.Type) (parseable, boolean, multiple bool) {
 		t = t.Elem()
 	}
 
-	parseable = scalar.CanParse(t)
+	parseable = canParseScalar(t)
 	boolean = isBoolean(t)
 
 	if parseable {
@@ -40,7 +40,7 @@ func canParse(t reflect.Type) (parseable, boolean, multiple bool) {
 		t = t.Elem()
 	}
 
-	parseable = scalar.CanParse(t)
+	parseable = canParseScalar(t)
 	boolean = isBoolean(t)
 
 	if parseable {
@@ -50,10 +50,16 @@ func canParse(t reflect.Type) (parseable, boolean, multiple bool) {
 	return false, false, false
 }
 
+// canParseScalar returns true if a single value of the type can be parsed
+// from a string.
+func canParseScalar(t reflect.Type) bool {
+	return isUnmarshaler(t) || isRegexp(t) || isLocation(t) || scalar.CanParse(t)
+}
+
 // isBoolean returns true if the type can be parsed from a single string.
 func isBoolean(t reflect.Type) bool {
 	switch {
-	case t.Implements(textUnmarshalerType):
+	case t.Implements(textUnmarshalerType), isUnmarshaler(t):
 		return false
 	case t.Kind() == reflect.Bool:
 		return true
@@ -0,0 +1,30 @@
+package env
+
+import (
+	"reflect"
+	"regexp"
+)
+
+var regexpType = reflect.TypeOf(regexp.Regexp{}) // nolint:gochecknoglobals
+
+// isRegexp returns true if t is regexp.Regexp or a pointer to it, which are
+// compiled from their pattern whatever the version of Go.
+func isRegexp(t reflect.Type) bool {
+	return t == regexpType || t == reflect.PtrTo(regexpType)
+}
+
+// parseRegexp compiles the pattern into dest.
+func parseRegexp(dest reflect.Value, pattern string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+
+	if dest.Kind() == reflect.Ptr {
+		dest.Set(reflect.ValueOf(re))
+	} else {
+		dest.Set(reflect.ValueOf(re).Elem())
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package env
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegexp(t *testing.T) {
+	var envs struct {
+		Filter  *regexp.Regexp
+		Value   regexp.Regexp
+		Routes  []*regexp.Regexp
+		Default *regexp.Regexp `default:"^/api/"`
+	}
+
+	err := parse(envsMap{"filter": "^debug", "value": "a+", "routes": "^/a,^/b"}, &envs)
+	require.NoError(t, err)
+	assert.True(t, envs.Filter.MatchString("debug: x"))
+	assert.Equal(t, "a+", envs.Value.String())
+	require.Len(t, envs.Routes, 2)
+	assert.Equal(t, "^/b", envs.Routes[1].String())
+	assert.True(t, envs.Default.MatchString("/api/v1"))
+}
+
+func TestRegexpInvalid(t *testing.T) {
+	var envs struct {
+		Filter *regexp.Regexp
+	}
+
+	err := parse(envsMap{"filter": "(debug"}, &envs)
+
+	var errs Errors
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs, 1)
+	assert.Equal(t, "filter", errs[0].Name)
+	assert.Contains(t, err.Error(), "missing closing )")
+	assert.Equal(t, "expected a value of type Regexp", errs[0].Hint)
+}
+
+func TestRegexpHelp(t *testing.T) {
+	envs := struct {
+		Filter *regexp.Regexp
+	}{
+		Filter: regexp.MustCompile("^debug"),
+	}
+
+	p, err := NewParser(Config{}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "Environments:\n  filter Regexp [default: ^debug]\n", p.Help())
+}
@@ -0,0 +1,128 @@
+package env
+
+import (
+	"strings"
+	"unicode"
+)
+
+// relaxedSource is a Source looking up variables under the relaxed forms of
+// their names.
+type relaxedSource struct {
+	src Source
+}
+
+// RelaxedSource returns a Source looking up every variable in src under the
+// relaxed forms of its name, in the manner of Spring relaxed binding: the
+// variable MY_APP_PORT is also read from my.app.port, my-app-port,
+// my_app_port, myAppPort and MyAppPort. The name as written is tried first.
+// Sources implementing Loader are still loaded.
+func RelaxedSource(src Source) Source {
+	return relaxedSource{src: src}
+}
+
+// Lookup returns the value of the first relaxed form of name set in the
+// underlying source.
+func (s relaxedSource) Lookup(name string) (string, bool) {
+	for _, form := range relaxedForms(name) {
+		if value, found := s.src.Lookup(form); found {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// Load loads the underlying source if it implements Loader.
+func (s relaxedSource) Load() error {
+	if l, ok := s.src.(Loader); ok {
+		return l.Load()
+	}
+
+	return nil
+}
+
+// relaxedForms returns name followed by its other relaxed forms.
+func relaxedForms(name string) []string {
+	words := splitWords(name)
+	if len(words) == 0 {
+		return []string{name}
+	}
+
+	lower := make([]string, len(words))
+	title := make([]string, len(words))
+
+	for i, w := range words {
+		lower[i] = strings.ToLower(w)
+		title[i] = strings.ToUpper(lower[i][:1]) + lower[i][1:]
+	}
+
+	camel := lower[0] + strings.Join(title[1:], "")
+
+	forms := []string{name}
+
+	for _, form := range []string{
+		strings.ToUpper(strings.Join(lower, "_")),
+		strings.Join(lower, "."),
+		strings.Join(lower, "-"),
+		strings.Join(lower, "_"),
+		camel,
+		strings.Join(title, ""),
+	} {
+		if !containsString(forms, form) {
+			forms = append(forms, form)
+		}
+	}
+
+	return forms
+}
+
+// splitWords splits a name on dots, dashes, underscores and camel case
+// boundaries, keeping acronyms such as HTTP in one word.
+func splitWords(name string) []string {
+	var (
+		words []string
+		word  []rune
+	)
+
+	runes := []rune(name)
+
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = nil
+		}
+	}
+
+	for i, r := range runes {
+		switch {
+		case r == '.' || r == '-' || r == '_':
+			flush()
+
+			continue
+		case unicode.IsUpper(r) && len(word) > 0:
+			prev := word[len(word)-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+
+			if !unicode.IsUpper(prev) || nextLower {
+				flush()
+			}
+		}
+
+		word = append(word, r)
+	}
+
+	flush()
+
+	return words
+}
+
+// containsString reports whether values contains s.
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
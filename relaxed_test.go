@@ -0,0 +1,45 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelaxedSource(t *testing.T) {
+	var envs struct {
+		Port    int    `env:"MY_APP_PORT"`
+		Host    string `env:"my.app.host"`
+		Timeout string `env:"myAppTimeout"`
+		Name    string `env:"APP_NAME"`
+	}
+
+	p, err := NewParser(Config{Sources: []Source{RelaxedSource(MapSource{
+		"myAppPort":      "8080",
+		"MY_APP_HOST":    "localhost",
+		"my-app-timeout": "5s",
+		"APP_NAME":       "foo",
+		"app.name":       "bar",
+	})}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, 8080, envs.Port)
+	assert.Equal(t, "localhost", envs.Host)
+	assert.Equal(t, "5s", envs.Timeout)
+	assert.Equal(t, "foo", envs.Name)
+}
+
+func TestRelaxedForms(t *testing.T) {
+	assert.Equal(t, []string{
+		"HTTPServerPort",
+		"HTTP_SERVER_PORT",
+		"http.server.port",
+		"http-server-port",
+		"http_server_port",
+		"httpServerPort",
+		"HttpServerPort",
+	}, relaxedForms("HTTPServerPort"))
+	assert.Equal(t, []string{"port", "PORT", "Port"}, relaxedForms("port"))
+	assert.Equal(t, []string{"_"}, relaxedForms("_"))
+}
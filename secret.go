@@ -0,0 +1,98 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// redacted replaces sensitive values in help, dumps and error messages.
+const redacted = "***"
+
+var secretType = reflect.TypeOf(Secret("")) // nolint:gochecknoglobals
+
+// Secret is a string holding sensitive data such as a password or a token.
+// It is parsed like a string, but always formats as "***" so that it does
+// not leak into logs. Fields of this type are treated like fields tagged
+// with the secret option. Convert it to a string to use the actual value.
+type Secret string
+
+// String returns the redacted value.
+func (s Secret) String() string {
+	return redacted
+}
+
+// Format writes the redacted value whatever the verb.
+func (s Secret) Format(f fmt.State, verb rune) {
+	_, _ = io.WriteString(f, redacted)
+}
+
+// MarshalJSON encodes the redacted value.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redacted)
+}
+
+// isSecretType returns true if t is Secret, or a pointer or slice of it.
+func isSecretType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		if t == secretType {
+			break
+		}
+
+		t = t.Elem()
+	}
+
+	return t == secretType
+}
+
+// redacts returns true if the name or an alias of the option matches one
+// of the Redact patterns.
+func (c Config) redacts(s *spec) bool {
+	for _, re := range c.Redact {
+		if re.MatchString(s.name) {
+			return true
+		}
+
+		for _, alias := range s.aliases {
+			if re.MatchString(alias) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// redact returns value, or its redacted form for secret and masked options.
+func (s *spec) redact(value string) string {
+	if (s.secret || s.mask) && value != "" {
+		return redacted
+	}
+
+	return value
+}
+
+// redactError hides the value from the error message of secret options.
+func (s *spec) redactError(err error, value string) error {
+	if !s.secret || value == "" {
+		return err
+	}
+
+	return &redactedError{err: err, value: value}
+}
+
+// redactedError wraps an error whose message may contain a secret value.
+type redactedError struct {
+	err   error
+	value string
+}
+
+func (e *redactedError) Error() string {
+	return strings.ReplaceAll(e.err.Error(), e.value, redacted)
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,138 @@
+package env
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretFormatting(t *testing.T) {
+	s := Secret("hunter2")
+
+	assert.Equal(t, "***", s.String())
+	assert.Equal(t, "*** ***", fmt.Sprintf("%v %#v", s, s))
+	assert.Equal(t, "{***}", fmt.Sprintf("%v", struct{ S Secret }{s}))
+
+	b, err := json.Marshal(map[string]Secret{"password": s})
+	require.NoError(t, err)
+	assert.Equal(t, `{"password":"***"}`, string(b))
+	assert.Equal(t, "hunter2", string(s))
+}
+
+func TestSecretParse(t *testing.T) {
+	var envs struct {
+		Password Secret
+		Token    string `env:"token,secret"`
+		Keys     []Secret
+	}
+
+	p, err := pparse(envsMap{"password": "hunter2", "token": "abc", "keys": "a,b"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, Secret("hunter2"), envs.Password)
+	assert.Equal(t, "abc", envs.Token)
+	assert.Equal(t, []Secret{"a", "b"}, envs.Keys)
+
+	assert.Equal(t, map[string]string{
+		"password": "***",
+		"token":    "***",
+		"keys":     "***",
+	}, p.Snapshot())
+}
+
+func TestSecretHelp(t *testing.T) {
+	var envs struct {
+		Password Secret `default:"hunter2"`
+		Token    string `env:"token,secret" default:"abc"`
+		Empty    string `env:"empty,secret"`
+	}
+
+	p, err := NewParser(Config{}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, `Environments:
+  password string [default: ***]
+  token string [default: ***]
+  empty string
+`, p.Help())
+}
+
+func TestSecretErrors(t *testing.T) {
+	var envs struct {
+		Pin int `env:"pin,secret"`
+	}
+
+	err := parse(envsMap{"pin": "12x4"}, &envs)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "12x4")
+	assert.Contains(t, err.Error(), "***")
+	assert.True(t, errors.Is(err, strconv.ErrSyntax))
+
+	var defaults struct {
+		Pin int `env:"pin,secret" default:"12x4"`
+	}
+
+	err = parse(envsMap{}, &defaults)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "12x4")
+}
+
+func TestMask(t *testing.T) {
+	var envs struct {
+		Token string `env:",mask" default:"abc"`
+		Port  int    `env:"port,mask"`
+	}
+
+	p, err := pparse(envsMap{"port": "x"}, &envs)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"x"`, "masked values are not hidden from error messages")
+
+	p, err = pparse(envsMap{"port": "8080"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "abc", envs.Token)
+	assert.Equal(t, map[string]string{"token": "***", "port": "***"}, p.Snapshot())
+	assert.Equal(t, `Environments:
+  token string [default: ***]
+  port int
+`, p.Help())
+}
+
+func TestRedactPatterns(t *testing.T) {
+	var envs struct {
+		APIToken string `env:"API_TOKEN" default:"abc"`
+		Pin      int    `env:"PIN,alias:DB_PASSWORD"`
+		Workers  int    `env:"WORKERS"`
+	}
+
+	p, err := NewParser(Config{
+		Redact: []*regexp.Regexp{regexp.MustCompile(`(?i)(token|password|key)`)},
+	}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, `Environments:
+  API_TOKEN string [default: ***]
+  PIN int [aliases: DB_PASSWORD]
+  WORKERS int
+`, p.Help())
+
+	os.Clearenv()
+	_ = os.Setenv("DB_PASSWORD", "12x4")
+	_ = os.Setenv("WORKERS", "4")
+
+	err = p.Parse()
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "12x4")
+
+	_ = os.Setenv("DB_PASSWORD", "1234")
+
+	require.NoError(t, p.Parse())
+	assert.Equal(t, map[string]string{
+		"API_TOKEN": "***",
+		"PIN":       "***",
+		"WORKERS":   "4",
+	}, p.Snapshot())
+}
@@ -0,0 +1,71 @@
+package env
+
+import (
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hostList is a flag-style list, set from hosts separated by semicolons.
+type hostList []string
+
+func (l *hostList) Set(value string) error {
+	*l = strings.Split(value, ";")
+
+	return nil
+}
+
+func (l *hostList) String() string {
+	return strings.Join(*l, ";")
+}
+
+// textLevel implements both Setter and encoding.TextUnmarshaler.
+type textLevel string
+
+func (l *textLevel) Set(value string) error {
+	*l = textLevel("set:" + value)
+
+	return nil
+}
+
+func (l *textLevel) UnmarshalText(text []byte) error {
+	*l = textLevel("text:" + string(text))
+
+	return nil
+}
+
+var _ flag.Value = (*hostList)(nil)
+
+func TestSetter(t *testing.T) {
+	var envs struct {
+		Hosts  hostList
+		Backup *hostList
+		Level  textLevel
+		Levels []textLevel
+	}
+
+	err := parse(envsMap{
+		"hosts":  "a;b",
+		"backup": "c",
+		"level":  "debug",
+		"levels": "info,warn",
+	}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, hostList{"a", "b"}, envs.Hosts)
+	assert.Equal(t, &hostList{"c"}, envs.Backup)
+	assert.Equal(t, textLevel("set:debug"), envs.Level)
+	assert.Equal(t, []textLevel{"set:info", "set:warn"}, envs.Levels)
+}
+
+func TestSetterDefault(t *testing.T) {
+	envs := struct {
+		Hosts hostList
+	}{Hosts: hostList{"a", "b"}}
+
+	p, err := NewParser(Config{Sources: []Source{MapSource{}}}, &envs)
+	require.NoError(t, err)
+	assert.Contains(t, p.Help(), "[default: a;b]")
+}
@@ -0,0 +1,22 @@
+//go:build go1.21
+// +build go1.21
+
+package env
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogValues logs the effective configuration with logger at the info
+// level, see LogValuesTo. It is only available with Go 1.21 and later.
+func (p *Parser) LogValues(logger *slog.Logger) {
+	for _, v := range p.Values() {
+		logger.LogAttrs(context.Background(), slog.LevelInfo, logValuesMsg,
+			slog.String("name", v.Name),
+			slog.String("field", v.Field),
+			slog.String("source", v.Origin),
+			slog.String("value", v.Value),
+		)
+	}
+}
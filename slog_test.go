@@ -0,0 +1,38 @@
+//go:build go1.21
+// +build go1.21
+
+package env
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogValues(t *testing.T) {
+	var envs struct {
+		Host  string
+		Token string `env:"token,secret"`
+	}
+
+	p, err := pparse(envsMap{"host": "example.com", "token": "hunter2"}, &envs)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	p.LogValues(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+
+			return a
+		},
+	})))
+
+	assert.Equal(t, "level=INFO msg=config name=host field=Host source=env value=example.com\n"+
+		"level=INFO msg=config name=token field=Token source=env value=***\n", buf.String())
+}
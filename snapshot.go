@@ -0,0 +1,137 @@
+package env
+
+import (
+	"encoding"
+	"encoding/csv"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Snapshot returns the current value of every variable, keyed by variable
+// name and formatted the way it would be written in the environment. The
+// values of secret variables are redacted.
+func (p *Parser) Snapshot() map[string]string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	snapshot := make(map[string]string, len(p.specs))
+
+	for _, spec := range p.specs {
+		snapshot[spec.name] = spec.redact(spec.format(p.val(spec.dest)))
+	}
+
+	return snapshot
+}
+
+// Diff returns the sorted names of the variables whose values differ
+// between two snapshots, including variables present in only one of them.
+func Diff(a, b map[string]string) []string {
+	var changed []string
+
+	for name, av := range a {
+		if bv, ok := b[name]; !ok || av != bv {
+			changed = append(changed, name)
+		}
+	}
+
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+
+	sort.Strings(changed)
+
+	return changed
+}
+
+// formatValue formats a field value as it would be written in the
+// environment: slices become CSV strings and nil pointers become empty.
+func formatValue(v reflect.Value) string {
+	if !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		return ""
+	}
+
+	if text, ok := marshalText(v); ok {
+		return text
+	}
+
+	if text, ok := stringSetter(v); ok {
+		return text
+	}
+
+	if isLocation(v.Type()) {
+		return formatLocation(v)
+	}
+
+	if isFileMode(v.Type()) {
+		return formatFileMode(v)
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		return formatValue(v.Elem())
+	case reflect.Slice:
+		values := make([]string, v.Len())
+		for i := range values {
+			values[i] = formatValue(v.Index(i))
+		}
+
+		return formatCSV(values)
+	}
+
+	if d, ok := v.Interface().(time.Duration); ok {
+		return formatDuration(d)
+	}
+
+	return fmt.Sprintf("%v", v.Interface())
+}
+
+// marshalText formats v using encoding.TextMarshaler if v or its address
+// implements it.
+func marshalText(v reflect.Value) (string, bool) {
+	m, ok := v.Interface().(encoding.TextMarshaler)
+	if !ok && v.CanAddr() {
+		m, ok = v.Addr().Interface().(encoding.TextMarshaler)
+	}
+
+	if !ok {
+		return "", false
+	}
+
+	text, err := m.MarshalText()
+	if err != nil {
+		return "", false
+	}
+
+	return string(text), true
+}
+
+// stringSetter formats v with its String method if v or its address
+// implements both Setter and fmt.Stringer, as flag.Value does.
+func stringSetter(v reflect.Value) (string, bool) {
+	s, ok := v.Interface().(fmt.Stringer)
+	if !ok && v.CanAddr() {
+		s, ok = v.Addr().Interface().(fmt.Stringer)
+	}
+
+	if !ok || !v.Type().Implements(setterType) && !reflect.PtrTo(v.Type()).Implements(setterType) {
+		return "", false
+	}
+
+	return s.String(), true
+}
+
+// formatCSV joins values into a single CSV record without a trailing newline.
+func formatCSV(values []string) string {
+	var b strings.Builder
+
+	w := csv.NewWriter(&b)
+	_ = w.Write(values)
+	w.Flush()
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
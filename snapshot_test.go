@@ -0,0 +1,65 @@
+package env
+
+import (
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	var envs struct {
+		Workers int
+		Timeout time.Duration
+		Hosts   []string
+		Host    net.IP
+		Ptr     *int
+		Name    string `default:"foo"`
+	}
+
+	p, err := pparse(envsMap{
+		"workers": "4",
+		"timeout": "1m",
+		"hosts":   "a,b c",
+		"host":    "127.0.0.1",
+	}, &envs)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"workers": "4",
+		"timeout": "1m",
+		"hosts":   "a,b c",
+		"host":    "127.0.0.1",
+		"ptr":     "",
+		"name":    "foo",
+	}, p.Snapshot())
+}
+
+func TestDiff(t *testing.T) {
+	var envs struct {
+		Workers int
+		Name    string
+		Hosts   []string
+	}
+
+	p, err := pparse(envsMap{"workers": "4", "name": "foo"}, &envs)
+	require.NoError(t, err)
+
+	before := p.Snapshot()
+
+	_ = os.Setenv("workers", "8")
+	_ = os.Setenv("hosts", "a")
+
+	require.NoError(t, p.Reload())
+
+	after := p.Snapshot()
+	assert.Equal(t, []string{"hosts", "workers"}, Diff(before, after))
+	assert.Empty(t, Diff(after, after))
+	assert.Equal(t, []string{"extra", "workers"}, Diff(
+		map[string]string{"workers": "4", "name": "foo"},
+		map[string]string{"workers": "8", "name": "foo", "extra": "x"},
+	))
+}
@@ -0,0 +1,223 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Source provides the values of variables. The process environment is the
+// default source, see Config.Sources.
+type Source interface {
+	// Lookup returns the value of the variable with the given name, and
+	// whether it is set.
+	Lookup(name string) (string, bool)
+}
+
+// Loader is implemented by the sources that need to be (re)loaded before
+// every Parse or Reload, such as files or remote stores.
+type Loader interface {
+	// Load refreshes the values of the source.
+	Load() error
+}
+
+// Prefetcher is implemented by the sources that resolve every variable at
+// once before any is looked up, such as CachedSource. Prefetch is called
+// before every Parse or Reload, after Load, with the names and aliases of
+// all the variables.
+type Prefetcher interface {
+	// Prefetch resolves the variables with the given names.
+	Prefetch(names []string) error
+}
+
+// Lister is implemented by the sources that can list the names of their
+// variables, which is needed to fill catch-all maps.
+type Lister interface {
+	// Names returns the names of the variables that are set.
+	Names() []string
+}
+
+// SourceFunc adapts a lookup function, such as os.LookupEnv, to a Source.
+type SourceFunc func(name string) (string, bool)
+
+// Lookup calls f(name).
+func (f SourceFunc) Lookup(name string) (string, bool) {
+	return f(name)
+}
+
+// EnvSource returns the Source reading the process environment.
+func EnvSource() Source {
+	return envSource{}
+}
+
+// envSource is a Source reading the process environment.
+type envSource struct{}
+
+// SourceName returns "env".
+func (envSource) SourceName() string {
+	return "env"
+}
+
+// Lookup returns the value of the environment variable.
+func (envSource) Lookup(name string) (string, bool) {
+	return os.LookupEnv(name)
+}
+
+// Names returns the names of the environment variables.
+func (envSource) Names() []string {
+	environ := os.Environ()
+	names := make([]string, 0, len(environ))
+
+	for _, kv := range environ {
+		if i := strings.IndexByte(kv, '='); i > 0 {
+			names = append(names, kv[:i])
+		}
+	}
+
+	return names
+}
+
+// withLookup returns the sources where Config.Lookup replaces the process
+// environment.
+func (c *Config) withLookup(sources []Source) []Source {
+	replaced := make([]Source, len(sources))
+
+	for i, src := range sources {
+		if _, ok := src.(envSource); ok {
+			src = NamedSource("env", SourceFunc(c.Lookup))
+		}
+
+		replaced[i] = src
+	}
+
+	return replaced
+}
+
+// MapSource is a Source reading variables from a map.
+type MapSource map[string]string
+
+// Lookup returns the value of the variable from the map.
+func (m MapSource) Lookup(name string) (string, bool) {
+	value, found := m[name]
+
+	return value, found
+}
+
+// Names returns the keys of the map.
+func (m MapSource) Names() []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// sources returns the sources of the parser in order of precedence, which
+// look up the overrides of the active profile first.
+func (p *Parser) sources() []Source {
+	sources := p.configuredSources()
+
+	if profile := p.profile(sources); profile != "" {
+		sources = withProfile(sources, profile)
+	}
+
+	return sources
+}
+
+// configuredSources returns the sources of the configuration, the flags and
+// the .env files, or those of ParseMap, without the overrides of the
+// profile.
+func (p *Parser) configuredSources() []Source {
+	if p.only != nil {
+		return p.only
+	}
+
+	sources := p.config.Sources
+	if sources == nil {
+		sources = []Source{EnvSource()}
+	}
+
+	if p.config.Lookup != nil {
+		sources = p.config.withLookup(sources)
+	}
+
+	if p.files != nil {
+		sources = append(sources[:len(sources):len(sources)], p.files...)
+	}
+
+	if p.flags != nil {
+		sources = append([]Source{p.flags}, sources...)
+	}
+
+	return sources
+}
+
+// loadSources loads the sources implementing Loader, then prefetches the
+// variables of the sources implementing Prefetcher.
+func (p *Parser) loadSources() error {
+	var names []string
+
+	for _, src := range p.configuredSources() {
+		if l, ok := src.(Loader); ok {
+			if err := l.Load(); err != nil {
+				return fmt.Errorf("error loading source: %w", err)
+			}
+		}
+
+		if f, ok := src.(Prefetcher); ok {
+			if names == nil {
+				names = p.names()
+			}
+
+			if err := f.Prefetch(names); err != nil {
+				return fmt.Errorf("error loading source: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// names returns the names, aliases and defaultEnv fallbacks of all the
+// variables, and their overrides in Config.Profile, as well as the variable
+// holding the profile.
+func (p *Parser) names() []string {
+	names := make([]string, 0, len(p.specs))
+
+	for _, spec := range p.specs {
+		names = append(names, spec.name)
+		names = append(names, spec.aliases...)
+		names = append(names, spec.defaultEnv...)
+	}
+
+	if p.config.Profile != "" {
+		for _, name := range names {
+			names = append(names, profileName(name, p.config.Profile))
+		}
+	}
+
+	if p.config.ProfileEnv != "" {
+		names = append(names, p.config.ProfileEnv)
+	}
+
+	return names
+}
+
+// lookup returns the first of the option's names that is set in the first
+// source having any of them, in the order of its sources tag, and its value.
+func (s *spec) lookup(sources []Source) (string, string, bool) {
+	for _, src := range s.ordered(sources) {
+		if value, found := src.Lookup(s.name); found {
+			return s.name, value, true
+		}
+
+		for _, alias := range s.aliases {
+			if value, found := src.Lookup(alias); found {
+				return alias, value, true
+			}
+		}
+	}
+
+	return "", "", false
+}
@@ -0,0 +1,99 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourcesPrecedence(t *testing.T) {
+	var envs struct {
+		Host    string
+		Port    int
+		Timeout string `default:"1s"`
+		Debug   bool
+	}
+
+	os.Clearenv()
+	_ = os.Setenv("host", "env-host")
+
+	p, err := NewParser(Config{Sources: []Source{
+		EnvSource(),
+		MapSource{"host": "file-host", "port": "80"},
+		MapSource{"port": "8080", "debug": "true"},
+	}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+
+	assert.Equal(t, "env-host", envs.Host)
+	assert.Equal(t, 80, envs.Port)
+	assert.Equal(t, "1s", envs.Timeout)
+	assert.True(t, envs.Debug)
+}
+
+func TestSourcesAliases(t *testing.T) {
+	var envs struct {
+		URL string `env:"name:DATABASE_URL, alias:DB_URL"`
+	}
+
+	// the first source having any of the names wins
+	p, err := NewParser(Config{Sources: []Source{
+		MapSource{"DB_URL": "legacy"},
+		MapSource{"DATABASE_URL": "default"},
+	}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, "legacy", envs.URL)
+}
+
+func TestSourceFunc(t *testing.T) {
+	var envs struct {
+		Foo string
+	}
+
+	p, err := NewParser(Config{Sources: []Source{
+		SourceFunc(func(name string) (string, bool) {
+			return "value of " + name, true
+		}),
+	}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, "value of foo", envs.Foo)
+}
+
+type countingSource struct {
+	MapSource
+	loads int
+	err   error
+}
+
+func (s *countingSource) Load() error {
+	s.loads++
+
+	return s.err
+}
+
+var errTestLoad = errors.New("cannot load")
+
+func TestSourceLoader(t *testing.T) {
+	var envs struct {
+		Foo string
+	}
+
+	src := &countingSource{MapSource: MapSource{"foo": "bar"}}
+
+	p, err := NewParser(Config{Sources: []Source{src}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	require.NoError(t, p.Reload())
+	assert.Equal(t, 2, src.loads)
+	assert.Equal(t, "bar", envs.Foo)
+
+	src.err = errTestLoad
+	err = p.Parse()
+	assert.True(t, errors.Is(err, errTestLoad))
+	assert.EqualError(t, err, "error loading source: cannot load")
+}
@@ -0,0 +1,61 @@
+package env
+
+// VarSpec is a read-only description of a variable, for tools built on top
+// of the parser such as documentation generators and configuration
+// linters.
+type VarSpec struct {
+	// Name is the name of the variable.
+	Name string
+	// Aliases are the other names of the variable, in priority order.
+	Aliases []string
+	// Path is the dotted path of the field, see FieldInfo.Path.
+	Path string
+	// Type is the language-neutral type of the values, see WriteSpecs.
+	Type string
+	// MapValues is the type of the values of maps, or of the elements of
+	// their lists, when Type is "map".
+	MapValues string
+	// GoType is the Go type of the field, such as "time.Duration".
+	GoType string
+	// Help is the help text of the variable.
+	Help string
+	// Docs is the link to the documentation of the variable.
+	Docs string
+	// Default is the default value as displayed in the help, redacted
+	// for secret variables, or "" if there is none.
+	Default string
+	// Required is true if the variable must be set.
+	Required bool
+	// Multiple is true if the variable holds a comma-separated list.
+	Multiple bool
+	// Secret is true if the value is hidden in the help, dumps and errors.
+	Secret bool
+	// Values are the allowed values of Enumerated types.
+	Values []string
+}
+
+// Specs returns the description of every variable, in the order of the
+// fields.
+func (p *Parser) Specs() []VarSpec {
+	specs := make([]VarSpec, len(p.specs))
+
+	for i, spec := range p.specs {
+		specs[i] = VarSpec{
+			Name:      spec.name,
+			Aliases:   append([]string(nil), spec.aliases...),
+			Path:      qualifiedPath(p.roots[spec.dest.root].Type().Elem(), spec.dest),
+			Type:      spec.typeName(),
+			MapValues: spec.mapValueType(),
+			GoType:    spec.typ.String(),
+			Help:      spec.help,
+			Docs:      spec.docs,
+			Default:   spec.displayDefault(),
+			Required:  spec.required,
+			Multiple:  spec.multiple,
+			Secret:    spec.secret || spec.mask,
+			Values:    append([]string(nil), spec.values...),
+		}
+	}
+
+	return specs
+}
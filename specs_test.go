@@ -0,0 +1,53 @@
+package env
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpecs(t *testing.T) {
+	type config struct {
+		Workers int           `env:"WORKERS,required,alias:THREADS" help:"number of workers"`
+		Timeout time.Duration `default:"5s" docs:"https://example.com/timeout"`
+		Token   string        `env:"secret" default:"abc"`
+		Hosts   []string
+		Stage   stage
+	}
+
+	var envs config
+
+	p, err := NewParser(Config{}, &envs)
+	require.NoError(t, err)
+
+	specs := p.Specs()
+	require.Len(t, specs, 5)
+	assert.Equal(t, VarSpec{
+		Name:     "WORKERS",
+		Aliases:  []string{"THREADS"},
+		Path:     "config.Workers",
+		Type:     "int",
+		GoType:   "int",
+		Help:     "number of workers",
+		Required: true,
+	}, specs[0])
+	assert.Equal(t, VarSpec{
+		Name:    "timeout",
+		Path:    "config.Timeout",
+		Type:    "duration",
+		GoType:  "time.Duration",
+		Docs:    "https://example.com/timeout",
+		Default: "5s",
+	}, specs[1])
+	assert.Equal(t, "***", specs[2].Default)
+	assert.True(t, specs[2].Secret)
+	assert.True(t, specs[3].Multiple)
+	assert.Equal(t, "string", specs[3].Type)
+	assert.Equal(t, []string{"dev", "staging", "prod"}, specs[4].Values)
+
+	// the specs cannot be modified through the returned slices
+	specs[0].Aliases[0] = "X"
+	assert.Equal(t, []string{"THREADS"}, p.Specs()[0].Aliases)
+}
@@ -0,0 +1,116 @@
+package env
+
+import (
+	"sort"
+	"strings"
+)
+
+// suggester finds the variables that were set under a name close to the
+// name of a missing variable, which are likely typos.
+type suggester struct {
+	p      *Parser
+	names  []string // names of the variables not bound to any field
+	listed bool
+}
+
+// suggest returns the unbound variable whose name is the closest to name,
+// or "" if none is close enough. Names differing only by case are the
+// closest.
+func (s *suggester) suggest(name string) string {
+	if !s.listed {
+		s.names = s.p.unboundNames()
+		s.listed = true
+	}
+
+	best, bestDist := "", maxSuggestDistance(name)+1
+
+	for _, candidate := range s.names {
+		if d := levenshtein(strings.ToLower(name), strings.ToLower(candidate)); d < bestDist {
+			best, bestDist = candidate, d
+		}
+	}
+
+	return best
+}
+
+// maxSuggestDistance returns the largest edit distance at which a name is
+// suggested for name: one edit for short names, and up to a quarter of the
+// length for longer ones.
+func maxSuggestDistance(name string) int {
+	if d := len(name) / 4; d > 1 {
+		return d
+	}
+
+	return 1
+}
+
+// unboundNames returns the sorted names of the variables of the sources
+// implementing Lister that are not bound to any field.
+func (p *Parser) unboundNames() []string {
+	bound := make(map[string]bool)
+	for _, name := range p.names() {
+		bound[name] = true
+	}
+
+	seen := make(map[string]bool)
+
+	var names []string
+
+	for _, src := range p.sources() {
+		l, ok := src.(Lister)
+		if !ok {
+			continue
+		}
+
+		for _, name := range l.Names() {
+			if !bound[name] && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// levenshtein returns the number of single byte insertions, deletions and
+// substitutions needed to turn a into b.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
+
+// minInt returns the smallest of values.
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,46 @@
+package env
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequiredSuggestion(t *testing.T) {
+	var envs struct {
+		Workers int    `env:"MYAPP_WORKERS,required"`
+		Host    string `env:"MYAPP_HOST,required"`
+		Port    int    `env:"MYAPP_PORT"`
+	}
+
+	err := parse(envsMap{"MYAPP_WROKERS": "4", "myapp_host": "localhost", "MYAPP_PORT": "80"}, &envs)
+	assert.True(t, errors.Is(err, ErrorFieldIsRequired))
+	assert.EqualError(t, err, "MYAPP_WORKERS: field is required, did you mean MYAPP_WROKERS?\n"+
+		"MYAPP_HOST: field is required, did you mean myapp_host?")
+}
+
+func TestRequiredNoSuggestion(t *testing.T) {
+	var envs struct {
+		Workers int `env:"workers,required"`
+	}
+
+	err := parse(envsMap{"threads": "4"}, &envs)
+	assert.EqualError(t, err, "workers: field is required")
+}
+
+func TestLevenshtein(t *testing.T) {
+	for _, test := range []struct {
+		a, b string
+		d    int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"workers", "workers", 0},
+		{"workers", "wrokers", 2},
+		{"workers", "worker", 1},
+		{"kitten", "sitting", 3},
+	} {
+		assert.Equal(t, test.d, levenshtein(test.a, test.b), "%s %s", test.a, test.b)
+	}
+}
@@ -0,0 +1,241 @@
+package env
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+)
+
+// systemdSource is a Source reading a file in the EnvironmentFile syntax of
+// systemd.
+type systemdSource struct {
+	path     string
+	optional bool
+
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// SystemdEnvFileSource returns a Source reading a file in the syntax of the
+// EnvironmentFile directive of systemd units, so that a service and the
+// local tools sharing its configuration read the same file. Each line
+// assigns a variable, as in KEY=value, later lines overriding earlier ones.
+// Values may be quoted: single quotes keep their contents as is, while
+// backslashes escape quotes, backslashes, backquotes and dollar signs
+// within double quotes and any character outside of quotes. A backslash at
+// the end of a line continues the value on the next line. Lines starting
+// with # or ; are comments. Unlike shell scripts, there is no export
+// keyword and no variable expansion. Like in units, a path starting with a
+// dash names a file that may not exist. The file is read again before
+// every Parse.
+func SystemdEnvFileSource(path string) Source {
+	if strings.HasPrefix(path, "-") {
+		return &systemdSource{path: path[1:], optional: true}
+	}
+
+	return &systemdSource{path: path}
+}
+
+// Load reads the file.
+func (s *systemdSource) Load() error {
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil && !(s.optional && os.IsNotExist(err)) {
+		return err
+	}
+
+	values := parseEnvironmentFile(string(data))
+
+	s.mu.Lock()
+	s.values = values
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Lookup returns the value assigned to the variable by the file.
+func (s *systemdSource) Lookup(name string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	value, found := s.values[name]
+
+	return value, found
+}
+
+// Names returns the names of the variables assigned by the file.
+func (s *systemdSource) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.values))
+	for name := range s.values {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// The states of the parser of environment files.
+const (
+	envFilePreKey = iota
+	envFileKey
+	envFilePreValue
+	envFileValue
+	envFileValueEscape
+	envFileSingleQuote
+	envFileDoubleQuote
+	envFileDoubleQuoteEscape
+	envFileComment
+	envFileCommentEscape
+)
+
+// parseEnvironmentFile returns the variables assigned by the contents of an
+// environment file, following the parser of systemd. Assignments to
+// invalid names are ignored.
+func parseEnvironmentFile(data string) map[string]string {
+	values := make(map[string]string)
+
+	var key, value strings.Builder
+
+	state := envFilePreKey
+	trailing := 0 // spaces at the end of an unquoted value, trimmed at the end of the line
+
+	assign := func() {
+		v := value.String()
+		if name := strings.TrimRight(key.String(), " \t"); isEnvName(name) {
+			values[name] = v[:len(v)-trailing]
+		}
+
+		key.Reset()
+		value.Reset()
+
+		trailing = 0
+	}
+
+	for _, c := range data {
+		switch state {
+		case envFilePreKey:
+			switch {
+			case c == '#' || c == ';':
+				state = envFileComment
+			case !isEnvSpace(c) && c != '\n' && c != '\r':
+				state = envFileKey
+
+				key.WriteRune(c)
+			}
+		case envFileKey:
+			switch c {
+			case '\n', '\r':
+				// a line without an equal sign assigns nothing
+				key.Reset()
+
+				state = envFilePreKey
+			case '=':
+				state = envFilePreValue
+			default:
+				key.WriteRune(c)
+			}
+		case envFilePreValue, envFileValue:
+			switch {
+			case c == '\n' || c == '\r':
+				assign()
+
+				state = envFilePreKey
+			case c == '\\':
+				state = envFileValueEscape
+				trailing = 0
+			case c == '\'' && state == envFilePreValue:
+				state = envFileSingleQuote
+			case c == '"' && state == envFilePreValue:
+				state = envFileDoubleQuote
+			case isEnvSpace(c) && state == envFilePreValue:
+			default:
+				state = envFileValue
+
+				value.WriteRune(c)
+
+				if isEnvSpace(c) {
+					trailing++
+				} else {
+					trailing = 0
+				}
+			}
+		case envFileValueEscape:
+			state = envFileValue
+
+			if c != '\n' {
+				value.WriteRune(c)
+			}
+		case envFileSingleQuote:
+			if c == '\'' {
+				state = envFilePreValue
+			} else {
+				value.WriteRune(c)
+			}
+		case envFileDoubleQuote:
+			switch c {
+			case '"':
+				state = envFilePreValue
+			case '\\':
+				state = envFileDoubleQuoteEscape
+			default:
+				value.WriteRune(c)
+			}
+		case envFileDoubleQuoteEscape:
+			state = envFileDoubleQuote
+
+			switch c {
+			case '"', '\\', '`', '$':
+				value.WriteRune(c)
+			case '\n':
+			default:
+				value.WriteRune('\\')
+				value.WriteRune(c)
+			}
+		case envFileComment:
+			switch c {
+			case '\\':
+				state = envFileCommentEscape
+			case '\n', '\r':
+				state = envFilePreKey
+			}
+		case envFileCommentEscape:
+			state = envFileComment
+		}
+	}
+
+	// the last line may not end with a newline, nor close its quotes
+	switch state {
+	case envFilePreValue, envFileValue, envFileValueEscape, envFileSingleQuote, envFileDoubleQuote,
+		envFileDoubleQuoteEscape:
+		assign()
+	}
+
+	return values
+}
+
+// isEnvSpace returns true for the blanks separating the parts of a line of
+// an environment file.
+func isEnvSpace(c rune) bool {
+	return c == ' ' || c == '\t'
+}
+
+// isEnvName returns true if name is a valid variable name: letters, digits
+// and underscores, not starting with a digit.
+func isEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, c := range name {
+		switch {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
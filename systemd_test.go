@@ -0,0 +1,78 @@
+package env
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEnvironmentFile(t *testing.T) {
+	values := parseEnvironmentFile(`# comment
+; another comment \
+  continued
+HOST=example.com
+
+  PORT = 8080  
+EMPTY=
+SINGLE='a "b" \n c'
+DOUBLE="a \"b\" \$HOME \n"
+CONCAT="a b"'c d'e
+ESCAPED=a\ b\\c\
+d
+MULTI="line 1
+line 2"
+export EXPORTED=1
+1INVALID=x
+NOEQUAL
+HOST=override
+CRLF=value` + "\r\nLAST=\"unterminated")
+
+	assert.Equal(t, map[string]string{
+		"HOST":    "override",
+		"PORT":    "8080",
+		"EMPTY":   "",
+		"SINGLE":  `a "b" \n c`,
+		"DOUBLE":  `a "b" $HOME \n`,
+		"CONCAT":  "a bc de",
+		"ESCAPED": `a b\cd`,
+		"MULTI":   "line 1\nline 2",
+		"CRLF":    "value",
+		"LAST":    "unterminated",
+	}, values)
+}
+
+func TestSystemdEnvFileSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "systemd")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.env")
+	require.NoError(t, ioutil.WriteFile(path, []byte("WORKERS=4\nNAME=\"my app\"\n"), 0o600))
+
+	var envs struct {
+		Workers int    `env:"WORKERS"`
+		Name    string `env:"NAME"`
+	}
+
+	p, err := NewParser(Config{Sources: []Source{SystemdEnvFileSource(path)}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, 4, envs.Workers)
+	assert.Equal(t, "my app", envs.Name)
+
+	// a missing file is an error unless the path starts with a dash
+	missing := filepath.Join(dir, "missing.env")
+
+	p, err = NewParser(Config{Sources: []Source{SystemdEnvFileSource(missing)}}, &envs)
+	require.NoError(t, err)
+	assert.Error(t, p.Parse())
+
+	p, err = NewParser(Config{Sources: []Source{SystemdEnvFileSource("-" + missing)}}, &envs)
+	require.NoError(t, err)
+	assert.NoError(t, p.Parse())
+}
@@ -0,0 +1,236 @@
+package env
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	tmplparse "text/template/parse"
+)
+
+// isTemplate returns true if the default value is a template, such as
+// "{{ .Host }}:{{ .Port }}".
+func isTemplate(def string) bool {
+	return strings.Contains(def, "{{")
+}
+
+// newDefaultTemplate parses a templated default value.
+func newDefaultTemplate(def string) (*template.Template, error) {
+	return template.New("default").Option("missingkey=error").Parse(def)
+}
+
+// templateFields returns the fields, such as [DB Host] for .DB.Host, that
+// the nodes of a template refer to. The fields within with and range
+// blocks, which refer to the value of their pipeline, are left out.
+func templateFields(node tmplparse.Node) [][]string {
+	var chains [][]string
+
+	var visit func(tmplparse.Node)
+
+	visit = func(node tmplparse.Node) {
+		switch n := node.(type) {
+		case *tmplparse.ListNode:
+			if n == nil {
+				return
+			}
+
+			for _, c := range n.Nodes {
+				visit(c)
+			}
+		case *tmplparse.ActionNode:
+			visit(n.Pipe)
+		case *tmplparse.PipeNode:
+			if n == nil {
+				return
+			}
+
+			for _, c := range n.Cmds {
+				visit(c)
+			}
+		case *tmplparse.CommandNode:
+			for _, c := range n.Args {
+				visit(c)
+			}
+		case *tmplparse.FieldNode:
+			chains = append(chains, n.Ident)
+		case *tmplparse.IfNode:
+			visit(n.Pipe)
+			visit(n.List)
+			visit(n.ElseList)
+		case *tmplparse.WithNode:
+			visit(n.Pipe)
+			visit(n.ElseList)
+		case *tmplparse.RangeNode:
+			visit(n.Pipe)
+			visit(n.ElseList)
+		case *tmplparse.TemplateNode:
+			visit(n.Pipe)
+		}
+	}
+
+	visit(node)
+
+	return chains
+}
+
+// fieldIndex returns the index sequence of the field of the struct t, or
+// of a pointer to it, reached by the chain of names. The chain stops at the
+// first method.
+func fieldIndex(t reflect.Type, chain []string) ([]int, bool) {
+	var index []int
+
+	for _, name := range chain {
+		if _, ok := t.MethodByName(name); ok {
+			return index, true
+		}
+
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if t.Kind() != reflect.Struct {
+			return nil, false
+		}
+
+		field, ok := t.FieldByName(name)
+		if !ok {
+			if _, ok := reflect.PtrTo(t).MethodByName(name); ok {
+				return index, true
+			}
+
+			return nil, false
+		}
+
+		index = append(index, field.Index...)
+		t = field.Type
+	}
+
+	return index, true
+}
+
+// orderTemplates checks the fields the templated default values refer to,
+// and sorts the options having them so that those referred to by a
+// template come before it.
+func (p *Parser) orderTemplates() error {
+	var templated []*spec
+
+	for _, spec := range p.specs {
+		if spec.defaultTemplate == nil || spec.defaultValue.IsValid() {
+			continue
+		}
+
+		for _, chain := range templateFields(spec.defaultTemplate.Tree.Root) {
+			if _, ok := fieldIndex(p.roots[spec.dest.root].Type(), chain); !ok {
+				return fmt.Errorf("%v: default: .%s: %w", spec.dest, strings.Join(chain, "."), ErrorUnknownField)
+			}
+		}
+
+		templated = append(templated, spec)
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	state := make(map[*spec]int, len(templated))
+
+	var visit func(spec *spec, stack []string) error
+
+	visit = func(spec *spec, stack []string) error {
+		stack = append(stack, spec.name)
+
+		switch state[spec] {
+		case visiting:
+			return fmt.Errorf("%s: %w", strings.Join(stack, " -> "), ErrorDefaultCycle)
+		case visited:
+			return nil
+		}
+
+		state[spec] = visiting
+
+		for _, dep := range templated {
+			if p.refersTo(spec, dep) {
+				if err := visit(dep, stack[:len(stack):len(stack)]); err != nil {
+					return err
+				}
+			}
+		}
+
+		state[spec] = visited
+		p.templated = append(p.templated, spec)
+
+		return nil
+	}
+
+	for _, spec := range templated {
+		if err := visit(spec, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// refersTo returns true if the templated default value of s refers to the
+// field of other, or to a struct holding it.
+func (p *Parser) refersTo(s, other *spec) bool {
+	if s.dest.root != other.dest.root {
+		return false
+	}
+
+	var target []int
+	for _, f := range other.dest.fields {
+		target = append(target, f.Index...)
+	}
+
+	for _, chain := range templateFields(s.defaultTemplate.Tree.Root) {
+		index, _ := fieldIndex(p.roots[s.dest.root].Type(), chain)
+		if len(index) > 0 && len(index) <= len(target) && reflect.DeepEqual(index, target[:len(index)]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// renderDefault sets the field of spec to its default value rendered
+// against the struct it belongs to, as filled in so far.
+func (p *Parser) renderDefault(roots []reflect.Value, spec *spec) Errors {
+	value, err := spec.render(roots[spec.dest.root])
+	if err != nil {
+		err = p.messages.invalidDefault(spec.name, err)
+
+		return Errors{p.fieldError(spec, spec.name, err)}
+	}
+
+	if err := p.parseDefault(resolveAlloc(roots, spec.dest), spec, value); err != nil {
+		err = p.messages.invalidDefault(spec.name, spec.redactError(err, value))
+
+		return Errors{p.fieldError(spec, spec.name, err)}
+	}
+
+	var errs Errors
+
+	if err := spec.checkUnsetItems(p.messages, roots); err != nil {
+		errs = append(errs, p.fieldError(spec, spec.name, err))
+	}
+
+	if err := spec.checkPath(resolve(roots, spec.dest)); err != nil {
+		errs = append(errs, p.fieldError(spec, spec.name, wrapf(p.messages.DefaultValue, spec.name, err)))
+	}
+
+	return errs
+}
+
+// render returns the default value of s rendered against root.
+func (s *spec) render(root reflect.Value) (string, error) {
+	var b bytes.Buffer
+
+	if err := s.defaultTemplate.Execute(&b, root.Interface()); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,106 @@
+package env
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateDefault(t *testing.T) {
+	type config struct {
+		Addr string `default:"{{ .Host }}:{{ .Port }}"`
+		URL  string `default:"http://{{ .Addr }}/"`
+		Host string `default:"localhost"`
+		Port int    `default:"8080"`
+	}
+
+	var envs config
+
+	require.NoError(t, parse(envsMap{}, &envs))
+	assert.Equal(t, "localhost:8080", envs.Addr)
+	assert.Equal(t, "http://localhost:8080/", envs.URL)
+
+	envs = config{}
+
+	require.NoError(t, parse(envsMap{"host": "example.com", "url": "https://example.org/"}, &envs))
+	assert.Equal(t, "example.com:8080", envs.Addr)
+	assert.Equal(t, "https://example.org/", envs.URL)
+}
+
+func TestTemplateDefaultNested(t *testing.T) {
+	type DB struct {
+		Host string `default:"localhost"`
+		Name string `default:"app"`
+	}
+
+	var envs struct {
+		DB
+		DSN     string `default:"postgres://{{ .DB.Host }}/{{ .Name }}"`
+		Timeout int    `default:"{{ if .Name }}30{{ else }}10{{ end }}"`
+	}
+
+	require.NoError(t, parse(envsMap{"name": "orders"}, &envs))
+	assert.Equal(t, "postgres://localhost/orders", envs.DSN)
+	assert.Equal(t, 30, envs.Timeout)
+}
+
+func TestTemplateDefaultInvalidValue(t *testing.T) {
+	var envs struct {
+		Port  string `default:"8080"`
+		Count int    `default:"{{ .Port }}x"`
+	}
+
+	err := parse(envsMap{}, &envs)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error processing default value for count")
+}
+
+func TestTemplateDefaultCycle(t *testing.T) {
+	var envs struct {
+		A string `default:"{{ .B }}"`
+		B string `default:"{{ .C }}"`
+		C string `default:"{{ .A }}"`
+	}
+
+	_, err := NewParser(Config{}, &envs)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrorDefaultCycle))
+	assert.Contains(t, err.Error(), "a -> b -> c -> a")
+}
+
+func TestTemplateDefaultUnknownField(t *testing.T) {
+	var envs struct {
+		Addr string `default:"{{ .Hots }}:80"`
+		Host string
+	}
+
+	_, err := NewParser(Config{}, &envs)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrorUnknownField))
+}
+
+func TestTemplateDefaultMalformed(t *testing.T) {
+	var envs struct {
+		Addr string `default:"{{ .Host"`
+		Host string
+	}
+
+	_, err := NewParser(Config{}, &envs)
+	require.Error(t, err)
+}
+
+func TestTemplateDefaultIndexed(t *testing.T) {
+	var envs struct {
+		Backends []struct {
+			Host string
+			Addr string `default:"{{ .Host }}:80"`
+		}
+	}
+
+	require.NoError(t, parse(envsMap{"backends_0_host": "a", "backends_1_host": "b"}, &envs))
+	require.Len(t, envs.Backends, 2)
+	assert.Equal(t, "a:80", envs.Backends[0].Addr)
+	assert.Equal(t, "b:80", envs.Backends[1].Addr)
+}
@@ -0,0 +1,12 @@
+//go:build toml
+// +build toml
+
+package env
+
+import "github.com/BurntSushi/toml"
+
+// TOMLFileSource returns a Source reading variables from a TOML file, see
+// FileSource. It is only available with the toml build tag.
+func TOMLFileSource(path string) Source {
+	return FileSource(path, toml.Unmarshal)
+}
@@ -0,0 +1,34 @@
+//go:build toml
+// +build toml
+
+package env
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOMLFileSource(t *testing.T) {
+	var envs struct {
+		Workers int
+		Debug   bool
+		DBHost  string `env:"DB_HOST"`
+		DBPort  int    `env:"db.port"`
+		Hosts   []string
+		Started time.Time
+	}
+
+	p, err := NewParser(Config{Sources: []Source{TOMLFileSource("testdata/config.toml")}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+
+	assert.Equal(t, 8, envs.Workers)
+	assert.True(t, envs.Debug)
+	assert.Equal(t, "localhost", envs.DBHost)
+	assert.Equal(t, 5432, envs.DBPort)
+	assert.Equal(t, []string{"a", "b"}, envs.Hosts)
+	assert.True(t, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC).Equal(envs.Started))
+}
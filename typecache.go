@@ -0,0 +1,89 @@
+package env
+
+import (
+	"reflect"
+	"sync"
+)
+
+// typeCache holds the options and groups of the struct types already walked
+// by NewParser, keyed by the pointer type of the destination, so that
+// parsing the same type again does not walk it again.
+var typeCache sync.Map // nolint:gochecknoglobals
+
+// typeSpecs are the options and groups of a struct type, never changed once
+// cached.
+type typeSpecs struct {
+	specs  []*spec
+	groups []*group
+}
+
+// cachedSpecsFromStruct returns the options and groups of the struct pointed
+// to by t like specsFromStruct, walking t only once. The results are copies
+// that the parser is free to change, such as to set the defaults from the
+// values of the fields.
+func cachedSpecsFromStruct(dest path, t reflect.Type, parsers map[reflect.Type]ParserFunc) ([]*spec, []*group, error) {
+	// the custom parsers change which fields are parsed and which expanded
+	if len(parsers) > 0 {
+		return specsFromStruct(dest, t, parsers)
+	}
+
+	if cached, ok := typeCache.Load(t); ok {
+		specs, groups := cached.(*typeSpecs).copy(dest.root)
+
+		return specs, groups, nil
+	}
+
+	specs, groups, err := specsFromStruct(dest, t, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cached := &typeSpecs{specs: specs, groups: groups}
+	typeCache.Store(t, cached)
+
+	specs, groups = cached.copy(dest.root)
+
+	return specs, groups, nil
+}
+
+// copy returns copies of the options and groups for the destination struct
+// at index root.
+func (c *typeSpecs) copy(root int) ([]*spec, []*group) {
+	groups := make([]*group, len(c.groups))
+	copies := make(map[*group]*group, len(c.groups))
+
+	for i, g := range c.groups {
+		cp := *g
+		cp.dest.root = root
+
+		if g.enabled != nil {
+			enabled := *g.enabled
+			enabled.root = root
+			cp.enabled = &enabled
+		}
+
+		groups[i] = &cp
+		copies[g] = &cp
+	}
+
+	for _, g := range groups {
+		if g.parent != nil {
+			g.parent = copies[g.parent]
+		}
+	}
+
+	specs := make([]*spec, len(c.specs))
+
+	for i, s := range c.specs {
+		cp := *s
+		cp.dest.root = root
+
+		if s.group != nil {
+			cp.group = copies[s.group]
+		}
+
+		specs[i] = &cp
+	}
+
+	return specs, groups
+}
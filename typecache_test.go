@@ -0,0 +1,56 @@
+package env
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cachedConfig struct {
+	Host string
+	Port int
+	DB   *struct {
+		User string `env:"db_user"`
+	} `onMissing:"disable"`
+}
+
+func TestTypeCache(t *testing.T) {
+	a := cachedConfig{Port: 80}
+	b := cachedConfig{Port: 8080}
+
+	pa, err := NewParser(Config{}, &a)
+	require.NoError(t, err)
+
+	_, ok := typeCache.Load(reflect.TypeOf(&a))
+	assert.True(t, ok)
+
+	pb, err := NewParser(Config{NameStyle: ScreamingSnakeCase}, &b)
+	require.NoError(t, err)
+
+	// the defaults and names are those of each parser
+	assert.Equal(t, "80", pa.specs[1].defaultVal)
+	assert.Equal(t, "8080", pb.specs[1].defaultVal)
+	assert.Equal(t, "host", pa.specs[0].name)
+	assert.Equal(t, "HOST", pb.specs[0].name)
+	assert.NotSame(t, pa.specs[2].group, pb.specs[2].group)
+	assert.Same(t, pa.groups[0], pa.specs[2].group)
+}
+
+func TestTypeCacheRoots(t *testing.T) {
+	var a, b cachedConfig
+
+	p, err := NewParser(Config{
+		Sources:          []Source{MapSource{"host": "h", "db_user": "u"}},
+		AllowSharedNames: true,
+	}, &a, &b)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+
+	assert.Equal(t, "h", a.Host)
+	assert.Equal(t, "h", b.Host)
+	require.NotNil(t, a.DB)
+	require.NotNil(t, b.DB)
+	assert.Equal(t, "u", b.DB.User)
+}
@@ -0,0 +1,116 @@
+package env
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+
+	scalar "github.com/alexflint/go-scalar"
+)
+
+// Unmarshaler is the interface implemented by types that parse their
+// environment form differently from their text form, such as lists with
+// escapes. It takes precedence over encoding.TextUnmarshaler.
+type Unmarshaler interface {
+	UnmarshalEnv(value string) error
+}
+
+// Setter is the interface implemented by the values of flag-style packages,
+// such as the Set method of flag.Value, so that their types can be parsed
+// from variables too. It is checked after Unmarshaler, and before
+// encoding.TextUnmarshaler.
+type Setter interface {
+	Set(value string) error
+}
+
+// nolint:gochecknoglobals
+var (
+	unmarshalerType       = reflect.TypeOf([]Unmarshaler{}).Elem()
+	setterType            = reflect.TypeOf([]Setter{}).Elem()
+	binaryUnmarshalerType = reflect.TypeOf([]encoding.BinaryUnmarshaler{}).Elem()
+	jsonUnmarshalerType   = reflect.TypeOf([]json.Unmarshaler{}).Elem()
+)
+
+// isUnmarshaler returns true if t or a pointer to t implements one of the
+// interfaces parseValue supports besides those of scalar.ParseValue.
+func isUnmarshaler(t reflect.Type) bool {
+	for _, iface := range []reflect.Type{unmarshalerType, setterType, binaryUnmarshalerType, jsonUnmarshalerType} {
+		if t.Implements(iface) || reflect.PtrTo(t).Implements(iface) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseValue parses value into dest, trying in order:
+//   - Unmarshaler,
+//   - Setter,
+//   - regexp.Regexp compiled from the pattern,
+//   - time.Location loaded from its name,
+//   - os.FileMode from octal permissions,
+//   - the text and scalar forms of scalar.ParseValue,
+//   - encoding.BinaryUnmarshaler with a base64 value,
+//   - json.Unmarshaler with a JSON value.
+func parseValue(dest reflect.Value, value string) error {
+	if u, ok := asInterface(dest, unmarshalerType); ok {
+		return u.(Unmarshaler).UnmarshalEnv(value)
+	}
+
+	if u, ok := asInterface(dest, setterType); ok {
+		return u.(Setter).Set(value)
+	}
+
+	if isRegexp(dest.Type()) {
+		return parseRegexp(dest, value)
+	}
+
+	if isLocation(dest.Type()) {
+		return parseLocation(dest, value)
+	}
+
+	if isFileMode(dest.Type()) {
+		return parseFileMode(dest, value)
+	}
+
+	if scalar.CanParse(dest.Type()) {
+		return scalar.ParseValue(dest, value)
+	}
+
+	if u, ok := asInterface(dest, binaryUnmarshalerType); ok {
+		data, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return err
+		}
+
+		return u.(encoding.BinaryUnmarshaler).UnmarshalBinary(data)
+	}
+
+	if u, ok := asInterface(dest, jsonUnmarshalerType); ok {
+		return u.(json.Unmarshaler).UnmarshalJSON([]byte(value))
+	}
+
+	return scalar.ParseValue(dest, value)
+}
+
+// asInterface returns dest, or its address, as a value implementing iface.
+// Nil pointers are allocated.
+func asInterface(dest reflect.Value, iface reflect.Type) (interface{}, bool) {
+	switch {
+	case dest.Kind() == reflect.Ptr && dest.Type().Implements(iface):
+		if dest.IsNil() {
+			if !dest.CanSet() {
+				return nil, false
+			}
+
+			dest.Set(reflect.New(dest.Type().Elem()))
+		}
+
+		return dest.Interface(), true
+	case dest.CanAddr() && reflect.PtrTo(dest.Type()).Implements(iface):
+		return dest.Addr().Interface(), true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,127 @@
+package env
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// escapedList is a list whose environment form separates the elements with
+// commas, escaped with backslashes, and whose text form is a JSON-like list.
+type escapedList []string
+
+func (l *escapedList) UnmarshalEnv(value string) error {
+	var elem strings.Builder
+
+	*l = nil
+
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value):
+			i++
+			elem.WriteByte(value[i])
+		case value[i] == ',':
+			*l = append(*l, elem.String())
+			elem.Reset()
+		default:
+			elem.WriteByte(value[i])
+		}
+	}
+
+	*l = append(*l, elem.String())
+
+	return nil
+}
+
+func (l *escapedList) UnmarshalText(text []byte) error {
+	*l = escapedList{"text:" + string(text)}
+
+	return nil
+}
+
+// upper is a string parsed in upper case.
+type upper string
+
+func (u *upper) UnmarshalEnv(value string) error {
+	*u = upper(strings.ToUpper(value))
+
+	return nil
+}
+
+func TestUnmarshaler(t *testing.T) {
+	var envs struct {
+		List   escapedList
+		Ptr    *escapedList
+		Name   upper
+		Names  []upper
+		Ptrs   []*upper
+		Quoted escapedList `default:"x\\,y"`
+	}
+
+	err := parse(envsMap{
+		"list":  `a\,b,c`,
+		"ptr":   "d",
+		"name":  "foo",
+		"names": "a,b",
+		"ptrs":  "c",
+	}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, escapedList{"a,b", "c"}, envs.List)
+	assert.Equal(t, &escapedList{"d"}, envs.Ptr)
+	assert.Equal(t, upper("FOO"), envs.Name)
+	assert.Equal(t, []upper{"A", "B"}, envs.Names)
+	require.Len(t, envs.Ptrs, 1)
+	assert.Equal(t, upper("C"), *envs.Ptrs[0])
+	assert.Equal(t, escapedList{"x,y"}, envs.Quoted)
+}
+
+// key is a fixed-size binary key.
+type key struct {
+	data [4]byte
+}
+
+func (k *key) UnmarshalBinary(data []byte) error {
+	if len(data) != len(k.data) {
+		return errors.New("invalid key length")
+	}
+
+	copy(k.data[:], data)
+
+	return nil
+}
+
+// thresholds are decoded from JSON.
+type thresholds map[string]float64
+
+func (t *thresholds) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, (*map[string]float64)(t))
+}
+
+func TestBinaryAndJSONUnmarshalers(t *testing.T) {
+	var envs struct {
+		Key        key
+		Keys       []*key
+		Thresholds thresholds
+	}
+
+	err := parse(envsMap{
+		"key":        "AQIDBA==",
+		"keys":       "AQIDBA==,BQYHCA==",
+		"thresholds": `{"p99": 0.2, "p50": 0.1}`,
+	}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, key{[4]byte{1, 2, 3, 4}}, envs.Key)
+	require.Len(t, envs.Keys, 2)
+	assert.Equal(t, key{[4]byte{5, 6, 7, 8}}, *envs.Keys[1])
+	assert.Equal(t, thresholds{"p99": 0.2, "p50": 0.1}, envs.Thresholds)
+
+	err = parse(envsMap{"key": "not base64", "thresholds": "{"}, &envs)
+
+	var errs Errors
+	require.True(t, errors.As(err, &errs))
+	assert.Len(t, errs, 2)
+}
@@ -0,0 +1,87 @@
+package env
+
+import (
+	"os"
+	"reflect"
+)
+
+// scrub is a variable to remove from the process environment once the
+// value captured from it is applied.
+type scrub struct {
+	spec    *spec
+	environ string        // name of the variable in the process environment
+	origin  string        // origin of the captured value, kept for Values
+	value   reflect.Value // captured value, kept for the next parses
+}
+
+// scrubLater records that the variable with the given name must be removed
+// from the process environment if the source that provided it, among
+// sources, reads the process environment, so that it is neither inherited
+// by child processes nor visible in /proc. Nothing is removed until the
+// parse succeeds, see Parser.scrub.
+func (p *Parser) scrubLater(roots []reflect.Value, s *spec, sources []Source, name, origin string) {
+	for _, src := range s.ordered(sources) {
+		if !isFound(src, name) {
+			continue
+		}
+
+		if environ, ok := environName(src, name); ok {
+			p.scrubs = append(p.scrubs, scrub{
+				spec:    s,
+				environ: environ,
+				origin:  origin,
+				value:   cloneValue(resolve(roots, s.dest)),
+			})
+		}
+
+		return
+	}
+}
+
+// scrub removes the variables recorded by scrubLater from the process
+// environment. It is called once the captured values are applied, so that
+// a failed parse leaves the environment untouched.
+func (p *Parser) scrub() {
+	for _, s := range p.scrubs {
+		_ = os.Unsetenv(s.environ)
+		s.spec.scrubbed = s.origin
+		s.spec.scrubbedVal = s.value
+	}
+
+	p.scrubs = nil
+}
+
+// environName returns the name of the variable of the process environment
+// through which src provides the variable name, and false if src does not
+// read it from the process environment.
+func environName(src Source, name string) (string, bool) {
+	switch src := src.(type) {
+	case envSource:
+		_, found := os.LookupEnv(name)
+
+		return name, found
+	case namedSource:
+		return environName(src.Source, name)
+	case profileSource:
+		if override := profileName(name, src.profile); isFound(src.Source, override) {
+			return environName(src.Source, override)
+		}
+
+		return environName(src.Source, name)
+	case relaxedSource:
+		for _, form := range relaxedForms(name) {
+			if isFound(src.src, form) {
+				return environName(src.src, form)
+			}
+		}
+	}
+
+	return "", false
+}
+
+// isFound reports whether src has the variable.
+func isFound(src Source, name string) bool {
+	_, found := src.Lookup(name)
+
+	return found
+}
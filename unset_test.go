@@ -0,0 +1,194 @@
+package env
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnset(t *testing.T) {
+	var envs struct {
+		Token   string `env:"TOKEN,alias:API_TOKEN,unset"`
+		Port    int    `env:"PORT,unset" default:"80"`
+		Workers int    `env:"WORKERS"`
+	}
+
+	p, err := pparse(envsMap{"TOKEN": "secret", "API_TOKEN": "old", "WORKERS": "4"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", envs.Token)
+	assert.Equal(t, 80, envs.Port)
+
+	_, found := os.LookupEnv("TOKEN")
+	assert.False(t, found)
+	_, found = os.LookupEnv("WORKERS")
+	assert.True(t, found)
+
+	// only the variable that provided the value is scrubbed
+	_, found = os.LookupEnv("API_TOKEN")
+	assert.True(t, found)
+	require.NoError(t, os.Unsetenv("API_TOKEN"))
+
+	// the scrubbed variable keeps its value on reload
+	require.NoError(t, p.Reload())
+	assert.Equal(t, "secret", envs.Token)
+}
+
+func TestUnsetFallback(t *testing.T) {
+	var envs struct {
+		Token string `env:"TOKEN,unset" defaultEnv:"LEGACY_TOKEN"`
+	}
+
+	err := parse(envsMap{"LEGACY_TOKEN": "secret"}, &envs)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", envs.Token)
+
+	_, found := os.LookupEnv("LEGACY_TOKEN")
+	assert.False(t, found)
+}
+
+func TestUnsetOtherEnvironment(t *testing.T) {
+	os.Clearenv()
+	require.NoError(t, os.Setenv("TOKEN", "process"))
+
+	defer os.Clearenv()
+
+	var envs struct {
+		Token string `env:"TOKEN,unset"`
+	}
+
+	// the process environment is left alone when it is not read
+	p, err := NewParser(Config{}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.ParseMap(map[string]string{"TOKEN": "map"}))
+	assert.Equal(t, "map", envs.Token)
+
+	p, err = NewParser(Config{Lookup: MapSource{"TOKEN": "lookup"}.Lookup}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, "lookup", envs.Token)
+
+	value, found := os.LookupEnv("TOKEN")
+	assert.True(t, found)
+	assert.Equal(t, "process", value)
+}
+
+func TestUnsetMalformed(t *testing.T) {
+	var envs struct {
+		Token string `env:"TOKEN,unset:yes"`
+	}
+
+	_, err := NewParser(Config{}, &envs)
+	assert.Error(t, err)
+}
+
+func TestUnsetProfileOverride(t *testing.T) {
+	var envs struct {
+		Token string `env:"TOKEN,unset"`
+	}
+
+	os.Clearenv()
+	require.NoError(t, os.Setenv("TOKEN", "prod"))
+	require.NoError(t, os.Setenv("TOKEN__STAGING", "staging"))
+
+	defer os.Clearenv()
+
+	p, err := NewParser(Config{Profile: "staging"}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, "staging", envs.Token)
+
+	// the override provided the value, not TOKEN
+	_, found := os.LookupEnv("TOKEN__STAGING")
+	assert.False(t, found)
+	_, found = os.LookupEnv("TOKEN")
+	assert.True(t, found)
+}
+
+func TestUnsetMixedSources(t *testing.T) {
+	dir, err := ioutil.TempDir("", "unset")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"TOKEN": "file"}`), 0o600))
+
+	os.Clearenv()
+	require.NoError(t, os.Setenv("TOKEN", "process"))
+	require.NoError(t, os.Setenv("KEY", "process"))
+
+	defer os.Clearenv()
+
+	var envs struct {
+		Token string `env:"TOKEN,unset"`
+		Key   string `env:"KEY,unset"`
+	}
+
+	p, err := NewParser(Config{Sources: []Source{JSONFileSource(path), EnvSource()}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+	assert.Equal(t, "file", envs.Token)
+	assert.Equal(t, "process", envs.Key)
+
+	// the file provided TOKEN, the process environment KEY
+	value, found := os.LookupEnv("TOKEN")
+	assert.True(t, found)
+	assert.Equal(t, "process", value)
+	_, found = os.LookupEnv("KEY")
+	assert.False(t, found)
+}
+
+func TestUnsetFailedParse(t *testing.T) {
+	os.Clearenv()
+	require.NoError(t, os.Setenv("TOKEN", "secret"))
+	require.NoError(t, os.Setenv("PORT", "eighty"))
+
+	defer os.Clearenv()
+
+	var envs struct {
+		Token string `env:"TOKEN,unset,required"`
+		Port  int    `env:"PORT"`
+	}
+
+	p, err := NewParser(Config{}, &envs)
+	require.NoError(t, err)
+	require.Error(t, p.Parse())
+
+	// the failed parse left the environment untouched
+	value, found := os.LookupEnv("TOKEN")
+	assert.True(t, found)
+	assert.Equal(t, "secret", value)
+
+	require.NoError(t, os.Setenv("PORT", "80"))
+	require.NoError(t, p.Parse())
+	assert.Equal(t, "secret", envs.Token)
+	assert.Equal(t, 80, envs.Port)
+
+	_, found = os.LookupEnv("TOKEN")
+	assert.False(t, found)
+
+	// the required variable is still satisfied by the captured value
+	require.NoError(t, p.Parse())
+	assert.Equal(t, "secret", envs.Token)
+	assert.Equal(t, "env", p.Values()[0].Origin)
+}
+
+func TestUnsetCheck(t *testing.T) {
+	os.Clearenv()
+	require.NoError(t, os.Setenv("TOKEN", "secret"))
+
+	defer os.Clearenv()
+
+	var envs struct {
+		Token string `env:"TOKEN,unset"`
+	}
+
+	require.NoError(t, Check([]string{"TOKEN=secret"}, &envs))
+
+	_, found := os.LookupEnv("TOKEN")
+	assert.True(t, found)
+}
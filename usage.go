@@ -4,13 +4,14 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"reflect"
 	"strings"
 )
 
 // the width of the left column.
 const colWidth = 25
 
-func printTwoCols(w io.Writer, left, help, defaultVal string) {
+func printTwoCols(w io.Writer, left, help string, bracketsContent []string) {
 	lhs := "  " + left
 	fmt.Fprint(w, lhs)
 
@@ -24,14 +25,6 @@ func printTwoCols(w io.Writer, left, help, defaultVal string) {
 		fmt.Fprint(w, help)
 	}
 
-	bracketsContent := []string{}
-
-	if defaultVal != "" {
-		bracketsContent = append(bracketsContent,
-			fmt.Sprintf("default: %s", defaultVal),
-		)
-	}
-
 	if len(bracketsContent) > 0 {
 		fmt.Fprintf(w, " [%s]", strings.Join(bracketsContent, ", "))
 	}
@@ -52,6 +45,11 @@ func (p *Parser) Help() string {
 func (p *Parser) writeHelp(w io.Writer, specs []*spec) {
 	options := make([]*spec, 0, len(specs))
 	options = append(options, specs...)
+	options = append(options, p.indexedOptions()...)
+
+	if p.version != "" {
+		fmt.Fprintln(w, p.version)
+	}
 
 	if p.description != "" {
 		fmt.Fprintln(w, p.description)
@@ -59,19 +57,128 @@ func (p *Parser) writeHelp(w io.Writer, specs []*spec) {
 
 	// write the list of options
 	if len(options) > 0 {
-		fmt.Fprint(w, "Environments:\n")
+		fmt.Fprintln(w, p.messages.Environments)
 
 		for _, spec := range options {
 			p.printOption(w, spec)
 		}
 	}
+
+	if p.epilogue != "" {
+		if p.version != "" || p.description != "" || len(options) > 0 {
+			fmt.Fprintln(w)
+		}
+
+		fmt.Fprintln(w, p.epilogue)
+	}
 }
 
 func (p *Parser) printOption(w io.Writer, spec *spec) {
-	left := synopsis(spec, spec.name)
-	printTwoCols(w, left, spec.help, spec.defaultVal)
+	left := p.synopsis(spec, spec.name)
+	printTwoCols(w, left, spec.help, p.brackets(spec))
+}
+
+// brackets returns the details printed in brackets after the help string.
+func (p *Parser) brackets(spec *spec) []string {
+	bracketsContent := []string{}
+
+	if spec.aliases != nil {
+		bracketsContent = append(bracketsContent,
+			fmt.Sprintf("%s: %s", p.messages.Aliases, strings.Join(spec.aliases, ", ")),
+		)
+	}
+
+	if kind, rng, ok := intRange(spec.typ); ok && spec.levels == nil {
+		bracketsContent = append(bracketsContent,
+			fmt.Sprintf("%s: %s", kind, rng),
+		)
+	}
+
+	if spec.levels != nil {
+		bracketsContent = append(bracketsContent,
+			fmt.Sprintf("%s: %s", p.messages.Levels, strings.Join(spec.levelNames(), ", ")),
+		)
+	}
+
+	if spec.values != nil {
+		bracketsContent = append(bracketsContent,
+			fmt.Sprintf("%s: %s", p.messages.OneOf, strings.Join(spec.values, ", ")),
+		)
+	}
+
+	if items := spec.itemsRange(p.messages); items != "" {
+		bracketsContent = append(bracketsContent,
+			fmt.Sprintf("%s: %s", p.messages.Items, items),
+		)
+	}
+
+	if spec.defaultEnv != nil {
+		bracketsContent = append(bracketsContent,
+			fmt.Sprintf("%s: $%s", p.messages.Fallback, strings.Join(spec.defaultEnv, ", $")),
+		)
+	}
+
+	if defaultVal := spec.displayDefault(); defaultVal != "" {
+		bracketsContent = append(bracketsContent,
+			fmt.Sprintf("%s: %s", p.messages.Default, defaultVal),
+		)
+	}
+
+	if spec.docs != "" {
+		bracketsContent = append(bracketsContent,
+			fmt.Sprintf("%s: %s", p.messages.Docs, spec.docs),
+		)
+	}
+
+	return bracketsContent
 }
 
-func synopsis(spec *spec, form string) string {
+// synopsis returns the name of the option followed by the type of its
+// value, such as "WORKERS int (required)" or "HOSTS string,..." for lists.
+func (p *Parser) synopsis(spec *spec, form string) string {
+	form += " " + spec.valueType()
+	if spec.multiple {
+		form += ",..."
+	}
+
+	if spec.required {
+		form += " (" + p.messages.Required + ")"
+	}
+
 	return form
 }
+
+// valueType returns the type of the values of the option: the
+// language-neutral name of basic types, see typeName, the syntax of maps,
+// such as key=int,..., and the bare name of the others, such as IP for
+// net.IP, or text for the unnamed ones.
+func (s *spec) valueType() string {
+	if s.invert {
+		return "bool"
+	}
+
+	if s.mapped {
+		values := s.mapValueType()
+		if isMapOfSlices(s.typ) {
+			values += s.itemSep + "..."
+		}
+
+		return "key" + s.kvSep + values + ",..."
+	}
+
+	typ := typeName(s.typ)
+	if typ != "text" {
+		return typ
+	}
+
+	t := s.typ
+	for t.Name() == "" && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+
+	if t.Name() == "" {
+		return typ
+	}
+
+	return t.Name()
+}
@@ -42,18 +42,18 @@ func (n *NameDotName) MarshalText() (text []byte, err error) {
 
 func TestWriteUsage(t *testing.T) {
 	expectedHelp := `Environments:
-  input
-  output                 list of outputs
-  name                   name to use [default: Foo Bar]
-  value                  secret value [default: 42]
-  v                      verbosity level
-  dataset                dataset to use
-  O                      optimization level
-  ids                    Ids
-  values                 Values [default: [3.14 42 256]]
-  WORKERS                number of workers to start [default: 10]
-  TEST_ENV
-  f                      File with mandatory extension [default: scratch.txt]
+  input string
+  output string,...      list of outputs
+  name string            name to use [default: Foo Bar]
+  value int              secret value [default: 42]
+  v bool                 verbosity level
+  dataset string         dataset to use
+  O int                  optimization level
+  ids int,...            Ids
+  values float,...       Values [default: 3.14,42,256]
+  WORKERS int            number of workers to start [default: 10]
+  TEST_ENV string
+  f NameDotName          File with mandatory extension [default: scratch.txt]
 `
 
 	var args struct {
@@ -94,8 +94,8 @@ func (n *MyEnum) MarshalText() ([]byte, error) {
 
 func TestUsageWithDefaults(t *testing.T) {
 	expectedHelp := `Environments:
-  label [default: cat]
-  content [default: dog]
+  label string [default: cat]
+  content string [default: dog]
 `
 
 	var args struct {
@@ -140,3 +140,91 @@ func TestUsageWithDescription(t *testing.T) {
 	help := p.Help()
 	assert.Equal(t, expectedHelp, help)
 }
+
+type versioned struct {
+	Foo string
+}
+
+func (versioned) Version() string {
+	return "example 1.0"
+}
+
+func (versioned) Description() string {
+	return "this program does this and that"
+}
+
+func (versioned) Epilogue() string {
+	return "For more information visit github.com/Alex616/go-env"
+}
+
+func TestUsageWithVersionAndEpilogue(t *testing.T) {
+	expectedHelp := `example 1.0
+this program does this and that
+Environments:
+  foo string
+
+For more information visit github.com/Alex616/go-env
+`
+	p, err := env.NewParser(env.Config{}, &versioned{})
+	require.NoError(t, err)
+	assert.Equal(t, expectedHelp, p.Help())
+}
+
+type epilogued struct{}
+
+func (epilogued) Epilogue() string {
+	return "see the manual"
+}
+
+func TestUsageWithEpilogueOnly(t *testing.T) {
+	p, err := env.NewParser(env.Config{}, &epilogued{})
+	require.NoError(t, err)
+	assert.Equal(t, "see the manual\n", p.Help())
+}
+
+func TestUsageWithAliases(t *testing.T) {
+	expectedHelp := `Environments:
+  DATABASE_URL string    database to use [aliases: DB_URL, POSTGRES_URL]
+`
+
+	var args struct {
+		URL string `env:"name:DATABASE_URL, alias:DB_URL, alias:POSTGRES_URL" help:"database to use"`
+	}
+
+	p, err := env.NewParser(env.Config{}, &args)
+	require.NoError(t, err)
+	assert.Equal(t, expectedHelp, p.Help())
+}
+
+type OnlyUnmarshaler struct {
+	Head, Tail string
+}
+
+func (n *OnlyUnmarshaler) UnmarshalText(b []byte) error {
+	return nil
+}
+
+func TestUsageDefaultsWithoutMarshaler(t *testing.T) {
+	expectedHelp := `Environments:
+  name OnlyUnmarshaler
+  file OnlyUnmarshaler [default: file.txt]
+  count int [default: 8]
+  mode string            mode to use [default: the fast one]
+`
+
+	var args struct {
+		Name  OnlyUnmarshaler
+		File  OnlyUnmarshaler `defaultText:"file.txt"`
+		Count *int
+		Mode  string `help:"mode to use" default:"fast" defaultText:"the fast one"`
+	}
+
+	count := 8
+	args.Name = OnlyUnmarshaler{"a", "b"}
+	args.File = OnlyUnmarshaler{"file", "txt"}
+	args.Count = &count
+
+	p, err := env.NewParser(env.Config{}, &args)
+	require.NoError(t, err)
+	assert.Equal(t, expectedHelp, p.Help())
+}
@@ -0,0 +1,76 @@
+package env
+
+// OriginDefault is the origin of the values coming from a default value:
+// the default tag, the initial value of the field or a Defaulter.
+const OriginDefault = "default"
+
+// Value describes the effective value of a variable, as returned by
+// Values.
+type Value struct {
+	// Name is the name of the variable.
+	Name string
+	// Field is the dotted path of the field, see FieldInfo.Path.
+	Field string
+	// Value is the value formatted the way it would be written in the
+	// environment. Secret values are redacted.
+	Value string
+	// Origin is the name of the source providing the value, see Named,
+	// its position among the sources such as "#2" if it has no name,
+	// OriginDefault for default values, or "" if the variable was not set
+	// and has no default.
+	Origin string
+	// Secret is true if the value is hidden.
+	Secret bool
+}
+
+// Values returns the effective value of every variable and where it comes
+// from, for instance to log the configuration at startup without leaking
+// secrets. The variables are in the order of the fields.
+func (p *Parser) Values() []Value {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	values := make([]Value, 0, len(p.specs))
+
+	for _, spec := range p.specs {
+		values = append(values, Value{
+			Name:   spec.name,
+			Field:  qualifiedPath(p.roots[spec.dest.root].Type().Elem(), spec.dest),
+			Value:  spec.redact(spec.format(p.val(spec.dest))),
+			Origin: p.origins[spec],
+			Secret: spec.secret || spec.mask,
+		})
+	}
+
+	return values
+}
+
+// origin returns the label of the first source, in the order of the
+// option, in which the variable with the given name is set.
+func (s *spec) origin(sources []Source, name string) string {
+	for _, i := range s.order(sources) {
+		if _, found := sources[i].Lookup(name); found {
+			return sourceLabel(sources[i], i)
+		}
+	}
+
+	return ""
+}
+
+// WasSet reports whether the variable named name, or one of its aliases, was
+// set by a source during the last successful Parse or Reload, even to an
+// empty value, as opposed to left unset.
+func (p *Parser) WasSet(name string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, spec := range p.specs {
+		if spec.name == name || containsString(spec.aliases, name) {
+			origin := p.origins[spec]
+
+			return origin != "" && origin != OriginDefault
+		}
+	}
+
+	return false
+}
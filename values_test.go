@@ -0,0 +1,73 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValues(t *testing.T) {
+	type config struct {
+		Host    string
+		Port    int    `default:"80"`
+		Token   string `env:"secret"`
+		Workers int
+		Name    string
+		Unset   string
+	}
+
+	envs := config{Name: "initial"}
+
+	p, err := NewParser(Config{Sources: []Source{
+		NamedSource("file", MapSource{"host": "db"}),
+		MapSource{"token": "abc", "workers": "4"},
+	}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+
+	assert.Equal(t, []Value{
+		{Name: "host", Field: "config.Host", Value: "db", Origin: "file"},
+		{Name: "port", Field: "config.Port", Value: "80", Origin: OriginDefault},
+		{Name: "token", Field: "config.Token", Value: "***", Origin: "#2", Secret: true},
+		{Name: "workers", Field: "config.Workers", Value: "4", Origin: "#2"},
+		{Name: "name", Field: "config.Name", Value: "initial", Origin: OriginDefault},
+		{Name: "unset", Field: "config.Unset", Value: "", Origin: ""},
+	}, p.Values())
+}
+
+func TestValuesFallbackAndUnset(t *testing.T) {
+	var envs struct {
+		Token  string `env:"TOKEN,unset"`
+		LogDir string `env:"LOG_DIR" defaultEnv:"HOME"`
+	}
+
+	p, err := pparse(envsMap{"TOKEN": "abc", "HOME": "/home/me"}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Reload())
+
+	values := p.Values()
+	assert.Equal(t, "env", values[0].Origin)
+	assert.Equal(t, "abc", values[0].Value)
+	assert.Equal(t, "env", values[1].Origin)
+}
+
+func TestWasSet(t *testing.T) {
+	var envs struct {
+		Host  string `env:"host,alias:server"`
+		Port  int    `default:"80"`
+		Label string
+		Debug bool
+	}
+
+	p, err := NewParser(Config{Sources: []Source{MapSource{"server": "h", "label": ""}}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+
+	assert.True(t, p.WasSet("host"))
+	assert.True(t, p.WasSet("server"))
+	assert.True(t, p.WasSet("label"))
+	assert.False(t, p.WasSet("port"))
+	assert.False(t, p.WasSet("debug"))
+	assert.False(t, p.WasSet("unknown"))
+}
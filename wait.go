@@ -0,0 +1,64 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"time"
+)
+
+const (
+	// defaultWaitInterval is the initial delay between two polls.
+	defaultWaitInterval = 100 * time.Millisecond
+	// maxWaitInterval caps the delay between two polls.
+	maxWaitInterval = 10 * time.Second
+)
+
+// waitRequired processes the environment into roots, retrying with
+// exponential backoff as long as required variables are missing and the
+// deadline from Config.WaitRequired has not passed.
+func (p *Parser) waitRequired(roots []reflect.Value) error {
+	deadline := time.Now().Add(p.config.WaitRequired)
+
+	delay := p.config.WaitInterval
+	if delay <= 0 {
+		delay = defaultWaitInterval
+	}
+
+	for {
+		err := p.process(roots)
+		if err == nil || !onlyMissing(err) {
+			return err
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return err
+		}
+
+		if delay > remaining {
+			delay = remaining
+		}
+
+		time.Sleep(delay)
+
+		if delay *= 2; delay > maxWaitInterval {
+			delay = maxWaitInterval
+		}
+	}
+}
+
+// onlyMissing reports whether err is only about missing required variables.
+func onlyMissing(err error) bool {
+	var errs Errors
+	if !errors.As(err, &errs) {
+		return false
+	}
+
+	for _, err := range errs {
+		if !errors.Is(err, ErrorFieldIsRequired) {
+			return false
+		}
+	}
+
+	return true
+}
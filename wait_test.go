@@ -0,0 +1,64 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitRequired(t *testing.T) {
+	var envs struct {
+		Token string `env:"token,required"`
+	}
+
+	p, err := NewParser(Config{WaitRequired: 5 * time.Second, WaitInterval: time.Millisecond}, &envs)
+	require.NoError(t, err)
+
+	os.Clearenv()
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_ = os.Setenv("token", "secret")
+	}()
+
+	err = p.Parse()
+	require.NoError(t, err)
+	assert.Equal(t, "secret", envs.Token)
+}
+
+func TestWaitRequiredDeadline(t *testing.T) {
+	var envs struct {
+		Token string `env:"token,required"`
+	}
+
+	p, err := NewParser(Config{WaitRequired: 20 * time.Millisecond, WaitInterval: time.Millisecond}, &envs)
+	require.NoError(t, err)
+
+	os.Clearenv()
+
+	start := time.Now()
+	err = p.Parse()
+	assert.True(t, errors.Is(err, ErrorFieldIsRequired))
+	assert.True(t, time.Since(start) >= 20*time.Millisecond)
+}
+
+func TestWaitRequiredInvalidValue(t *testing.T) {
+	var envs struct {
+		Workers int `env:"workers,required"`
+	}
+
+	p, err := NewParser(Config{WaitRequired: time.Minute}, &envs)
+	require.NoError(t, err)
+
+	os.Clearenv()
+	_ = os.Setenv("workers", "xyz")
+
+	// invalid values are reported immediately rather than waited on
+	err = p.Parse()
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrorFieldIsRequired))
+}
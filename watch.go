@@ -0,0 +1,184 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ChangeFunc is called after a reload changed the value of a variable. The
+// field is the name of the environment variable, old and new are the values
+// of the destination field before and after the reload.
+type ChangeFunc func(field string, old, new interface{})
+
+// OnChange registers a callback invoked for every variable whose value was
+// changed by Reload, Watch or a Parse following a successful one.
+func (p *Parser) OnChange(fn ChangeFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.onChange = append(p.onChange, fn)
+}
+
+// FieldChangeFunc is called after a reload changed the value of the
+// variable it was registered for. The old and new values are formatted the
+// way they would be written in the environment, and redacted if secret.
+type FieldChangeFunc func(old, new string)
+
+// OnFieldChange registers a callback invoked when the value of the variable
+// with the given name is changed by Reload, Watch or a Parse following a
+// successful one, such as a log level tuned while the process is running.
+func (p *Parser) OnFieldChange(name string, fn FieldChangeFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.onField == nil {
+		p.onField = make(map[string][]FieldChangeFunc)
+	}
+
+	p.onField[name] = append(p.onField[name], fn)
+}
+
+// OnError registers a callback invoked when a reload triggered by Watch
+// fails. The destination structs keep their previous values in that case.
+func (p *Parser) OnError(fn func(error)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.onError = append(p.onError, fn)
+}
+
+// change records a single variable changed by a reload.
+type change struct {
+	name          string
+	before, after interface{}
+	onField       []FieldChangeFunc // callbacks registered for the variable
+	old, new      string            // formatted values passed to onField
+}
+
+// Reload re-reads the sources into copies of the destination structs
+// and, if every variable could be processed, applies the changed values to
+// the destination structs and invokes the OnChange callbacks. The variables
+// removed since the last parse go back to their initial values. Call it
+// from a SIGHUP handler to re-parse on demand.
+func (p *Parser) Reload() error {
+	p.processMu.Lock()
+	defer p.processMu.Unlock()
+
+	shadow := p.copyRoots()
+
+	if err := p.process(shadow); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	changes := p.apply(shadow, false)
+	p.scrub()
+	p.parsed = true
+	callbacks := append([]ChangeFunc(nil), p.onChange...)
+	p.mu.Unlock()
+
+	notify(changes, callbacks)
+
+	return nil
+}
+
+// notify invokes the callbacks for the changes, without holding p.mu so
+// that they can call the methods of the parser.
+func notify(changes []change, callbacks []ChangeFunc) {
+	for _, c := range changes {
+		for _, fn := range callbacks {
+			fn(c.name, c.before, c.after)
+		}
+
+		for _, fn := range c.onField {
+			fn(c.old, c.new)
+		}
+	}
+}
+
+// View calls fn while holding the lock under which Parse, Reload and Watch
+// write the destination structs, so that fn reads consistent values while
+// the parser is reloaded from another goroutine. fn must not call the
+// methods of the parser.
+func (p *Parser) View(fn func()) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	fn()
+}
+
+// Watch reloads the sources every interval until ctx is done, and
+// returns the context error. Failed reloads are reported to the OnError
+// callbacks and leave the destination structs untouched. An interval that
+// is not positive fails with ErrorInvalidInterval.
+func (p *Parser) Watch(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("%v: %w", interval, ErrorInvalidInterval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := p.Reload(); err != nil {
+				p.reportError(err)
+			}
+		}
+	}
+}
+
+// reportError passes a reload error to the OnError callbacks.
+func (p *Parser) reportError(err error) {
+	p.mu.Lock()
+	callbacks := append([](func(error))(nil), p.onError...)
+	p.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn(err)
+	}
+}
+
+// apply copies every changed spec value from shadow into the destination
+// structs, and returns the changes. With all, the unchanged values are
+// copied as well so that the structs do not share pointers and slices with
+// the defaults. It must be called with p.mu held.
+func (p *Parser) apply(shadow []reflect.Value, all bool) []change {
+	var changes []change
+
+	p.applyGroups(shadow)
+
+	for _, spec := range append(p.compositeSpecs(), p.specs...) {
+		dst, src := p.val(spec.dest), resolve(shadow, spec.dest)
+		if !dst.IsValid() || !src.IsValid() {
+			continue
+		}
+
+		if !reflect.DeepEqual(dst.Interface(), src.Interface()) {
+			c := change{
+				name:   spec.name,
+				before: dst.Interface(),
+				after:  src.Interface(),
+			}
+
+			if fns := p.onField[spec.name]; len(fns) > 0 {
+				c.onField = append([]FieldChangeFunc(nil), fns...)
+				c.old = spec.redact(spec.format(dst))
+				c.new = spec.redact(spec.format(src))
+			}
+
+			changes = append(changes, c)
+		} else if !all {
+			continue
+		}
+
+		dst.Set(src)
+	}
+
+	return changes
+}
@@ -0,0 +1,274 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload(t *testing.T) {
+	var envs struct {
+		Workers int
+		Name    string
+		Hosts   []string
+	}
+
+	p, err := pparse(envsMap{"workers": "4", "name": "foo", "hosts": "a,b"}, &envs)
+	require.NoError(t, err)
+
+	type change struct {
+		field    string
+		old, new interface{}
+	}
+
+	var changes []change
+
+	p.OnChange(func(field string, old, new interface{}) {
+		changes = append(changes, change{field, old, new})
+	})
+
+	_ = os.Setenv("workers", "8")
+	_ = os.Setenv("hosts", "c")
+
+	err = p.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, 8, envs.Workers)
+	assert.Equal(t, "foo", envs.Name)
+	assert.Equal(t, []string{"c"}, envs.Hosts)
+	assert.Equal(t, []change{
+		{"workers", 4, 8},
+		{"hosts", []string{"a", "b"}, []string{"c"}},
+	}, changes)
+}
+
+func TestReloadRemovedVariables(t *testing.T) {
+	var envs struct {
+		Workers int
+		Hosts   []string
+		Level   string
+	}
+
+	envs.Level = "info"
+
+	p, err := pparse(envsMap{"workers": "8", "hosts": "a,b", "level": "debug"}, &envs)
+	require.NoError(t, err)
+
+	var changed []string
+
+	p.OnChange(func(field string, old, new interface{}) {
+		changed = append(changed, field)
+	})
+
+	os.Clearenv()
+
+	require.NoError(t, p.Reload())
+	assert.Equal(t, 0, envs.Workers)
+	assert.Nil(t, envs.Hosts)
+	assert.Equal(t, "info", envs.Level)
+	assert.Equal(t, []string{"workers", "hosts", "level"}, changed)
+}
+
+func TestOnFieldChange(t *testing.T) {
+	var envs struct {
+		Level    string `default:"info"`
+		Workers  int
+		Password string `env:"password,secret"`
+	}
+
+	p, err := pparse(envsMap{"workers": "4", "password": "a"}, &envs)
+	require.NoError(t, err)
+
+	var levels, passwords []string
+
+	p.OnFieldChange("level", func(old, new string) {
+		levels = append(levels, old+"->"+new)
+	})
+	p.OnFieldChange("password", func(old, new string) {
+		passwords = append(passwords, old+"->"+new)
+	})
+
+	// a Parse following a successful one reports the changes too
+	_ = os.Setenv("level", "debug")
+	_ = os.Setenv("workers", "8")
+
+	require.NoError(t, p.Parse())
+	assert.Equal(t, []string{"info->debug"}, levels)
+	assert.Empty(t, passwords)
+
+	_ = os.Setenv("password", "b")
+
+	require.NoError(t, p.Reload())
+	assert.Equal(t, []string{"info->debug"}, levels)
+	assert.Equal(t, []string{"***->***"}, passwords)
+}
+
+func TestOnFieldChangeFirstParse(t *testing.T) {
+	var envs struct {
+		Level string `default:"info"`
+	}
+
+	p, err := NewParser(Config{Sources: []Source{MapSource{"level": "debug"}}}, &envs)
+	require.NoError(t, err)
+
+	called := false
+
+	p.OnFieldChange("level", func(string, string) {
+		called = true
+	})
+
+	require.NoError(t, p.Parse())
+	assert.Equal(t, "debug", envs.Level)
+	assert.False(t, called)
+}
+
+func TestReloadKeepsValuesOnError(t *testing.T) {
+	var envs struct {
+		Workers int
+		Name    string
+	}
+
+	p, err := pparse(envsMap{"workers": "4", "name": "foo"}, &envs)
+	require.NoError(t, err)
+
+	called := false
+
+	p.OnChange(func(string, interface{}, interface{}) {
+		called = true
+	})
+
+	_ = os.Setenv("name", "bar")
+	_ = os.Setenv("workers", "xyz")
+
+	err = p.Reload()
+	require.Error(t, err)
+	assert.Equal(t, 4, envs.Workers)
+	assert.Equal(t, "foo", envs.Name)
+	assert.False(t, called)
+}
+
+func TestWatch(t *testing.T) {
+	var envs struct {
+		Workers int
+	}
+
+	p, err := pparse(envsMap{"workers": "4"}, &envs)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changed := make(chan interface{}, 1)
+
+	p.OnChange(func(field string, old, new interface{}) {
+		changed <- new
+		cancel()
+	})
+
+	_ = os.Setenv("workers", "8")
+
+	err = p.Watch(ctx, time.Millisecond)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 8, <-changed)
+	assert.Equal(t, 8, envs.Workers)
+}
+
+func TestWatchReportsErrors(t *testing.T) {
+	var envs struct {
+		Workers int
+	}
+
+	p, err := pparse(envsMap{"workers": "4"}, &envs)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var reported error
+
+	p.OnError(func(err error) {
+		reported = err
+		cancel()
+	})
+
+	_ = os.Setenv("workers", "xyz")
+
+	err = p.Watch(ctx, time.Millisecond)
+	assert.Equal(t, context.Canceled, err)
+	assert.Error(t, reported)
+	assert.Equal(t, 4, envs.Workers)
+}
+
+func TestWatchInvalidInterval(t *testing.T) {
+	var envs struct {
+		Workers int
+	}
+
+	p, err := pparse(envsMap{"workers": "4"}, &envs)
+	require.NoError(t, err)
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		err = p.Watch(context.Background(), interval)
+		assert.True(t, errors.Is(err, ErrorInvalidInterval))
+	}
+}
+
+func TestConcurrentReload(t *testing.T) {
+	var envs struct {
+		Port  int
+		Hosts []string
+		DB    *struct {
+			User string `env:"db_user"`
+		} `onMissing:"disable"`
+	}
+
+	src := MapSource{"port": "0", "hosts": "a,b", "db_user": "u"}
+
+	p, err := NewParser(Config{Sources: []Source{src}}, &envs)
+	require.NoError(t, err)
+	require.NoError(t, p.Parse())
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 50; j++ {
+				assert.NoError(t, p.Reload())
+				assert.NoError(t, p.Parse())
+			}
+		}()
+	}
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 50; j++ {
+				p.View(func() {
+					assert.Equal(t, []string{"a", "b"}, envs.Hosts)
+					assert.Equal(t, "u", envs.DB.User)
+				})
+
+				assert.Equal(t, "0", p.Snapshot()["port"])
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	src["port"] = "1"
+
+	require.NoError(t, p.Reload())
+	p.View(func() { assert.Equal(t, 1, envs.Port) })
+}
@@ -0,0 +1,12 @@
+//go:build yaml
+// +build yaml
+
+package env
+
+import "gopkg.in/yaml.v3"
+
+// YAMLFileSource returns a Source reading variables from a YAML file, see
+// FileSource. It is only available with the yaml build tag.
+func YAMLFileSource(path string) Source {
+	return FileSource(path, yaml.Unmarshal)
+}